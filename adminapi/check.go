@@ -0,0 +1,53 @@
+package adminapi
+
+import (
+	"net/http"
+)
+
+// CheckResponse is the body of the GET /check response
+type CheckResponse struct {
+	EntityType   string `json:"entity_type"`
+	EntityID     string `json:"entity_id"`
+	Permission   string `json:"permission"`
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	Allowed      bool   `json:"allowed"`
+}
+
+// check serves GET /check with the entity_type, entity_id and permission (handle)
+// query parameters, and optionally resource_type and resource_id
+func (h *handler) check(w http.ResponseWriter, r *http.Request) error {
+	values := r.URL.Query()
+
+	result := CheckResponse{
+		EntityType:   values.Get("entity_type"),
+		EntityID:     values.Get("entity_id"),
+		Permission:   values.Get("permission"),
+		ResourceType: values.Get("resource_type"),
+		ResourceID:   values.Get("resource_id"),
+	}
+
+	if result.EntityType == "" || result.EntityID == "" || result.Permission == "" {
+		return badRequest("entity_type, entity_id and permission are required")
+	}
+
+	if (result.ResourceType == "") != (result.ResourceID == "") {
+		return badRequest("resource_type and resource_id must be both set or both empty")
+	}
+
+	var err error
+
+	if result.ResourceType == "" {
+		result.Allowed, err = h.store.EntityHasPermission(r.Context(), result.EntityType, result.EntityID, result.Permission)
+	} else {
+		result.Allowed, err = h.store.EntityHasPermissionOnResource(r.Context(), result.EntityType, result.EntityID, result.Permission, result.ResourceType, result.ResourceID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusOK, result)
+
+	return nil
+}
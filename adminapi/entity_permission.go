@@ -0,0 +1,384 @@
+package adminapi
+
+import (
+	"net/http"
+
+	"github.com/gouniverse/permissionstore"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// entityPermissionOrderColumns are the columns the entity permissions can be ordered by
+var entityPermissionOrderColumns = []string{
+	permissionstore.COLUMN_CREATED_AT,
+	permissionstore.COLUMN_EFFECT,
+	permissionstore.COLUMN_ENTITY_ID,
+	permissionstore.COLUMN_ENTITY_TYPE,
+	permissionstore.COLUMN_EXPIRES_AT,
+	permissionstore.COLUMN_ID,
+	permissionstore.COLUMN_PERMISSION_ID,
+	permissionstore.COLUMN_UPDATED_AT,
+	permissionstore.COLUMN_VALID_FROM,
+}
+
+// entityPermissionEffects are the effects accepted in the request bodies
+var entityPermissionEffects = []string{
+	permissionstore.ENTITY_PERMISSION_EFFECT_ALLOW,
+	permissionstore.ENTITY_PERMISSION_EFFECT_DENY,
+}
+
+// EntityPermission is the JSON representation of an entity permission.
+// The validity window is omitted, when the grant is not time-bounded
+type EntityPermission struct {
+	ID            string            `json:"id"`
+	EntityType    string            `json:"entity_type"`
+	EntityID      string            `json:"entity_id"`
+	PermissionID  string            `json:"permission_id"`
+	Effect        string            `json:"effect"`
+	ResourceType  string            `json:"resource_type"`
+	ResourceID    string            `json:"resource_id"`
+	ValidFrom     string            `json:"valid_from,omitempty"`
+	ExpiresAt     string            `json:"expires_at,omitempty"`
+	Memo          string            `json:"memo"`
+	Metas         map[string]string `json:"metas"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+	SoftDeletedAt string            `json:"soft_deleted_at,omitempty"`
+}
+
+// EntityPermissionCreateRequest is the body of POST /entity-permissions.
+// The permission is given by PermissionID or by its handle in Permission
+type EntityPermissionCreateRequest struct {
+	EntityType   string            `json:"entity_type"`
+	EntityID     string            `json:"entity_id"`
+	PermissionID string            `json:"permission_id"`
+	Permission   string            `json:"permission"`
+	Effect       string            `json:"effect"`
+	ResourceType string            `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	ValidFrom    string            `json:"valid_from"`
+	ExpiresAt    string            `json:"expires_at"`
+	Memo         string            `json:"memo"`
+	Metas        map[string]string `json:"metas"`
+}
+
+// EntityPermissionUpdateRequest is the body of PATCH /entity-permissions/{id},
+// the omitted fields are not changed. Empty ValidFrom and ExpiresAt remove
+// the respective bound of the validity window
+type EntityPermissionUpdateRequest struct {
+	Effect       *string            `json:"effect"`
+	ResourceType *string            `json:"resource_type"`
+	ResourceID   *string            `json:"resource_id"`
+	ValidFrom    *string            `json:"valid_from"`
+	ExpiresAt    *string            `json:"expires_at"`
+	Memo         *string            `json:"memo"`
+	Metas        *map[string]string `json:"metas"`
+}
+
+func newEntityPermission(entityPermission permissionstore.EntityPermissionInterface) (EntityPermission, error) {
+	metas, err := entityPermission.Metas()
+
+	if err != nil {
+		return EntityPermission{}, err
+	}
+
+	return EntityPermission{
+		ID:            entityPermission.ID(),
+		EntityType:    entityPermission.EntityType(),
+		EntityID:      entityPermission.EntityID(),
+		PermissionID:  entityPermission.PermissionID(),
+		Effect:        entityPermission.Effect(),
+		ResourceType:  entityPermission.ResourceType(),
+		ResourceID:    entityPermission.ResourceID(),
+		ValidFrom:     datetime(entityPermission.ValidFrom()),
+		ExpiresAt:     datetime(entityPermission.ExpiresAt()),
+		Memo:          entityPermission.Memo(),
+		Metas:         metas,
+		CreatedAt:     datetime(entityPermission.CreatedAt()),
+		UpdatedAt:     datetime(entityPermission.UpdatedAt()),
+		SoftDeletedAt: datetime(entityPermission.SoftDeletedAt()),
+	}, nil
+}
+
+// entityPermissionList serves GET /entity-permissions, filtered by the entity_type,
+// entity_id, permission_id, effect, resource_type, resource_id and include_deleted
+// query parameters. Empty resource_type and resource_id select the global grants
+func (h *handler) entityPermissionList(w http.ResponseWriter, r *http.Request) error {
+	p, err := readPage(r, entityPermissionOrderColumns, permissionstore.COLUMN_CREATED_AT)
+
+	if err != nil {
+		return err
+	}
+
+	includeDeleted, err := readBool(r, "include_deleted")
+
+	if err != nil {
+		return err
+	}
+
+	values := r.URL.Query()
+
+	filter := func() permissionstore.EntityPermissionQueryInterface {
+		query := permissionstore.NewEntityPermissionQuery().
+			SetSoftDeletedIncluded(includeDeleted)
+
+		if value := values.Get("entity_type"); value != "" {
+			query.SetEntityType(value)
+		}
+
+		if value := values.Get("entity_id"); value != "" {
+			query.SetEntityID(value)
+		}
+
+		if value := values.Get("permission_id"); value != "" {
+			query.SetPermissionID(value)
+		}
+
+		if value := values.Get("effect"); value != "" {
+			query.SetEffect(value)
+		}
+
+		if values.Has("resource_type") {
+			query.SetResourceType(values.Get("resource_type"))
+		}
+
+		if values.Has("resource_id") {
+			query.SetResourceID(values.Get("resource_id"))
+		}
+
+		return query
+	}
+
+	total, err := h.store.EntityPermissionCount(r.Context(), filter())
+
+	if err != nil {
+		return err
+	}
+
+	entityPermissions, err := h.store.EntityPermissionList(r.Context(), filter().
+		SetLimit(p.limit).
+		SetOffset(p.offset).
+		SetOrderBy(p.orderBy).
+		SetSortDirection(p.sortDirection))
+
+	if err != nil {
+		return err
+	}
+
+	data := make([]EntityPermission, 0, len(entityPermissions))
+
+	for _, entityPermission := range entityPermissions {
+		item, err := newEntityPermission(entityPermission)
+
+		if err != nil {
+			return err
+		}
+
+		data = append(data, item)
+	}
+
+	writeJSON(w, http.StatusOK, listBody{Data: data, Meta: listMeta{Total: total, Limit: p.limit, Offset: p.offset}})
+
+	return nil
+}
+
+// entityPermissionCreate serves POST /entity-permissions
+func (h *handler) entityPermissionCreate(w http.ResponseWriter, r *http.Request) error {
+	body := EntityPermissionCreateRequest{}
+
+	if err := readJSON(r, &body); err != nil {
+		return err
+	}
+
+	if body.EntityType == "" || body.EntityID == "" {
+		return badRequest("entity_type and entity_id are required")
+	}
+
+	if (body.PermissionID == "") == (body.Permission == "") {
+		return badRequest("one of permission_id or permission is required")
+	}
+
+	if body.Effect == "" {
+		body.Effect = permissionstore.ENTITY_PERMISSION_EFFECT_ALLOW
+	}
+
+	entityPermission := permissionstore.NewEntityPermission().
+		SetEntityType(body.EntityType).
+		SetEntityID(body.EntityID).
+		SetEffect(body.Effect).
+		SetResourceType(body.ResourceType).
+		SetResourceID(body.ResourceID).
+		SetMemo(body.Memo)
+
+	if body.ValidFrom != "" {
+		entityPermission.SetValidFrom(body.ValidFrom)
+	}
+
+	if body.ExpiresAt != "" {
+		entityPermission.SetExpiresAt(body.ExpiresAt)
+	}
+
+	if body.Metas != nil {
+		if err := entityPermission.SetMetas(body.Metas); err != nil {
+			return err
+		}
+	}
+
+	if err := entityPermissionValidate(entityPermission); err != nil {
+		return err
+	}
+
+	var permission permissionstore.PermissionInterface
+	var err error
+
+	if body.PermissionID != "" {
+		permission, err = h.store.PermissionGetByID(r.Context(), body.PermissionID)
+	} else {
+		permission, err = h.store.PermissionGetByHandle(r.Context(), body.Permission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	entityPermission.SetPermissionID(permission.ID())
+
+	if err := h.store.EntityPermissionCreate(r.Context(), entityPermission); err != nil {
+		return err
+	}
+
+	return h.writeEntityPermission(w, http.StatusCreated, entityPermission)
+}
+
+// entityPermissionRead serves GET /entity-permissions/{id}
+func (h *handler) entityPermissionRead(w http.ResponseWriter, r *http.Request) error {
+	entityPermission, err := h.store.EntityPermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	return h.writeEntityPermission(w, http.StatusOK, entityPermission)
+}
+
+// entityPermissionUpdate serves PATCH /entity-permissions/{id}
+func (h *handler) entityPermissionUpdate(w http.ResponseWriter, r *http.Request) error {
+	body := EntityPermissionUpdateRequest{}
+
+	if err := readJSON(r, &body); err != nil {
+		return err
+	}
+
+	entityPermission, err := h.store.EntityPermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if body.Effect != nil {
+		entityPermission.SetEffect(*body.Effect)
+	}
+
+	if body.ResourceType != nil {
+		entityPermission.SetResourceType(*body.ResourceType)
+	}
+
+	if body.ResourceID != nil {
+		entityPermission.SetResourceID(*body.ResourceID)
+	}
+
+	if body.ValidFrom != nil {
+		entityPermission.SetValidFrom(lo.Ternary(*body.ValidFrom == "", sb.NULL_DATETIME, *body.ValidFrom))
+	}
+
+	if body.ExpiresAt != nil {
+		entityPermission.SetExpiresAt(lo.Ternary(*body.ExpiresAt == "", sb.MAX_DATETIME, *body.ExpiresAt))
+	}
+
+	if body.Memo != nil {
+		entityPermission.SetMemo(*body.Memo)
+	}
+
+	if body.Metas != nil {
+		if err := entityPermission.SetMetas(*body.Metas); err != nil {
+			return err
+		}
+	}
+
+	if err := entityPermissionValidate(entityPermission); err != nil {
+		return err
+	}
+
+	if err := h.store.EntityPermissionUpdate(r.Context(), entityPermission); err != nil {
+		return err
+	}
+
+	return h.writeEntityPermission(w, http.StatusOK, entityPermission)
+}
+
+// entityPermissionDelete serves DELETE /entity-permissions/{id}
+func (h *handler) entityPermissionDelete(w http.ResponseWriter, r *http.Request) error {
+	hard, err := readBool(r, "hard")
+
+	if err != nil {
+		return err
+	}
+
+	entityPermission, err := h.store.EntityPermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if hard {
+		err = h.store.EntityPermissionDelete(r.Context(), entityPermission)
+	} else {
+		err = h.store.EntityPermissionSoftDelete(r.Context(), entityPermission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// entityPermissionValidate checks the effect, the resource and the validity
+// window, so invalid requests are answered with 400 instead of store errors
+func entityPermissionValidate(entityPermission permissionstore.EntityPermissionInterface) error {
+	if !lo.Contains(entityPermissionEffects, entityPermission.Effect()) {
+		return badRequest("effect must be allow or deny")
+	}
+
+	if (entityPermission.ResourceType() == "") != (entityPermission.ResourceID() == "") {
+		return badRequest("resource_type and resource_id must be both set or both empty")
+	}
+
+	if err := validDatetime("valid_from", entityPermission.ValidFrom()); err != nil {
+		return err
+	}
+
+	if err := validDatetime("expires_at", entityPermission.ExpiresAt()); err != nil {
+		return err
+	}
+
+	if !entityPermission.ExpiresAtCarbon().Gt(entityPermission.ValidFromCarbon()) {
+		return badRequest("expires_at must be after valid_from")
+	}
+
+	return nil
+}
+
+func (h *handler) writeEntityPermission(w http.ResponseWriter, status int, entityPermission permissionstore.EntityPermissionInterface) error {
+	item, err := newEntityPermission(entityPermission)
+
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, status, item)
+
+	return nil
+}
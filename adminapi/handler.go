@@ -0,0 +1,344 @@
+// Package adminapi exposes a permission store over a JSON REST API.
+//
+// The handler serves the following routes, relative to where it is mounted:
+//
+//	GET    /permissions                list the permissions
+//	POST   /permissions                create a permission
+//	GET    /permissions/{id}           get a permission
+//	PATCH  /permissions/{id}           update a permission
+//	DELETE /permissions/{id}           soft delete a permission, ?hard=true deletes it
+//	GET    /entity-permissions         list the entity permissions
+//	POST   /entity-permissions         create an entity permission
+//	GET    /entity-permissions/{id}    get an entity permission
+//	PATCH  /entity-permissions/{id}    update an entity permission
+//	DELETE /entity-permissions/{id}    soft delete an entity permission, ?hard=true deletes it
+//	GET    /check                      check whether an entity is granted a permission
+//
+// The list routes accept the limit, offset, order_by and sort query parameters
+// and respond with {"data": [...], "meta": {"total", "limit", "offset"}}.
+// Errors are answered with {"error": {"code", "message"}}.
+//
+// Every request is authorized by the Authorizer of the handler before it is served.
+package adminapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/permissionstore"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// actions authorized by the Authorizer
+const (
+	ActionCheck                  = "check"
+	ActionEntityPermissionCreate = "entity_permission.create"
+	ActionEntityPermissionDelete = "entity_permission.delete"
+	ActionEntityPermissionList   = "entity_permission.list"
+	ActionEntityPermissionRead   = "entity_permission.read"
+	ActionEntityPermissionUpdate = "entity_permission.update"
+	ActionPermissionCreate       = "permission.create"
+	ActionPermissionDelete       = "permission.delete"
+	ActionPermissionList         = "permission.list"
+	ActionPermissionRead         = "permission.read"
+	ActionPermissionUpdate       = "permission.update"
+)
+
+// error codes of the error bodies
+const (
+	CodeBadRequest   = "bad_request"
+	CodeConflict     = "conflict"
+	CodeForbidden    = "forbidden"
+	CodeInternal     = "internal_error"
+	CodeNotFound     = "not_found"
+	CodeUnauthorized = "unauthorized"
+)
+
+// DefaultLimit is the page size of the list routes without a limit parameter
+const DefaultLimit = 50
+
+// MaxLimit is the largest page size of the list routes
+const MaxLimit = 500
+
+// ErrUnauthenticated is returned by an Authorizer for requests without
+// credentials, these are answered with 401 Unauthorized
+var ErrUnauthenticated = errors.New("adminapi: unauthenticated")
+
+// ErrForbidden is returned by an Authorizer for requests, which are not
+// allowed the action, these are answered with 403 Forbidden
+var ErrForbidden = errors.New("adminapi: forbidden")
+
+// Authorizer decides whether the request is allowed the action, one of the
+// Action constants. It returns nil to allow the request, ErrUnauthenticated
+// to answer with 401 and any other error to answer with 403
+type Authorizer func(r *http.Request, action string) error
+
+// HandlerOptions define the options for creating a new admin API handler
+type HandlerOptions struct {
+	// Store is the permission store
+	Store permissionstore.StoreInterface
+
+	// Authorizer authorizes every request, required
+	Authorizer Authorizer
+
+	// Logger logs the internal errors, which are answered without details, defaults to the default logger
+	Logger *slog.Logger
+}
+
+// handler is the admin API handler
+type handler struct {
+	store      permissionstore.StoreInterface
+	authorizer Authorizer
+	logger     *slog.Logger
+	mux        *http.ServeMux
+}
+
+// NewHandler creates a new admin API handler
+func NewHandler(opts HandlerOptions) (http.Handler, error) {
+	if opts.Store == nil {
+		return nil, errors.New("adminapi: Store is required")
+	}
+
+	if opts.Authorizer == nil {
+		return nil, errors.New("adminapi: Authorizer is required")
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	h := &handler{
+		store:      opts.Store,
+		authorizer: opts.Authorizer,
+		logger:     opts.Logger,
+		mux:        http.NewServeMux(),
+	}
+
+	h.route("GET /permissions", ActionPermissionList, h.permissionList)
+	h.route("POST /permissions", ActionPermissionCreate, h.permissionCreate)
+	h.route("GET /permissions/{id}", ActionPermissionRead, h.permissionRead)
+	h.route("PATCH /permissions/{id}", ActionPermissionUpdate, h.permissionUpdate)
+	h.route("DELETE /permissions/{id}", ActionPermissionDelete, h.permissionDelete)
+	h.route("GET /entity-permissions", ActionEntityPermissionList, h.entityPermissionList)
+	h.route("POST /entity-permissions", ActionEntityPermissionCreate, h.entityPermissionCreate)
+	h.route("GET /entity-permissions/{id}", ActionEntityPermissionRead, h.entityPermissionRead)
+	h.route("PATCH /entity-permissions/{id}", ActionEntityPermissionUpdate, h.entityPermissionUpdate)
+	h.route("DELETE /entity-permissions/{id}", ActionEntityPermissionDelete, h.entityPermissionDelete)
+	h.route("GET /check", ActionCheck, h.check)
+
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "route not found")
+	})
+
+	return h, nil
+}
+
+// ServeHTTP serves the request
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// route registers the route, which is served after the request is authorized for the action
+func (h *handler) route(pattern string, action string, serve func(w http.ResponseWriter, r *http.Request) error) {
+	h.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := h.authorizer(r, action); err != nil {
+			if errors.Is(err, ErrUnauthenticated) {
+				writeError(w, http.StatusUnauthorized, CodeUnauthorized, "authentication required")
+				return
+			}
+
+			writeError(w, http.StatusForbidden, CodeForbidden, "action "+action+" is not allowed")
+			return
+		}
+
+		if err := serve(w, r); err != nil {
+			h.writeServeError(w, r, err)
+		}
+	})
+}
+
+// errorBody is the body of the error responses
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// listBody is the body of the list responses
+type listBody struct {
+	Data any      `json:"data"`
+	Meta listMeta `json:"meta"`
+}
+
+type listMeta struct {
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// badRequestError is an invalid request, answered with 400 and its message
+type badRequestError struct {
+	message string
+}
+
+func (e badRequestError) Error() string {
+	return e.message
+}
+
+// badRequest returns an error answered with 400 Bad Request
+func badRequest(message string) error {
+	return badRequestError{message: message}
+}
+
+// writeServeError answers with the status and code matching the error,
+// internal errors are logged and answered without details
+func (h *handler) writeServeError(w http.ResponseWriter, r *http.Request, err error) {
+	var badRequestErr badRequestError
+
+	switch {
+	case errors.As(err, &badRequestErr):
+		writeError(w, http.StatusBadRequest, CodeBadRequest, badRequestErr.message)
+	case errors.Is(err, permissionstore.ErrNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, permissionstore.ErrDuplicateHandle), errors.Is(err, permissionstore.ErrDuplicateGrant):
+		writeError(w, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, permissionstore.ErrInvalidQuery):
+		writeError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+	default:
+		h.logger.Error("adminapi: request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "internal error")
+	}
+}
+
+// writeError answers with an error body
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+// writeJSON answers with the value as JSON
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// readJSON decodes the request body into the value, unknown fields are rejected
+func readJSON(r *http.Request, value any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		return badRequest("invalid JSON body: " + err.Error())
+	}
+
+	return nil
+}
+
+// page are the pagination parameters of a list request
+type page struct {
+	limit         int
+	offset        int
+	orderBy       string
+	sortDirection string
+}
+
+// readPage reads the limit, offset, order_by and sort query parameters,
+// order_by must be one of the given columns
+func readPage(r *http.Request, columns []string, defaultOrderBy string) (page, error) {
+	query := r.URL.Query()
+	p := page{limit: DefaultLimit, orderBy: defaultOrderBy, sortDirection: sb.ASC}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return p, badRequest("limit must be a number from 1 to " + strconv.Itoa(MaxLimit))
+		}
+
+		p.limit = limit
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+
+		if err != nil || offset < 0 {
+			return p, badRequest("offset must be a non-negative number")
+		}
+
+		p.offset = offset
+	}
+
+	if value := query.Get("order_by"); value != "" {
+		if !lo.Contains(columns, value) {
+			return p, badRequest("order_by must be one of " + strings.Join(columns, ", "))
+		}
+
+		p.orderBy = value
+	}
+
+	switch strings.ToLower(query.Get("sort")) {
+	case "", "asc":
+		p.sortDirection = sb.ASC
+	case "desc":
+		p.sortDirection = sb.DESC
+	default:
+		return p, badRequest("sort must be asc or desc")
+	}
+
+	return p, nil
+}
+
+// readBool reads a boolean query parameter, false when missing
+func readBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, badRequest(name + " must be true or false")
+	}
+
+	return b, nil
+}
+
+// datetime normalizes a datetime column to "YYYY-MM-DD HH:MM:SS" in UTC,
+// the open ended values, i.e. "not deleted" or "never expires", are empty
+func datetime(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	normalized := carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	if normalized == "" {
+		return value
+	}
+
+	if normalized == sb.MAX_DATETIME || normalized == sb.NULL_DATETIME {
+		return ""
+	}
+
+	return normalized
+}
+
+// validDatetime checks a datetime of a request body
+func validDatetime(name string, value string) error {
+	if value != "" && carbon.Parse(value, carbon.UTC).Error != nil {
+		return badRequest(name + " must be a datetime, i.e. 2030-01-31 12:00:00")
+	}
+
+	return nil
+}
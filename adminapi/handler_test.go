@@ -0,0 +1,343 @@
+package adminapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gouniverse/permissionstore"
+	_ "modernc.org/sqlite"
+)
+
+// testToken is the bearer token of the admin in the tests
+const testToken = "admin-token"
+
+func initStore(t *testing.T) permissionstore.StoreInterface {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	store, err := permissionstore.NewStore(permissionstore.NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return store
+}
+
+// testAuthorizer allows the admin token every action and the
+// reader token only the read actions
+func testAuthorizer(r *http.Request, action string) error {
+	switch r.Header.Get("Authorization") {
+	case "Bearer " + testToken:
+		return nil
+	case "Bearer reader-token":
+		if strings.HasSuffix(action, ".list") || strings.HasSuffix(action, ".read") {
+			return nil
+		}
+
+		return ErrForbidden
+	}
+
+	return ErrUnauthenticated
+}
+
+func initServer(t *testing.T) (*httptest.Server, permissionstore.StoreInterface) {
+	t.Helper()
+
+	store := initStore(t)
+
+	handler, err := NewHandler(HandlerOptions{
+		Store:      store,
+		Authorizer: testAuthorizer,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/admin", handler))
+	t.Cleanup(server.Close)
+
+	return server, store
+}
+
+// request sends the request with the admin token and decodes the response body into out
+func request(t *testing.T, server *httptest.Server, method string, path string, body string, out any) int {
+	t.Helper()
+
+	return requestWithToken(t, server, testToken, method, path, body, out)
+}
+
+func requestWithToken(t *testing.T, server *httptest.Server, token string, method string, path string, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+"/admin"+path, strings.NewReader(body))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := server.Client().Do(req)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestNewHandlerRequiresAuthorizer(t *testing.T) {
+	if _, err := NewHandler(HandlerOptions{Store: initStore(t)}); err == nil {
+		t.Fatal("error expected without authorizer")
+	}
+}
+
+func TestPermissionRoutes(t *testing.T) {
+	server, _ := initServer(t)
+
+	created := Permission{}
+
+	status := request(t, server, http.MethodPost, "/permissions", `{"handle":"orders.read","title":"Read orders","metas":{"team":"sales"}}`, &created)
+
+	if status != http.StatusCreated {
+		t.Fatal("status 201 expected, found:", status)
+	}
+
+	if created.ID == "" || created.Handle != "orders.read" || created.Status != permissionstore.PERMISSION_STATUS_ACTIVE || created.Metas["team"] != "sales" {
+		t.Fatal("created permission expected, found:", created)
+	}
+
+	errBody := errorBody{}
+
+	if status := request(t, server, http.MethodPost, "/permissions", `{"handle":"orders.read","title":"Again"}`, &errBody); status != http.StatusConflict || errBody.Error.Code != CodeConflict {
+		t.Fatal("duplicate handle must conflict, found:", status, errBody)
+	}
+
+	if status := request(t, server, http.MethodPost, "/permissions", `{"handle":"","title":"Empty"}`, &errBody); status != http.StatusBadRequest || errBody.Error.Code != CodeBadRequest {
+		t.Fatal("empty handle must be a bad request, found:", status, errBody)
+	}
+
+	if status := request(t, server, http.MethodPost, "/permissions", `{"handle":"orders.write","title":"Write","unknown":1}`, &errBody); status != http.StatusBadRequest {
+		t.Fatal("unknown field must be a bad request, found:", status)
+	}
+
+	updated := Permission{}
+
+	if status := request(t, server, http.MethodPatch, "/permissions/"+created.ID, `{"title":"View orders","status":"inactive"}`, &updated); status != http.StatusOK {
+		t.Fatal("status 200 expected, found:", status)
+	}
+
+	if updated.Title != "View orders" || updated.Status != "inactive" || updated.Handle != "orders.read" || updated.Metas["team"] != "sales" {
+		t.Fatal("only the given fields must be updated, found:", updated)
+	}
+
+	read := Permission{}
+
+	if status := request(t, server, http.MethodGet, "/permissions/"+created.ID, "", &read); status != http.StatusOK || read.Title != "View orders" {
+		t.Fatal("updated permission expected, found:", status, read)
+	}
+
+	if status := request(t, server, http.MethodDelete, "/permissions/"+created.ID, "", nil); status != http.StatusNoContent {
+		t.Fatal("status 204 expected, found:", status)
+	}
+
+	if status := request(t, server, http.MethodGet, "/permissions/"+created.ID, "", &errBody); status != http.StatusNotFound || errBody.Error.Code != CodeNotFound {
+		t.Fatal("soft deleted permission must not be found, found:", status, errBody)
+	}
+}
+
+func TestPermissionListPagination(t *testing.T) {
+	server, _ := initServer(t)
+
+	for _, handle := range []string{"c.read", "a.read", "e.read", "b.read", "d.read"} {
+		if status := request(t, server, http.MethodPost, "/permissions", `{"handle":"`+handle+`","title":"`+handle+`"}`, nil); status != http.StatusCreated {
+			t.Fatal("status 201 expected, found:", status)
+		}
+	}
+
+	list := struct {
+		Data []Permission `json:"data"`
+		Meta listMeta     `json:"meta"`
+	}{}
+
+	if status := request(t, server, http.MethodGet, "/permissions?limit=2&offset=1", "", &list); status != http.StatusOK {
+		t.Fatal("status 200 expected, found:", status)
+	}
+
+	if list.Meta.Total != 5 || list.Meta.Limit != 2 || list.Meta.Offset != 1 {
+		t.Fatal("meta expected, found:", list.Meta)
+	}
+
+	if len(list.Data) != 2 || list.Data[0].Handle != "b.read" || list.Data[1].Handle != "c.read" {
+		t.Fatal("second page ordered by handle expected, found:", list.Data)
+	}
+
+	if status := request(t, server, http.MethodGet, "/permissions?order_by=handle&sort=desc&limit=1", "", &list); status != http.StatusOK || list.Data[0].Handle != "e.read" {
+		t.Fatal("descending order expected, found:", status, list.Data)
+	}
+
+	errBody := errorBody{}
+
+	for _, query := range []string{"limit=0", "limit=x", "offset=-1", "order_by=memo", "sort=up"} {
+		if status := request(t, server, http.MethodGet, "/permissions?"+query, "", &errBody); status != http.StatusBadRequest {
+			t.Fatal(query, "must be a bad request, found:", status)
+		}
+	}
+}
+
+func TestEntityPermissionRoutesAndCheck(t *testing.T) {
+	server, _ := initServer(t)
+
+	permission := Permission{}
+	request(t, server, http.MethodPost, "/permissions", `{"handle":"refunds.approve","title":"Approve refunds"}`, &permission)
+
+	check := func(query string) bool {
+		t.Helper()
+
+		result := CheckResponse{}
+
+		if status := request(t, server, http.MethodGet, "/check?"+query, "", &result); status != http.StatusOK {
+			t.Fatal("status 200 expected, found:", status)
+		}
+
+		return result.Allowed
+	}
+
+	if check("entity_type=user&entity_id=U1&permission=refunds.approve") {
+		t.Fatal("permission must not be granted before the grant")
+	}
+
+	created := EntityPermission{}
+
+	if status := request(t, server, http.MethodPost, "/entity-permissions", `{"entity_type":"user","entity_id":"U1","permission":"refunds.approve"}`, &created); status != http.StatusCreated {
+		t.Fatal("status 201 expected, found:", status)
+	}
+
+	if created.PermissionID != permission.ID || created.Effect != permissionstore.ENTITY_PERMISSION_EFFECT_ALLOW || created.ExpiresAt != "" {
+		t.Fatal("created grant expected, found:", created)
+	}
+
+	errBody := errorBody{}
+
+	if status := request(t, server, http.MethodPost, "/entity-permissions", `{"entity_type":"user","entity_id":"U1","permission_id":"`+permission.ID+`"}`, &errBody); status != http.StatusConflict {
+		t.Fatal("duplicate grant must conflict, found:", status)
+	}
+
+	if status := request(t, server, http.MethodPost, "/entity-permissions", `{"entity_type":"user","entity_id":"U2","permission":"missing.handle"}`, &errBody); status != http.StatusNotFound {
+		t.Fatal("grant of a missing permission must not be found, found:", status)
+	}
+
+	if status := request(t, server, http.MethodPost, "/entity-permissions", `{"entity_type":"user","entity_id":"U2","permission":"refunds.approve","valid_from":"2030-01-01 00:00:00","expires_at":"2029-01-01 00:00:00"}`, &errBody); status != http.StatusBadRequest {
+		t.Fatal("inverted validity window must be a bad request, found:", status)
+	}
+
+	request(t, server, http.MethodPost, "/entity-permissions", `{"entity_type":"user","entity_id":"U1","permission":"refunds.approve","effect":"deny","resource_type":"store","resource_id":"S1"}`, nil)
+
+	if !check("entity_type=user&entity_id=U1&permission=refunds.approve") {
+		t.Fatal("permission must be granted after the grant")
+	}
+
+	if check("entity_type=user&entity_id=U1&permission=refunds.approve&resource_type=store&resource_id=S1") {
+		t.Fatal("resource scoped deny must override the global grant")
+	}
+
+	if status := request(t, server, http.MethodGet, "/check?entity_type=user", "", &errBody); status != http.StatusBadRequest {
+		t.Fatal("check without permission must be a bad request, found:", status)
+	}
+
+	list := struct {
+		Data []EntityPermission `json:"data"`
+		Meta listMeta           `json:"meta"`
+	}{}
+
+	request(t, server, http.MethodGet, "/entity-permissions?entity_type=user&entity_id=U1", "", &list)
+
+	if list.Meta.Total != 2 || len(list.Data) != 2 {
+		t.Fatal("two grants expected, found:", list)
+	}
+
+	request(t, server, http.MethodGet, "/entity-permissions?entity_id=U1&resource_type=&resource_id=", "", &list)
+
+	if list.Meta.Total != 1 || list.Data[0].ID != created.ID {
+		t.Fatal("only the global grant expected, found:", list)
+	}
+
+	updated := EntityPermission{}
+
+	if status := request(t, server, http.MethodPatch, "/entity-permissions/"+created.ID, `{"effect":"deny","memo":"suspended"}`, &updated); status != http.StatusOK {
+		t.Fatal("status 200 expected, found:", status)
+	}
+
+	if updated.Effect != "deny" || updated.Memo != "suspended" || updated.EntityID != "U1" {
+		t.Fatal("updated grant expected, found:", updated)
+	}
+
+	if check("entity_type=user&entity_id=U1&permission=refunds.approve") {
+		t.Fatal("permission must not be granted after the update to deny")
+	}
+
+	if status := request(t, server, http.MethodDelete, "/entity-permissions/"+created.ID+"?hard=true", "", nil); status != http.StatusNoContent {
+		t.Fatal("status 204 expected, found:", status)
+	}
+
+	if status := request(t, server, http.MethodGet, "/entity-permissions/"+created.ID, "", &errBody); status != http.StatusNotFound {
+		t.Fatal("deleted grant must not be found, found:", status)
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	server, _ := initServer(t)
+
+	errBody := errorBody{}
+
+	if status := requestWithToken(t, server, "", http.MethodGet, "/permissions", "", &errBody); status != http.StatusUnauthorized || errBody.Error.Code != CodeUnauthorized {
+		t.Fatal("request without token must be unauthorized, found:", status, errBody)
+	}
+
+	if status := requestWithToken(t, server, "reader-token", http.MethodGet, "/permissions", "", nil); status != http.StatusOK {
+		t.Fatal("reader must list the permissions, found:", status)
+	}
+
+	if status := requestWithToken(t, server, "reader-token", http.MethodPost, "/permissions", `{"handle":"a.b","title":"A"}`, &errBody); status != http.StatusForbidden || errBody.Error.Code != CodeForbidden {
+		t.Fatal("reader must not create permissions, found:", status, errBody)
+	}
+
+	if status := request(t, server, http.MethodGet, "/unknown", "", &errBody); status != http.StatusNotFound || errBody.Error.Code != CodeNotFound {
+		t.Fatal("unknown route must not be found, found:", status, errBody)
+	}
+}
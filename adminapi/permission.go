@@ -0,0 +1,295 @@
+package adminapi
+
+import (
+	"net/http"
+
+	"github.com/gouniverse/permissionstore"
+	"github.com/samber/lo"
+)
+
+// permissionOrderColumns are the columns the permissions can be ordered by
+var permissionOrderColumns = []string{
+	permissionstore.COLUMN_CREATED_AT,
+	permissionstore.COLUMN_HANDLE,
+	permissionstore.COLUMN_ID,
+	permissionstore.COLUMN_STATUS,
+	permissionstore.COLUMN_TITLE,
+	permissionstore.COLUMN_UPDATED_AT,
+}
+
+// permissionStatuses are the statuses accepted in the request bodies
+var permissionStatuses = []string{
+	permissionstore.PERMISSION_STATUS_ACTIVE,
+	permissionstore.PERMISSION_STATUS_INACTIVE,
+}
+
+// Permission is the JSON representation of a permission
+type Permission struct {
+	ID            string            `json:"id"`
+	Handle        string            `json:"handle"`
+	Title         string            `json:"title"`
+	Status        string            `json:"status"`
+	Memo          string            `json:"memo"`
+	Metas         map[string]string `json:"metas"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+	SoftDeletedAt string            `json:"soft_deleted_at,omitempty"`
+}
+
+// PermissionCreateRequest is the body of POST /permissions
+type PermissionCreateRequest struct {
+	Handle string            `json:"handle"`
+	Title  string            `json:"title"`
+	Status string            `json:"status"`
+	Memo   string            `json:"memo"`
+	Metas  map[string]string `json:"metas"`
+}
+
+// PermissionUpdateRequest is the body of PATCH /permissions/{id},
+// the omitted fields are not changed
+type PermissionUpdateRequest struct {
+	Handle *string            `json:"handle"`
+	Title  *string            `json:"title"`
+	Status *string            `json:"status"`
+	Memo   *string            `json:"memo"`
+	Metas  *map[string]string `json:"metas"`
+}
+
+func newPermission(permission permissionstore.PermissionInterface) (Permission, error) {
+	metas, err := permission.Metas()
+
+	if err != nil {
+		return Permission{}, err
+	}
+
+	return Permission{
+		ID:            permission.ID(),
+		Handle:        permission.Handle(),
+		Title:         permission.Title(),
+		Status:        permission.Status(),
+		Memo:          permission.Memo(),
+		Metas:         metas,
+		CreatedAt:     datetime(permission.CreatedAt()),
+		UpdatedAt:     datetime(permission.UpdatedAt()),
+		SoftDeletedAt: datetime(permission.SoftDeletedAt()),
+	}, nil
+}
+
+// permissionList serves GET /permissions, filtered by the status, handle,
+// title_like and include_deleted query parameters
+func (h *handler) permissionList(w http.ResponseWriter, r *http.Request) error {
+	p, err := readPage(r, permissionOrderColumns, permissionstore.COLUMN_HANDLE)
+
+	if err != nil {
+		return err
+	}
+
+	includeDeleted, err := readBool(r, "include_deleted")
+
+	if err != nil {
+		return err
+	}
+
+	filter := func() permissionstore.PermissionQueryInterface {
+		query := permissionstore.NewPermissionQuery().
+			SetSoftDeletedIncluded(includeDeleted)
+
+		if value := r.URL.Query().Get("status"); value != "" {
+			query.SetStatus(value)
+		}
+
+		if value := r.URL.Query().Get("handle"); value != "" {
+			query.SetHandle(value)
+		}
+
+		if value := r.URL.Query().Get("title_like"); value != "" {
+			query.SetTitleLike(value)
+		}
+
+		return query
+	}
+
+	total, err := h.store.PermissionCount(r.Context(), filter())
+
+	if err != nil {
+		return err
+	}
+
+	permissions, err := h.store.PermissionList(r.Context(), filter().
+		SetLimit(p.limit).
+		SetOffset(p.offset).
+		SetOrderBy(p.orderBy).
+		SetSortDirection(p.sortDirection))
+
+	if err != nil {
+		return err
+	}
+
+	data := make([]Permission, 0, len(permissions))
+
+	for _, permission := range permissions {
+		item, err := newPermission(permission)
+
+		if err != nil {
+			return err
+		}
+
+		data = append(data, item)
+	}
+
+	writeJSON(w, http.StatusOK, listBody{Data: data, Meta: listMeta{Total: total, Limit: p.limit, Offset: p.offset}})
+
+	return nil
+}
+
+// permissionCreate serves POST /permissions
+func (h *handler) permissionCreate(w http.ResponseWriter, r *http.Request) error {
+	body := PermissionCreateRequest{}
+
+	if err := readJSON(r, &body); err != nil {
+		return err
+	}
+
+	if err := permissionstore.PermissionHandleValidate(body.Handle); err != nil {
+		return badRequest(err.Error())
+	}
+
+	if body.Title == "" {
+		return badRequest("title is required")
+	}
+
+	if body.Status == "" {
+		body.Status = permissionstore.PERMISSION_STATUS_ACTIVE
+	}
+
+	if !lo.Contains(permissionStatuses, body.Status) {
+		return badRequest("status must be active or inactive")
+	}
+
+	permission := permissionstore.NewPermission().
+		SetHandle(body.Handle).
+		SetTitle(body.Title).
+		SetStatus(body.Status).
+		SetMemo(body.Memo)
+
+	if body.Metas != nil {
+		if err := permission.SetMetas(body.Metas); err != nil {
+			return err
+		}
+	}
+
+	if err := h.store.PermissionCreate(r.Context(), permission); err != nil {
+		return err
+	}
+
+	return h.writePermission(w, http.StatusCreated, permission)
+}
+
+// permissionRead serves GET /permissions/{id}
+func (h *handler) permissionRead(w http.ResponseWriter, r *http.Request) error {
+	permission, err := h.store.PermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	return h.writePermission(w, http.StatusOK, permission)
+}
+
+// permissionUpdate serves PATCH /permissions/{id}
+func (h *handler) permissionUpdate(w http.ResponseWriter, r *http.Request) error {
+	body := PermissionUpdateRequest{}
+
+	if err := readJSON(r, &body); err != nil {
+		return err
+	}
+
+	if body.Handle != nil {
+		if err := permissionstore.PermissionHandleValidate(*body.Handle); err != nil {
+			return badRequest(err.Error())
+		}
+	}
+
+	if body.Title != nil && *body.Title == "" {
+		return badRequest("title must not be empty")
+	}
+
+	if body.Status != nil && !lo.Contains(permissionStatuses, *body.Status) {
+		return badRequest("status must be active or inactive")
+	}
+
+	permission, err := h.store.PermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if body.Handle != nil {
+		permission.SetHandle(*body.Handle)
+	}
+
+	if body.Title != nil {
+		permission.SetTitle(*body.Title)
+	}
+
+	if body.Status != nil {
+		permission.SetStatus(*body.Status)
+	}
+
+	if body.Memo != nil {
+		permission.SetMemo(*body.Memo)
+	}
+
+	if body.Metas != nil {
+		if err := permission.SetMetas(*body.Metas); err != nil {
+			return err
+		}
+	}
+
+	if err := h.store.PermissionUpdate(r.Context(), permission); err != nil {
+		return err
+	}
+
+	return h.writePermission(w, http.StatusOK, permission)
+}
+
+// permissionDelete serves DELETE /permissions/{id}
+func (h *handler) permissionDelete(w http.ResponseWriter, r *http.Request) error {
+	hard, err := readBool(r, "hard")
+
+	if err != nil {
+		return err
+	}
+
+	permission, err := h.store.PermissionGetByID(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		return err
+	}
+
+	if hard {
+		err = h.store.PermissionDelete(r.Context(), permission)
+	} else {
+		err = h.store.PermissionSoftDelete(r.Context(), permission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (h *handler) writePermission(w http.ResponseWriter, status int, permission permissionstore.PermissionInterface) error {
+	item, err := newPermission(permission)
+
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, status, item)
+
+	return nil
+}
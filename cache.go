@@ -0,0 +1,178 @@
+package permissionstore
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheStats are the statistics of the effective permission cache
+type CacheStats struct {
+	// Hits is the number of lookups served from the cache
+	Hits int64
+
+	// Misses is the number of lookups which had to query the database
+	Misses int64
+
+	// Evictions is the number of entries removed to keep the cache within its size
+	Evictions int64
+
+	// Invalidations is the number of entries removed because of a change
+	Invalidations int64
+
+	// Size is the current number of entries in the cache
+	Size int
+}
+
+// grantCache is a least recently used cache of the effective grants of entities,
+// keyed by entity type and entity ID. It is safe for concurrent use.
+type grantCache struct {
+	mu sync.Mutex
+
+	// size is the maximum number of entries
+	size int
+
+	// ttl is the time to live of an entry, zero for no expiry
+	ttl time.Duration
+
+	// entries holds the cache entries, the most recently used first
+	entries *list.List
+
+	// index maps the entry keys to their list elements
+	index map[string]*list.Element
+
+	// version is bumped by every invalidation, so that grants loaded
+	// before an invalidation are not cached after it
+	version uint64
+
+	stats CacheStats
+}
+
+// grantCacheEntry is a single entry of the grant cache
+type grantCacheEntry struct {
+	key       string
+	grants    []grant
+	expiresAt time.Time
+}
+
+// newGrantCache creates a cache holding at most size entries for ttl each
+func newGrantCache(size int, ttl time.Duration) *grantCache {
+	return &grantCache{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		index:   map[string]*list.Element{},
+	}
+}
+
+// get returns the grants of the entity, false if they are not cached or expired
+func (c *grantCache) get(entityType string, entityID string) ([]grant, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.index[grantCacheKey(entityType, entityID)]
+
+	if !exists {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*grantCacheEntry)
+
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.entries.MoveToFront(element)
+	c.stats.Hits++
+
+	return entry.grants, true
+}
+
+// generation returns the current generation of the cache, to be read before
+// loading the grants passed to set
+func (c *grantCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.version
+}
+
+// set caches the grants of the entity, evicting the least recently used entry when full.
+// The grants are dropped if the cache has been invalidated since the generation, at
+// which they were loaded, as they may be stale
+func (c *grantCache) set(entityType string, entityID string, grants []grant, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.version {
+		return
+	}
+
+	key := grantCacheKey(entityType, entityID)
+
+	if element, exists := c.index[key]; exists {
+		c.remove(element)
+	}
+
+	entry := &grantCacheEntry{key: key, grants: grants}
+
+	if c.ttl > 0 {
+		entry.expiresAt = time.Now().Add(c.ttl)
+	}
+
+	c.index[key] = c.entries.PushFront(entry)
+
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+		c.stats.Evictions++
+	}
+}
+
+// invalidate removes the grants of the entity
+func (c *grantCache) invalidate(entityType string, entityID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+
+	if element, exists := c.index[grantCacheKey(entityType, entityID)]; exists {
+		c.remove(element)
+		c.stats.Invalidations++
+	}
+}
+
+// flush removes all entries
+func (c *grantCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.stats.Invalidations += int64(c.entries.Len())
+	c.entries.Init()
+	c.index = map[string]*list.Element{}
+}
+
+// statistics returns a snapshot of the cache statistics
+func (c *grantCache) statistics() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.entries.Len()
+
+	return stats
+}
+
+// remove removes the element, the caller must hold the lock
+func (c *grantCache) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*grantCacheEntry).key)
+}
+
+// grantCacheKey returns the cache key of the entity
+func grantCacheKey(entityType string, entityID string) string {
+	return entityType + "\x00" + entityID
+}
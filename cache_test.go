@@ -0,0 +1,95 @@
+package permissionstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGrantCache(t *testing.T) {
+	cache := newGrantCache(2, 0)
+
+	if _, found := cache.get("USER", "USER_01"); found {
+		t.Fatal("empty cache MUST NOT find anything")
+	}
+
+	cache.set("USER", "USER_01", []grant{{handle: "articles.read"}}, cache.generation())
+	cache.set("USER", "USER_02", []grant{{handle: "articles.write"}}, cache.generation())
+
+	grants, found := cache.get("USER", "USER_01")
+
+	if !found || len(grants) != 1 || grants[0].handle != "articles.read" {
+		t.Fatal("USER_01 MUST be cached, found:", grants)
+	}
+
+	// USER_02 is now the least recently used entry
+	cache.set("USER", "USER_03", []grant{}, cache.generation())
+
+	if _, found := cache.get("USER", "USER_02"); found {
+		t.Fatal("USER_02 MUST be evicted")
+	}
+
+	if _, found := cache.get("USER", "USER_03"); !found {
+		t.Fatal("USER_03 MUST be cached, even without grants")
+	}
+
+	cache.invalidate("USER", "USER_01")
+
+	if _, found := cache.get("USER", "USER_01"); found {
+		t.Fatal("USER_01 MUST be invalidated")
+	}
+
+	cache.flush()
+
+	stats := cache.statistics()
+
+	if stats.Hits != 2 || stats.Misses != 3 || stats.Evictions != 1 || stats.Invalidations != 2 || stats.Size != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestGrantCache_TTL(t *testing.T) {
+	cache := newGrantCache(10, 10*time.Millisecond)
+
+	cache.set("USER", "USER_01", []grant{{handle: "articles.read"}}, cache.generation())
+
+	if _, found := cache.get("USER", "USER_01"); !found {
+		t.Fatal("USER_01 MUST be cached")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found := cache.get("USER", "USER_01"); found {
+		t.Fatal("USER_01 MUST be expired")
+	}
+
+	if cache.statistics().Size != 0 {
+		t.Fatal("expired entry MUST be removed")
+	}
+}
+
+func TestGrantCache_Generation(t *testing.T) {
+	cache := newGrantCache(10, 0)
+
+	// invalidated between the load and the set, even without an entry yet
+	generation := cache.generation()
+	cache.invalidate("USER", "USER_01")
+	cache.set("USER", "USER_01", []grant{{handle: "articles.read"}}, generation)
+
+	if _, found := cache.get("USER", "USER_01"); found {
+		t.Fatal("grants loaded before an invalidation MUST NOT be cached")
+	}
+
+	generation = cache.generation()
+	cache.flush()
+	cache.set("USER", "USER_01", []grant{{handle: "articles.read"}}, generation)
+
+	if _, found := cache.get("USER", "USER_01"); found {
+		t.Fatal("grants loaded before a flush MUST NOT be cached")
+	}
+
+	cache.set("USER", "USER_01", []grant{{handle: "articles.read"}}, cache.generation())
+
+	if _, found := cache.get("USER", "USER_01"); !found {
+		t.Fatal("grants loaded at the current generation MUST be cached")
+	}
+}
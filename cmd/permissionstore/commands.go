@@ -0,0 +1,610 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gouniverse/permissionstore"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// commands are the top level commands keyed by name
+var commands = map[string]func(c *cli, args []string) error{
+	"check":       (*cli).check,
+	"export":      (*cli).export,
+	"grant":       (*cli).grant,
+	"import":      (*cli).importPolicy,
+	"list-entity": (*cli).listEntity,
+	"migrate":     (*cli).migrate,
+	"permission":  (*cli).permission,
+	"revoke":      (*cli).revoke,
+}
+
+// permissionCommands are the subcommands of the permission command keyed by name
+var permissionCommands = map[string]func(c *cli, args []string) error{
+	"create": (*cli).permissionCreate,
+	"delete": (*cli).permissionDelete,
+	"list":   (*cli).permissionList,
+	"update": (*cli).permissionUpdate,
+}
+
+// migrate applies the pending migrations up to the given version, all by
+// default, or shows the migration status
+func (c *cli) migrate(args []string) error {
+	fs := c.flagSet("migrate", "[-to version] [-status]")
+	to := fs.Int("to", 0, "version to migrate to, defaults to the latest")
+	status := fs.Bool("status", false, "show the migration status without migrating")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	migrations, err := store.MigrationStatus(c.ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if !*status {
+		version := *to
+
+		if version == 0 {
+			version = migrations[len(migrations)-1].Version
+		}
+
+		if err := store.MigrateTo(c.ctx, version); err != nil {
+			return err
+		}
+
+		if migrations, err = store.MigrationStatus(c.ctx); err != nil {
+			return err
+		}
+	}
+
+	output := lo.Map(migrations, func(m permissionstore.Migration, _ int) migrationRow {
+		return migrationRow{
+			Version:     m.Version,
+			Description: m.Description,
+			Enabled:     m.Enabled,
+			Applied:     m.Applied,
+			AppliedAt:   datetime(m.AppliedAt),
+		}
+	})
+
+	rows := lo.Map(output, func(m migrationRow, _ int) []string {
+		state := lo.Ternary(m.Applied, "applied", lo.Ternary(m.Enabled, "pending", "disabled"))
+		return []string{fmt.Sprint(m.Version), state, m.AppliedAt, m.Description}
+	})
+
+	return c.print(output, []string{"VERSION", "STATE", "APPLIED AT", "DESCRIPTION"}, rows)
+}
+
+// permission dispatches the permission subcommands
+func (c *cli) permission(args []string) error {
+	if len(args) < 1 || permissionCommands[args[0]] == nil {
+		fmt.Fprintln(c.stderr, "usage: permissionstore permission list|create|update|delete [flags]")
+		return errUsage
+	}
+
+	return permissionCommands[args[0]](c, args[1:])
+}
+
+// permissionList lists the permissions ordered by handle
+func (c *cli) permissionList(args []string) error {
+	fs := c.flagSet("permission list", "[-status status] [-limit n] [-offset n] [-include-deleted]")
+	status := fs.String("status", "", "filter by status")
+	limit := fs.Int("limit", 0, "maximum number of permissions, zero for all")
+	offset := fs.Int("offset", 0, "number of permissions to skip")
+	includeDeleted := fs.Bool("include-deleted", false, "include the soft deleted permissions")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	query := permissionstore.NewPermissionQuery().
+		SetOrderBy(permissionstore.COLUMN_HANDLE).
+		SetSortDirection(sb.ASC).
+		SetSoftDeletedIncluded(*includeDeleted)
+
+	if *status != "" {
+		query.SetStatus(*status)
+	}
+
+	if *limit > 0 {
+		query.SetLimit(*limit)
+	}
+
+	if *offset > 0 {
+		query.SetOffset(*offset)
+	}
+
+	permissions, err := store.PermissionList(c.ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	return c.printPermissions(lo.Map(permissions, func(permission permissionstore.PermissionInterface, _ int) permissionRow {
+		return newPermissionRow(permission)
+	}))
+}
+
+// permissionCreate creates a permission
+func (c *cli) permissionCreate(args []string) error {
+	fs := c.flagSet("permission create", "-handle handle -title title [-memo memo] [-status status]")
+	handle := fs.String("handle", "", "handle of the permission, i.e. billing.invoice.read")
+	title := fs.String("title", "", "title of the permission")
+	memo := fs.String("memo", "", "memo of the permission")
+	status := fs.String("status", permissionstore.PERMISSION_STATUS_ACTIVE, "status of the permission")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.required(fs, "handle", "title"); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	permission := permissionstore.NewPermission().
+		SetHandle(*handle).
+		SetTitle(*title).
+		SetMemo(*memo).
+		SetStatus(*status)
+
+	if err := store.PermissionCreate(c.ctx, permission); err != nil {
+		return err
+	}
+
+	return c.printPermissions([]permissionRow{newPermissionRow(permission)})
+}
+
+// permissionUpdate updates the given fields of a permission
+func (c *cli) permissionUpdate(args []string) error {
+	fs := c.flagSet("permission update", "-id id|-handle handle [-set-handle handle] [-title title] [-memo memo] [-status status]")
+	id := fs.String("id", "", "ID of the permission")
+	handle := fs.String("handle", "", "handle of the permission")
+	setHandle := fs.String("set-handle", "", "new handle of the permission")
+	title := fs.String("title", "", "new title of the permission")
+	memo := fs.String("memo", "", "new memo of the permission")
+	status := fs.String("status", "", "new status of the permission")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	permission, err := c.permissionFind(fs, *id, *handle)
+
+	if err != nil {
+		return err
+	}
+
+	if isSet(fs, "set-handle") {
+		permission.SetHandle(*setHandle)
+	}
+
+	if isSet(fs, "title") {
+		permission.SetTitle(*title)
+	}
+
+	if isSet(fs, "memo") {
+		permission.SetMemo(*memo)
+	}
+
+	if isSet(fs, "status") {
+		permission.SetStatus(*status)
+	}
+
+	if err := c.store.PermissionUpdate(c.ctx, permission); err != nil {
+		return err
+	}
+
+	return c.printPermissions([]permissionRow{newPermissionRow(permission)})
+}
+
+// permissionDelete soft deletes a permission, or deletes it with -hard
+func (c *cli) permissionDelete(args []string) error {
+	fs := c.flagSet("permission delete", "-id id|-handle handle [-hard]")
+	id := fs.String("id", "", "ID of the permission")
+	handle := fs.String("handle", "", "handle of the permission")
+	hard := fs.Bool("hard", false, "delete the permission instead of soft deleting it")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	permission, err := c.permissionFind(fs, *id, *handle)
+
+	if err != nil {
+		return err
+	}
+
+	if *hard {
+		err = c.store.PermissionDelete(c.ctx, permission)
+	} else {
+		err = c.store.PermissionSoftDelete(c.ctx, permission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.printPermissions([]permissionRow{newPermissionRow(permission)})
+}
+
+// permissionFind opens the store and returns the permission by ID or by handle
+func (c *cli) permissionFind(fs *flag.FlagSet, id string, handle string) (permissionstore.PermissionInterface, error) {
+	if (id == "") == (handle == "") {
+		fmt.Fprintln(c.stderr, "permissionstore: one of -id or -handle is required")
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if id != "" {
+		return store.PermissionGetByID(c.ctx, id)
+	}
+
+	return store.PermissionGetByHandle(c.ctx, handle)
+}
+
+// grantFlags are the flags identifying an entity permission
+type grantFlags struct {
+	entityType   *string
+	entityID     *string
+	permission   *string
+	resourceType *string
+	resourceID   *string
+}
+
+func newGrantFlags(fs *flag.FlagSet) grantFlags {
+	return grantFlags{
+		entityType:   fs.String("entity-type", "", "type of the entity, i.e. user"),
+		entityID:     fs.String("entity-id", "", "ID of the entity"),
+		permission:   fs.String("permission", "", "handle of the permission"),
+		resourceType: fs.String("resource-type", "", "type of the resource, empty for a global grant"),
+		resourceID:   fs.String("resource-id", "", "ID of the resource, empty for a global grant"),
+	}
+}
+
+// grant grants a permission to an entity
+func (c *cli) grant(args []string) error {
+	fs := c.flagSet("grant", "-entity-type type -entity-id id -permission handle [flags]")
+	target := newGrantFlags(fs)
+	effect := fs.String("effect", permissionstore.ENTITY_PERMISSION_EFFECT_ALLOW, "effect of the grant: allow or deny")
+	validFrom := fs.String("valid-from", "", "time the grant starts being effective, YYYY-MM-DD HH:MM:SS in UTC")
+	expiresAt := fs.String("expires-at", "", "time the grant stops being effective, YYYY-MM-DD HH:MM:SS in UTC")
+	memo := fs.String("memo", "", "memo of the grant")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.required(fs, "entity-type", "entity-id", "permission"); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	permission, err := store.PermissionGetByHandle(c.ctx, *target.permission)
+
+	if err != nil {
+		return err
+	}
+
+	entityPermission := permissionstore.NewEntityPermission().
+		SetEntityType(*target.entityType).
+		SetEntityID(*target.entityID).
+		SetPermissionID(permission.ID()).
+		SetResourceType(*target.resourceType).
+		SetResourceID(*target.resourceID).
+		SetEffect(*effect).
+		SetMemo(*memo)
+
+	if *validFrom != "" {
+		entityPermission.SetValidFrom(*validFrom)
+	}
+
+	if *expiresAt != "" {
+		entityPermission.SetExpiresAt(*expiresAt)
+	}
+
+	if err := store.EntityPermissionCreate(c.ctx, entityPermission); err != nil {
+		return err
+	}
+
+	return c.printGrants([]grantRow{newGrantRow(entityPermission, permission.Handle())})
+}
+
+// revoke soft deletes the grant of a permission to an entity, or deletes it with -hard
+func (c *cli) revoke(args []string) error {
+	fs := c.flagSet("revoke", "-entity-type type -entity-id id -permission handle [-resource-type type -resource-id id] [-hard]")
+	target := newGrantFlags(fs)
+	hard := fs.Bool("hard", false, "delete the grant instead of soft deleting it")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.required(fs, "entity-type", "entity-id", "permission"); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	permission, err := store.PermissionGetByHandle(c.ctx, *target.permission)
+
+	if err != nil {
+		return err
+	}
+
+	entityPermission, err := store.EntityPermissionFindByEntityPermissionAndResource(c.ctx, *target.entityType, *target.entityID, permission.ID(), *target.resourceType, *target.resourceID)
+
+	if err != nil {
+		return err
+	}
+
+	if entityPermission == nil {
+		return errors.New("grant of " + permission.Handle() + " to " + *target.entityType + " " + *target.entityID + ": " + permissionstore.ErrNotFound.Error())
+	}
+
+	if *hard {
+		err = store.EntityPermissionDelete(c.ctx, entityPermission)
+	} else {
+		err = store.EntityPermissionSoftDelete(c.ctx, entityPermission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.printGrants([]grantRow{newGrantRow(entityPermission, permission.Handle())})
+}
+
+// check checks whether an entity is granted a permission, globally or on a resource
+func (c *cli) check(args []string) error {
+	fs := c.flagSet("check", "-entity-type type -entity-id id -permission handle [-resource-type type -resource-id id]")
+	target := newGrantFlags(fs)
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.required(fs, "entity-type", "entity-id", "permission"); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	var allowed bool
+
+	if *target.resourceType == "" && *target.resourceID == "" {
+		allowed, err = store.EntityHasPermission(c.ctx, *target.entityType, *target.entityID, *target.permission)
+	} else {
+		allowed, err = store.EntityHasPermissionOnResource(c.ctx, *target.entityType, *target.entityID, *target.permission, *target.resourceType, *target.resourceID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result := checkRow{
+		EntityType:   *target.entityType,
+		EntityID:     *target.entityID,
+		Permission:   *target.permission,
+		ResourceType: *target.resourceType,
+		ResourceID:   *target.resourceID,
+		Allowed:      allowed,
+	}
+
+	return c.print(result, []string{"ENTITY TYPE", "ENTITY ID", "PERMISSION", "RESULT"}, [][]string{
+		{result.EntityType, result.EntityID, result.Permission, lo.Ternary(allowed, "allowed", "denied")},
+	})
+}
+
+// listEntity lists the grants of an entity with the handles of their permissions
+func (c *cli) listEntity(args []string) error {
+	fs := c.flagSet("list-entity", "-entity-type type -entity-id id [-include-deleted]")
+	entityType := fs.String("entity-type", "", "type of the entity, i.e. user")
+	entityID := fs.String("entity-id", "", "ID of the entity")
+	includeDeleted := fs.Bool("include-deleted", false, "include the soft deleted grants")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.required(fs, "entity-type", "entity-id"); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	entityPermissions, err := store.EntityPermissionList(c.ctx, permissionstore.NewEntityPermissionQuery().
+		SetEntityType(*entityType).
+		SetEntityID(*entityID).
+		SetSoftDeletedIncluded(*includeDeleted).
+		SetOrderBy(permissionstore.COLUMN_CREATED_AT).
+		SetSortDirection(sb.ASC))
+
+	if err != nil {
+		return err
+	}
+
+	handles := map[string]string{}
+
+	if len(entityPermissions) > 0 {
+		permissions, err := store.PermissionList(c.ctx, permissionstore.NewPermissionQuery().
+			SetIDIn(lo.Uniq(lo.Map(entityPermissions, func(entityPermission permissionstore.EntityPermissionInterface, _ int) string {
+				return entityPermission.PermissionID()
+			}))).
+			SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			handles[permission.ID()] = permission.Handle()
+		}
+	}
+
+	return c.printGrants(lo.Map(entityPermissions, func(entityPermission permissionstore.EntityPermissionInterface, _ int) grantRow {
+		return newGrantRow(entityPermission, handles[entityPermission.PermissionID()])
+	}))
+}
+
+// export writes the policy document to stdout or a file
+func (c *cli) export(args []string) error {
+	fs := c.flagSet("export", "[-format json|yaml] [-file path]")
+	format := fs.String("format", permissionstore.POLICY_FORMAT_JSON, "document format: json or yaml")
+	file := fs.String("file", "-", "file to write, - for stdout")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	if *file == "-" {
+		return store.Export(c.ctx, c.stdout, permissionstore.ExportOptions{Format: *format})
+	}
+
+	f, err := os.Create(*file)
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.Export(c.ctx, f, permissionstore.ExportOptions{Format: *format}); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// importPolicy reads the policy document from stdin or a file and lists the changes
+func (c *cli) importPolicy(args []string) error {
+	fs := c.flagSet("import", "[-format json|yaml] [-mode merge|replace] [-dry-run] [-file path]")
+	format := fs.String("format", permissionstore.POLICY_FORMAT_JSON, "document format: json or yaml")
+	mode := fs.String("mode", permissionstore.POLICY_IMPORT_MODE_MERGE, "import mode: merge or replace")
+	dryRun := fs.Bool("dry-run", false, "list the changes without applying them")
+	file := fs.String("file", "-", "file to read, - for stdin")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	store, err := c.open()
+
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = c.stdin
+
+	if *file != "-" {
+		f, err := os.Open(*file)
+
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	report, err := store.Import(c.ctx, r, permissionstore.ImportOptions{
+		Format: *format,
+		Mode:   *mode,
+		DryRun: *dryRun,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	output := importOutput{DryRun: report.DryRun, Changes: []importRow{}}
+
+	permissionChanges := func(change string, handles []string) {
+		for _, handle := range handles {
+			output.Changes = append(output.Changes, importRow{Change: change, Kind: "permission", Key: handle})
+		}
+	}
+
+	grantChanges := func(change string, grants []permissionstore.PolicyGrant) {
+		for _, grant := range grants {
+			key := grant.EntityType + ":" + grant.EntityID + " " + grant.Permission
+
+			if grant.ResourceType != "" {
+				key += " on " + grant.ResourceType + ":" + grant.ResourceID
+			}
+
+			output.Changes = append(output.Changes, importRow{Change: change, Kind: "grant", Key: key})
+		}
+	}
+
+	permissionChanges("created", report.PermissionsCreated)
+	permissionChanges("updated", report.PermissionsUpdated)
+	permissionChanges("deleted", report.PermissionsDeleted)
+	grantChanges("created", report.GrantsCreated)
+	grantChanges("updated", report.GrantsUpdated)
+	grantChanges("deleted", report.GrantsDeleted)
+
+	rows := lo.Map(output.Changes, func(row importRow, _ int) []string {
+		return []string{row.Change, row.Kind, row.Key}
+	})
+
+	if report.DryRun && c.options.output == outputTable {
+		fmt.Fprintln(c.stderr, "dry run, no changes applied")
+	}
+
+	return c.print(output, []string{"CHANGE", "KIND", "KEY"}, rows)
+}
@@ -0,0 +1,268 @@
+// Command permissionstore administers a permission store from the command line.
+//
+// Usage:
+//
+//	permissionstore [global flags] <command> [flags]
+//
+// The global flags select the database and the table names:
+//
+//	-driver                   database driver: sqlite (default), mysql or postgres
+//	-dsn                      data source name, i.e. a file path for sqlite
+//	-permission-table         permission table name (default "permissions")
+//	-entity-permission-table  entity permission table name (default "entities_permissions")
+//	-role-table, -role-permission-table, -entity-role-table
+//	                          role table names, optional, set all three to enable roles
+//	-audit-table              audit log table name, optional
+//	-output                   output mode: table (default) or json
+//
+// The commands are:
+//
+//	migrate            applies the pending schema migrations, or shows their status
+//	permission list    lists the permissions
+//	permission create  creates a permission
+//	permission update  updates a permission
+//	permission delete  soft deletes (or deletes) a permission
+//	grant              grants a permission to an entity
+//	revoke             revokes a permission from an entity
+//	check              checks whether an entity is granted a permission
+//	list-entity        lists the permissions granted to an entity
+//	export             writes the permissions and grants as a policy document
+//	import             reads a policy document into the store
+//
+// Run "permissionstore <command> -h" for the flags of a command.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/gouniverse/permissionstore"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage is returned for invalid command lines, the usage is already printed
+var errUsage = errors.New("usage")
+
+// driverNames maps the -driver values to the registered database/sql driver names
+var driverNames = map[string]string{
+	"sqlite":   "sqlite",
+	"mysql":    "mysql",
+	"postgres": "postgres",
+}
+
+// globalOptions are the options shared by all commands
+type globalOptions struct {
+	driver                string
+	dsn                   string
+	permissionTable       string
+	entityPermissionTable string
+	roleTable             string
+	rolePermissionTable   string
+	entityRoleTable       string
+	auditTable            string
+	output                string
+}
+
+// cli is the state of a single invocation
+type cli struct {
+	ctx     context.Context
+	options globalOptions
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	store   permissionstore.StoreInterface
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	c := &cli{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	fs := flag.NewFlagSet("permissionstore", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.options.driver, "driver", "sqlite", "database driver: sqlite, mysql or postgres")
+	fs.StringVar(&c.options.dsn, "dsn", "", "data source name")
+	fs.StringVar(&c.options.permissionTable, "permission-table", "permissions", "permission table name")
+	fs.StringVar(&c.options.entityPermissionTable, "entity-permission-table", "entities_permissions", "entity permission table name")
+	fs.StringVar(&c.options.roleTable, "role-table", "", "role table name, optional")
+	fs.StringVar(&c.options.rolePermissionTable, "role-permission-table", "", "role permission table name, optional")
+	fs.StringVar(&c.options.entityRoleTable, "entity-role-table", "", "entity role table name, optional")
+	fs.StringVar(&c.options.auditTable, "audit-table", "", "audit log table name, optional")
+	fs.StringVar(&c.options.output, "output", outputTable, "output mode: table or json")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: permissionstore [global flags] <command> [flags]")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "commands: migrate, permission list|create|update|delete, grant, revoke, check, list-entity, export, import")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "global flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	if c.options.output != outputTable && c.options.output != outputJSON {
+		fmt.Fprintln(stderr, "permissionstore: -output must be table or json")
+		return exitUsage
+	}
+
+	command, exists := commands[fs.Arg(0)]
+
+	if !exists {
+		fmt.Fprintln(stderr, "permissionstore: unknown command "+fs.Arg(0))
+		fs.Usage()
+		return exitUsage
+	}
+
+	err := command(c, fs.Args()[1:])
+
+	if c.store != nil {
+		_ = c.store.DB().Close()
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	}
+
+	fmt.Fprintln(stderr, "error: "+err.Error())
+
+	return exitError
+}
+
+// open opens the database and creates the store. The schema is not migrated,
+// run the migrate command for it
+func (c *cli) open() (permissionstore.StoreInterface, error) {
+	if c.store != nil {
+		return c.store, nil
+	}
+
+	if c.options.dsn == "" {
+		return nil, errors.New("-dsn is required")
+	}
+
+	driverName, exists := driverNames[c.options.driver]
+
+	if !exists {
+		return nil, errors.New("-driver must be one of sqlite, mysql or postgres")
+	}
+
+	db, err := sql.Open(driverName, c.options.dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.PingContext(c.ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	store, err := permissionstore.NewStore(permissionstore.NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       c.options.permissionTable,
+		EntityPermissionTableName: c.options.entityPermissionTable,
+		RoleTableName:             c.options.roleTable,
+		RolePermissionTableName:   c.options.rolePermissionTable,
+		EntityRoleTableName:       c.options.entityRoleTable,
+		AuditTableName:            c.options.auditTable,
+	})
+
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	c.store = store
+
+	return store, nil
+}
+
+// flagSet returns a flag set for a command, which prints its errors and usage to stderr
+func (c *cli) flagSet(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(c.stderr, "usage: permissionstore "+name+" "+usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses the flags of a command, positional arguments are not accepted
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintln(c.stderr, "permissionstore: unexpected arguments: "+strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// required reports a usage error, when one of the named flags is empty
+func (c *cli) required(fs *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if f := fs.Lookup(name); f != nil && f.Value.String() == "" {
+			fmt.Fprintln(c.stderr, "permissionstore: -"+name+" is required")
+			fs.Usage()
+			return errUsage
+		}
+	}
+
+	return nil
+}
+
+// isSet reports whether the named flag has been given on the command line
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
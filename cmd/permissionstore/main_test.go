@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI runs the command line against the SQLite file and returns the output and exit code
+func runCLI(t *testing.T, dsn string, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	code := run(context.Background(), append([]string{"-dsn", dsn}, args...), strings.NewReader(stdin), stdout, stderr)
+
+	return stdout.String(), stderr.String(), code
+}
+
+// mustRunCLI runs the command line and fails the test, when it does not succeed
+func mustRunCLI(t *testing.T, dsn string, args ...string) string {
+	t.Helper()
+
+	stdout, stderr, code := runCLI(t, dsn, "", args...)
+
+	if code != exitOK {
+		t.Fatalf("%v: exit code %d, stderr: %s", args, code, stderr)
+	}
+
+	return stdout
+}
+
+// initDSN returns the path of a migrated SQLite file in a temporary directory
+func initDSN(t *testing.T) string {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "permissions.db")
+
+	mustRunCLI(t, dsn, "migrate")
+
+	return dsn
+}
+
+func TestMigrate(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "permissions.db")
+
+	stdout := mustRunCLI(t, dsn, "migrate", "-to", "2")
+
+	if !strings.Contains(stdout, "applied") || !strings.Contains(stdout, "pending") {
+		t.Fatal("migrations 1 and 2 must be applied and the others pending, found:", stdout)
+	}
+
+	stdout = mustRunCLI(t, dsn, "-output", "json", "migrate")
+
+	migrations := []migrationRow{}
+
+	if err := json.Unmarshal([]byte(stdout), &migrations); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, m := range migrations {
+		if m.Enabled && !m.Applied {
+			t.Fatal("migration", m.Version, "must be applied")
+		}
+	}
+}
+
+func TestPermissionCommands(t *testing.T) {
+	dsn := initDSN(t)
+
+	mustRunCLI(t, dsn, "permission", "create", "-handle", "billing.invoice.read", "-title", "Read invoices")
+	mustRunCLI(t, dsn, "permission", "create", "-handle", "billing.invoice.write", "-title", "Write invoices")
+
+	_, stderr, code := runCLI(t, dsn, "", "permission", "create", "-handle", "billing.invoice.read", "-title", "Duplicate")
+
+	if code != exitError {
+		t.Fatal("duplicate handle must fail with exit code", exitError, "found:", code)
+	}
+
+	if !strings.Contains(stderr, "same handle") {
+		t.Fatal("error must report the duplicate handle, found:", stderr)
+	}
+
+	stdout := mustRunCLI(t, dsn, "permission", "list")
+
+	if !strings.HasPrefix(stdout, "ID") || !strings.Contains(stdout, "Read invoices") || !strings.Contains(stdout, "Write invoices") {
+		t.Fatal("table must list both permissions, found:", stdout)
+	}
+
+	mustRunCLI(t, dsn, "permission", "update", "-handle", "billing.invoice.write", "-title", "Edit invoices", "-status", "inactive")
+	mustRunCLI(t, dsn, "permission", "delete", "-handle", "billing.invoice.read")
+
+	stdout = mustRunCLI(t, dsn, "-output", "json", "permission", "list")
+
+	permissions := []permissionRow{}
+
+	if err := json.Unmarshal([]byte(stdout), &permissions); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(permissions) != 1 {
+		t.Fatal("soft deleted permission must not be listed, found:", permissions)
+	}
+
+	if permissions[0].Handle != "billing.invoice.write" || permissions[0].Title != "Edit invoices" || permissions[0].Status != "inactive" {
+		t.Fatal("permission must be updated, found:", permissions[0])
+	}
+
+	stdout = mustRunCLI(t, dsn, "-output", "json", "permission", "list", "-include-deleted")
+
+	if err := json.Unmarshal([]byte(stdout), &permissions); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(permissions) != 2 {
+		t.Fatal("soft deleted permission must be listed with -include-deleted, found:", permissions)
+	}
+}
+
+func TestGrantCheckRevoke(t *testing.T) {
+	dsn := initDSN(t)
+
+	mustRunCLI(t, dsn, "permission", "create", "-handle", "reports.view", "-title", "View reports")
+
+	check := func(args ...string) bool {
+		t.Helper()
+
+		stdout := mustRunCLI(t, dsn, append([]string{"-output", "json", "check", "-entity-type", "user", "-entity-id", "U1", "-permission", "reports.view"}, args...)...)
+
+		result := checkRow{}
+
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		return result.Allowed
+	}
+
+	if check() {
+		t.Fatal("permission must not be granted before the grant")
+	}
+
+	mustRunCLI(t, dsn, "grant", "-entity-type", "user", "-entity-id", "U1", "-permission", "reports.view")
+	mustRunCLI(t, dsn, "grant", "-entity-type", "user", "-entity-id", "U1", "-permission", "reports.view", "-effect", "deny", "-resource-type", "report", "-resource-id", "R1")
+
+	if !check() {
+		t.Fatal("permission must be granted after the grant")
+	}
+
+	if check("-resource-type", "report", "-resource-id", "R1") {
+		t.Fatal("resource scoped deny must override the global grant")
+	}
+
+	if !check("-resource-type", "report", "-resource-id", "R2") {
+		t.Fatal("global grant must apply to the other resources")
+	}
+
+	stdout := mustRunCLI(t, dsn, "list-entity", "-entity-type", "user", "-entity-id", "U1")
+
+	if !strings.Contains(stdout, "report:R1") || strings.Count(stdout, "reports.view") != 2 {
+		t.Fatal("both grants must be listed with the handle, found:", stdout)
+	}
+
+	mustRunCLI(t, dsn, "revoke", "-entity-type", "user", "-entity-id", "U1", "-permission", "reports.view")
+
+	if check() {
+		t.Fatal("permission must not be granted after the revoke")
+	}
+
+	_, stderr, code := runCLI(t, dsn, "", "revoke", "-entity-type", "user", "-entity-id", "U1", "-permission", "reports.view")
+
+	if code != exitError || !strings.Contains(stderr, "not found") {
+		t.Fatal("revoking a missing grant must fail with not found, found:", code, stderr)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source := initDSN(t)
+	target := initDSN(t)
+
+	mustRunCLI(t, source, "permission", "create", "-handle", "orders.read", "-title", "Read orders")
+	mustRunCLI(t, source, "grant", "-entity-type", "group", "-entity-id", "G1", "-permission", "orders.read")
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+
+	mustRunCLI(t, source, "export", "-format", "yaml", "-file", file)
+
+	stdout := mustRunCLI(t, target, "-output", "json", "import", "-format", "yaml", "-file", file, "-dry-run")
+
+	output := importOutput{}
+
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !output.DryRun || len(output.Changes) != 2 {
+		t.Fatal("dry run must plan a permission and a grant, found:", output)
+	}
+
+	document, err := os.ReadFile(file)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, stderr, code := runCLI(t, target, string(document), "import", "-format", "yaml")
+
+	if code != exitOK {
+		t.Fatal("import from stdin must succeed, found:", code, stderr)
+	}
+
+	stdout = mustRunCLI(t, target, "check", "-entity-type", "group", "-entity-id", "G1", "-permission", "orders.read")
+
+	if !strings.Contains(stdout, "allowed") {
+		t.Fatal("imported grant must allow the permission, found:", stdout)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "permissions.db")
+
+	cases := [][]string{
+		{},
+		{"unknown"},
+		{"permission"},
+		{"permission", "update"},
+		{"grant", "-entity-type", "user"},
+		{"-output", "xml", "permission", "list"},
+		{"permission", "list", "extra"},
+	}
+
+	for _, args := range cases {
+		if _, _, code := runCLI(t, dsn, "", args...); code != exitUsage {
+			t.Fatal(args, "must fail with exit code", exitUsage, "found:", code)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	if code := run(context.Background(), []string{"permission", "list"}, nil, stdout, stderr); code != exitError || !strings.Contains(stderr.String(), "-dsn is required") {
+		t.Fatal("missing -dsn must fail, found:", code, stderr.String())
+	}
+}
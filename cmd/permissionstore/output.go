@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/permissionstore"
+	"github.com/gouniverse/sb"
+)
+
+// output modes
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// permissionRow is the output of a permission
+type permissionRow struct {
+	ID            string `json:"id"`
+	Handle        string `json:"handle"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	Memo          string `json:"memo"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	SoftDeletedAt string `json:"soft_deleted_at,omitempty"`
+}
+
+// grantRow is the output of an entity permission
+type grantRow struct {
+	ID            string `json:"id"`
+	EntityType    string `json:"entity_type"`
+	EntityID      string `json:"entity_id"`
+	PermissionID  string `json:"permission_id"`
+	Permission    string `json:"permission"`
+	Effect        string `json:"effect"`
+	ResourceType  string `json:"resource_type"`
+	ResourceID    string `json:"resource_id"`
+	ValidFrom     string `json:"valid_from,omitempty"`
+	ExpiresAt     string `json:"expires_at,omitempty"`
+	Memo          string `json:"memo"`
+	SoftDeletedAt string `json:"soft_deleted_at,omitempty"`
+}
+
+// checkRow is the output of a permission check
+type checkRow struct {
+	EntityType   string `json:"entity_type"`
+	EntityID     string `json:"entity_id"`
+	Permission   string `json:"permission"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Allowed      bool   `json:"allowed"`
+}
+
+// migrationRow is the output of a schema migration
+type migrationRow struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Applied     bool   `json:"applied"`
+	AppliedAt   string `json:"applied_at,omitempty"`
+}
+
+// importRow is a single change of an import
+type importRow struct {
+	Change string `json:"change"`
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+}
+
+// importOutput is the output of an import
+type importOutput struct {
+	DryRun  bool        `json:"dry_run"`
+	Changes []importRow `json:"changes"`
+}
+
+func newPermissionRow(permission permissionstore.PermissionInterface) permissionRow {
+	return permissionRow{
+		ID:            permission.ID(),
+		Handle:        permission.Handle(),
+		Title:         permission.Title(),
+		Status:        permission.Status(),
+		Memo:          permission.Memo(),
+		CreatedAt:     datetime(permission.CreatedAt()),
+		UpdatedAt:     datetime(permission.UpdatedAt()),
+		SoftDeletedAt: datetime(permission.SoftDeletedAt()),
+	}
+}
+
+func newGrantRow(entityPermission permissionstore.EntityPermissionInterface, handle string) grantRow {
+	return grantRow{
+		ID:            entityPermission.ID(),
+		EntityType:    entityPermission.EntityType(),
+		EntityID:      entityPermission.EntityID(),
+		PermissionID:  entityPermission.PermissionID(),
+		Permission:    handle,
+		Effect:        entityPermission.Effect(),
+		ResourceType:  entityPermission.ResourceType(),
+		ResourceID:    entityPermission.ResourceID(),
+		ValidFrom:     datetime(entityPermission.ValidFrom()),
+		ExpiresAt:     datetime(entityPermission.ExpiresAt()),
+		Memo:          entityPermission.Memo(),
+		SoftDeletedAt: datetime(entityPermission.SoftDeletedAt()),
+	}
+}
+
+// datetime normalizes a datetime column to "YYYY-MM-DD HH:MM:SS" in UTC,
+// the open ended values, i.e. "not deleted" or "never expires", are empty
+func datetime(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	normalized := carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	if normalized == "" {
+		return value
+	}
+
+	if normalized == sb.MAX_DATETIME || normalized == sb.NULL_DATETIME {
+		return ""
+	}
+
+	return normalized
+}
+
+// print writes the value as JSON, or the rows as a table with the headers
+func (c *cli) print(value any, headers []string, rows [][]string) error {
+	if c.options.output == outputJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(value)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+
+	if _, err := w.Write([]byte(strings.Join(headers, "\t") + "\n")); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if _, err := w.Write([]byte(strings.Join(row, "\t") + "\n")); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func (c *cli) printPermissions(permissions []permissionRow) error {
+	rows := make([][]string, 0, len(permissions))
+
+	for _, p := range permissions {
+		rows = append(rows, []string{p.ID, p.Handle, p.Title, p.Status})
+	}
+
+	return c.print(permissions, []string{"ID", "HANDLE", "TITLE", "STATUS"}, rows)
+}
+
+func (c *cli) printGrants(grants []grantRow) error {
+	rows := make([][]string, 0, len(grants))
+
+	for _, g := range grants {
+		resource := ""
+
+		if g.ResourceType != "" {
+			resource = g.ResourceType + ":" + g.ResourceID
+		}
+
+		rows = append(rows, []string{g.ID, g.EntityType, g.EntityID, g.Permission, g.Effect, resource, g.ValidFrom, g.ExpiresAt})
+	}
+
+	return c.print(grants, []string{"ID", "ENTITY TYPE", "ENTITY ID", "PERMISSION", "EFFECT", "RESOURCE", "VALID FROM", "EXPIRES AT"}, rows)
+}
@@ -4,19 +4,98 @@ const ERROR_EMPTY_ARRAY = "array cannot be empty"
 const ERROR_EMPTY_STRING = "string cannot be empty"
 const ERROR_NEGATIVE_NUMBER = "number cannot be negative"
 
+const COLUMN_ACTOR = "actor"
+const COLUMN_APPLIED_AT = "applied_at"
 const COLUMN_CREATED_AT = "created_at"
+const COLUMN_DATA_AFTER = "data_after"
+const COLUMN_DATA_BEFORE = "data_before"
+const COLUMN_DESCRIPTION = "description"
+const COLUMN_EFFECT = "effect"
 const COLUMN_ENTITY_ID = "entity_id"
 const COLUMN_ENTITY_TYPE = "entity_type"
+const COLUMN_EXPIRES_AT = "expires_at"
 const COLUMN_HANDLE = "handle"
 const COLUMN_ID = "id"
+const COLUMN_MEMBER_ID = "member_id"
+const COLUMN_MEMBER_TYPE = "member_type"
 const COLUMN_MEMO = "memo"
 const COLUMN_METAS = "metas"
+const COLUMN_OPERATION = "operation"
+const COLUMN_PARENT_ID = "parent_id"
+const COLUMN_PARENT_TYPE = "parent_type"
 const COLUMN_PERMISSION_ID = "permission_id"
+const COLUMN_RESOURCE_ID = "resource_id"
+const COLUMN_RESOURCE_TYPE = "resource_type"
+const COLUMN_ROLE_ID = "role_id"
+const COLUMN_ROW_ID = "row_id"
 const COLUMN_STATUS = "status"
 const COLUMN_SOFT_DELETED_AT = "soft_deleted_at"
+const COLUMN_TABLE_NAME = "table_name"
 const COLUMN_TITLE = "title"
 const COLUMN_UPDATED_AT = "updated_at"
+const COLUMN_VALID_FROM = "valid_from"
+const COLUMN_VERSION = "version"
+
+const AUDIT_OPERATION_CREATE = "create"
+const AUDIT_OPERATION_DELETE = "delete"
+const AUDIT_OPERATION_PURGE = "purge"
+const AUDIT_OPERATION_SOFT_DELETE = "soft_delete"
+const AUDIT_OPERATION_UPDATE = "update"
+
+const BULK_STATUS_CREATED = "created"
+const BULK_STATUS_DUPLICATE = "duplicate"
+const BULK_STATUS_NOT_FOUND = "not_found"
+const BULK_STATUS_REVOKED = "revoked"
+
+const ENTITY_PERMISSION_EFFECT_ALLOW = "allow"
+const ENTITY_PERMISSION_EFFECT_DENY = "deny"
+
+const ENTITY_PERMISSION_VALIDITY_ACTIVE = "active"
+const ENTITY_PERMISSION_VALIDITY_EXPIRED = "expired"
+const ENTITY_PERMISSION_VALIDITY_PENDING = "pending"
+
+const EXPLAIN_OUTCOME_MATCHED = "matched"
+const EXPLAIN_OUTCOME_SKIPPED = "skipped"
+
+const EXPLAIN_REASON_EXPIRED = "expired"
+const EXPLAIN_REASON_HANDLE_MISMATCH = "handle_mismatch"
+const EXPLAIN_REASON_PENDING = "pending"
+const EXPLAIN_REASON_PERMISSION_INACTIVE = "permission_inactive"
+const EXPLAIN_REASON_PERMISSION_NOT_FOUND = "permission_not_found"
+const EXPLAIN_REASON_PERMISSION_SOFT_DELETED = "permission_soft_deleted"
+const EXPLAIN_REASON_RESOURCE_SCOPED = "resource_scoped"
+const EXPLAIN_REASON_ROLE_INACTIVE = "role_inactive"
+const EXPLAIN_REASON_ROLE_NOT_FOUND = "role_not_found"
+const EXPLAIN_REASON_ROLE_SOFT_DELETED = "role_soft_deleted"
+const EXPLAIN_REASON_SOFT_DELETED = "soft_deleted"
+const EXPLAIN_REASON_WRONG_ENTITY = "wrong_entity"
+
+const EXPLAIN_SOURCE_DIRECT = "direct"
+const EXPLAIN_SOURCE_MEMBERSHIP = "membership"
+const EXPLAIN_SOURCE_ROLE = "role"
+
+const MEMBERSHIP_MAX_DEPTH_DEFAULT = 5
+
+const PERMISSION_HANDLE_MAX_LENGTH = 50
+const PERMISSION_HANDLE_SEPARATOR = "."
+const PERMISSION_HANDLE_WILDCARD = "*"
+
+const PERMISSION_SYNC_MISSING_DEACTIVATE = "deactivate"
+const PERMISSION_SYNC_MISSING_KEEP = "keep"
+const PERMISSION_SYNC_MISSING_SOFT_DELETE = "soft_delete"
 
 const PERMISSION_STATUS_ACTIVE = "active"
 const PERMISSION_STATUS_INACTIVE = "inactive"
 const PERMISSION_STATUS_DELETED = "deleted"
+
+const POLICY_DOCUMENT_VERSION = 1
+
+const POLICY_FORMAT_JSON = "json"
+const POLICY_FORMAT_YAML = "yaml"
+
+const POLICY_IMPORT_MODE_MERGE = "merge"
+const POLICY_IMPORT_MODE_REPLACE = "replace"
+
+const ROLE_STATUS_ACTIVE = "active"
+const ROLE_STATUS_INACTIVE = "inactive"
+const ROLE_STATUS_DELETED = "deleted"
@@ -0,0 +1,146 @@
+package permissionstore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned by the Get methods, when the record does not exist
+var ErrNotFound = errors.New("permissionstore: not found")
+
+// ErrDuplicateGrant is returned when an entity permission, a role permission,
+// an entity role or an entity membership is created or updated with the same
+// relation as another one, which is not soft deleted
+var ErrDuplicateGrant = errors.New("permissionstore: duplicate grant")
+
+// ErrConflictingGrant is returned when an allow grant cannot be made, as the
+// entity holds a deny grant of the same permission
+var ErrConflictingGrant = errors.New("permissionstore: conflicting grant")
+
+// ErrDuplicateHandle is returned when a permission is created or updated
+// with the handle of another permission, which is not soft deleted
+var ErrDuplicateHandle = errors.New("permissionstore: permission with the same handle already exists")
+
+// ErrInvalidQuery is returned when the query options do not validate
+var ErrInvalidQuery = errors.New("permissionstore: invalid query")
+
+// ErrNilDatabase is returned when the store has no database
+var ErrNilDatabase = errors.New("permissionstore: database is nil")
+
+// Error is an error of a store operation, carrying the operation and the IDs
+// of the records involved. The underlying error, usually one of the sentinel
+// errors, can be checked with errors.Is
+type Error struct {
+	// Op is the store operation, i.e. "PermissionGetByHandle"
+	Op string
+
+	// IDs are the identifiers of the records involved keyed by name, i.e. "handle"
+	IDs map[string]string
+
+	// Err is the underlying error
+	Err error
+}
+
+// newError returns an error of the operation, the ids are given as name-value pairs
+func newError(op string, err error, ids ...string) *Error {
+	e := &Error{Op: op, IDs: map[string]string{}, Err: err}
+
+	for i := 0; i+1 < len(ids); i += 2 {
+		e.IDs[ids[i]] = ids[i+1]
+	}
+
+	return e
+}
+
+// newInvalidQueryError returns an ErrInvalidQuery error of the operation,
+// keeping the message of the validation error
+func newInvalidQueryError(op string, err error) *Error {
+	return newError(op, fmt.Errorf("%w: %s", ErrInvalidQuery, err.Error()))
+}
+
+// Error returns the message of the error, i.e.
+// "permissionstore > PermissionGetByHandle. not found (handle: user.read)"
+func (e *Error) Error() string {
+	message := "permissionstore > " + e.Op + ". "
+
+	if e.Err != nil {
+		message += strings.TrimPrefix(e.Err.Error(), "permissionstore: ")
+	}
+
+	if len(e.IDs) < 1 {
+		return message
+	}
+
+	names := make([]string, 0, len(e.IDs))
+
+	for name := range e.IDs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	ids := make([]string, 0, len(names))
+
+	for _, name := range names {
+		ids = append(ids, name+": "+e.IDs[name])
+	}
+
+	return message + " (" + strings.Join(ids, ", ") + ")"
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// MigrationConflictError is returned by MigrateTo, when a unique index cannot be
+// created, as rows which are not soft deleted share the same key. The rows must be
+// resolved, i.e. soft deleted, before migrating again.
+// It unwraps to ErrDuplicateGrant or ErrDuplicateHandle
+type MigrationConflictError struct {
+	// Table is the table of the unique index
+	Table string
+
+	// Columns are the columns of the unique index
+	Columns []string
+
+	// Keys are the values of the columns shared by the conflicting rows, ordered
+	Keys [][]string
+
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the message of the error, i.e.
+// "permissionstore: duplicate grant in permissions_entity_permission_table (entity_type, entity_id, ...): (USER, USER_01, ...)"
+func (e *MigrationConflictError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+
+	for _, key := range e.Keys {
+		keys = append(keys, "("+strings.Join(key, ", ")+")")
+	}
+
+	return e.Err.Error() + " in " + e.Table + " (" + strings.Join(e.Columns, ", ") + "): " + strings.Join(keys, ", ")
+}
+
+// Unwrap returns the underlying error
+func (e *MigrationConflictError) Unwrap() error {
+	return e.Err
+}
+
+// isUniqueViolation returns true if the error is a unique constraint violation
+// reported by one of the supported database drivers
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := err.Error()
+
+	return strings.Contains(message, "UNIQUE constraint failed") || // sqlite
+		strings.Contains(message, "Duplicate entry") || // mysql
+		strings.Contains(message, "duplicate key value violates unique constraint") || // postgres
+		strings.Contains(message, "SQLSTATE 23505") // postgres (pgx)
+}
@@ -0,0 +1,63 @@
+package permissionstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	err := newError("PermissionGetByHandle", ErrNotFound, "handle", "articles.read", "id", "PERMISSION_01")
+
+	expected := "permissionstore > PermissionGetByHandle. not found (handle: articles.read, id: PERMISSION_01)"
+
+	if err.Error() != expected {
+		t.Fatal("expected", expected, "found:", err.Error())
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must unwrap to ErrNotFound")
+	}
+
+	var storeErr *Error
+
+	if !errors.As(error(err), &storeErr) {
+		t.Fatal("must be an *Error")
+	}
+
+	if storeErr.Op != "PermissionGetByHandle" || storeErr.IDs["handle"] != "articles.read" {
+		t.Fatal("unexpected error fields:", storeErr.Op, storeErr.IDs)
+	}
+}
+
+func TestError_InvalidQuery(t *testing.T) {
+	err := newInvalidQueryError("PermissionQuery", errors.New("permission query. limit must be greater than 0"))
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("must unwrap to ErrInvalidQuery")
+	}
+
+	expected := "permissionstore > PermissionQuery. invalid query: permission query. limit must be greater than 0"
+
+	if err.Error() != expected {
+		t.Fatal("expected", expected, "found:", err.Error())
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	violations := []string{
+		"constraint failed: UNIQUE constraint failed: permissions.handle (2067)",
+		"Error 1062 (23000): Duplicate entry 'articles.read' for key 'handle_unique'",
+		`pq: duplicate key value violates unique constraint "handle_unique"`,
+		"ERROR: duplicate key (SQLSTATE 23505)",
+	}
+
+	for _, violation := range violations {
+		if !isUniqueViolation(errors.New(violation)) {
+			t.Fatal("must be a unique violation:", violation)
+		}
+	}
+
+	if isUniqueViolation(nil) || isUniqueViolation(errors.New("no such table")) {
+		t.Fatal("must not be a unique violation")
+	}
+}
@@ -0,0 +1,138 @@
+package permissionstore
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Explanation is the decision trace of EntityPermissionExplain. It lists
+// the grants of the entity considered for the handle, whether each of them
+// matched or was skipped and why, and the final verdict
+type Explanation struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Handle     string `json:"handle"`
+
+	// Allowed is the verdict, the same as the one of EntityHasPermission
+	Allowed bool `json:"allowed"`
+
+	// Reason describes the grant deciding the verdict, if any
+	Reason string `json:"reason"`
+
+	// Steps are the grants considered, the direct grants first, then
+	// the grants inherited via memberships and the grants via roles
+	Steps []ExplainStep `json:"steps"`
+}
+
+// ExplainStep is a single grant considered by EntityPermissionExplain
+type ExplainStep struct {
+	// Source is EXPLAIN_SOURCE_DIRECT, EXPLAIN_SOURCE_MEMBERSHIP or EXPLAIN_SOURCE_ROLE
+	Source string `json:"source"`
+
+	// EntityType and EntityID are the entity holding the grant, the ancestor
+	// of the entity for the grants inherited via memberships
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+
+	EntityPermissionID string `json:"entity_permission_id,omitempty"`
+	EntityRoleID       string `json:"entity_role_id,omitempty"`
+	RoleID             string `json:"role_id,omitempty"`
+	RoleHandle         string `json:"role_handle,omitempty"`
+	RolePermissionID   string `json:"role_permission_id,omitempty"`
+	PermissionID       string `json:"permission_id,omitempty"`
+	PermissionHandle   string `json:"permission_handle,omitempty"`
+	Effect             string `json:"effect,omitempty"`
+	ResourceType       string `json:"resource_type,omitempty"`
+	ResourceID         string `json:"resource_id,omitempty"`
+
+	// Outcome is EXPLAIN_OUTCOME_MATCHED or EXPLAIN_OUTCOME_SKIPPED
+	Outcome string `json:"outcome"`
+
+	// Reason is one of the EXPLAIN_REASON_* constants for the skipped grants
+	Reason string `json:"reason,omitempty"`
+
+	// Deciding is true for the grant deciding the verdict
+	Deciding bool `json:"deciding,omitempty"`
+}
+
+// IsMatched returns true if the grant applies to the handle
+func (s ExplainStep) IsMatched() bool {
+	return s.Outcome == EXPLAIN_OUTCOME_MATCHED
+}
+
+// String renders the explanation as text, one line for the verdict
+// followed by one line per step. The deciding step is marked with "*"
+func (e Explanation) String() string {
+	verdict := "denied"
+
+	if e.Allowed {
+		verdict = "allowed"
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(e.EntityType + ":" + e.EntityID + " " + strconv.Quote(e.Handle) + " " + verdict + ": " + e.Reason + "\n")
+
+	for _, step := range e.Steps {
+		marker := " "
+
+		if step.Deciding {
+			marker = "*"
+		}
+
+		sb.WriteString(marker + " " + step.String() + "\n")
+	}
+
+	return sb.String()
+}
+
+// String renders the step as a single line of text
+func (s ExplainStep) String() string {
+	line := s.Outcome + " " + s.describe()
+
+	if s.Reason != "" {
+		line += " (" + s.Reason + ")"
+	}
+
+	return line
+}
+
+// describe returns the description of the grant of the step
+func (s ExplainStep) describe() string {
+	description := s.Source + " grant"
+
+	if s.EntityPermissionID != "" {
+		description += " " + s.EntityPermissionID
+	}
+
+	description += " of " + s.EntityType + ":" + s.EntityID
+
+	if s.RoleID != "" {
+		description += " via role " + strconv.Quote(s.RoleHandle) + " (" + s.RoleID + ")"
+	}
+
+	if s.PermissionHandle != "" {
+		description += " on " + strconv.Quote(s.PermissionHandle)
+	}
+
+	if s.ResourceType != "" || s.ResourceID != "" {
+		description += " for " + s.ResourceType + ":" + s.ResourceID
+	}
+
+	if s.Effect != "" {
+		description += " [" + s.Effect + "]"
+	}
+
+	return description
+}
+
+// grant returns the grant of a matched step for the evaluation of the verdict.
+// Skipped steps are never evaluated, so the validity window is not needed
+func (s ExplainStep) grant() grant {
+	return grant{
+		handle:       s.PermissionHandle,
+		effect:       s.Effect,
+		resourceType: s.ResourceType,
+		resourceID:   s.ResourceID,
+	}
+}
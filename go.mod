@@ -7,18 +7,22 @@ toolchain go1.23.4
 require (
 	github.com/doug-martin/goqu/v9 v9.19.0
 	github.com/dromara/carbon/v2 v2.5.2
+	github.com/go-sql-driver/mysql v1.8.1
 	github.com/gouniverse/base v0.1.0
 	github.com/gouniverse/dataobject v0.3.0
 	github.com/gouniverse/maputils v0.7.0
 	github.com/gouniverse/sb v0.8.0
 	github.com/gouniverse/uid v1.5.0
 	github.com/gouniverse/utils v1.45.4
+	github.com/lib/pq v1.10.9
 	github.com/samber/lo v1.47.0
 	github.com/spf13/cast v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.34.2
 )
 
 require (
+	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/darkoatanasovski/htmltags v1.0.0 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/georgysavva/scany v1.2.2 // indirect
@@ -0,0 +1,70 @@
+package permissionstore
+
+import "testing"
+
+func TestGrantsAllow(t *testing.T) {
+	allow := func(handle string) grant {
+		return grant{handle: handle, effect: ENTITY_PERMISSION_EFFECT_ALLOW}
+	}
+
+	deny := func(handle string) grant {
+		return grant{handle: handle, effect: ENTITY_PERMISSION_EFFECT_DENY}
+	}
+
+	testCases := []struct {
+		name     string
+		grants   []grant
+		handle   string
+		expected bool
+	}{
+		{"no grants", []grant{}, "reports.sales", false},
+		{"allow", []grant{allow("reports.sales")}, "reports.sales", true},
+		{"deny only", []grant{deny("reports.sales")}, "reports.sales", false},
+		{"equal specificity deny wins", []grant{allow("reports.sales"), deny("reports.sales")}, "reports.sales", false},
+		{"specific deny beats wildcard allow", []grant{allow("reports.*"), deny("reports.payroll")}, "reports.payroll", false},
+		{"wildcard allow outside deny", []grant{allow("reports.*"), deny("reports.payroll")}, "reports.sales", true},
+		{"specific allow beats wildcard deny", []grant{deny("reports.*"), allow("reports.payroll")}, "reports.payroll", true},
+		{"root deny beats root allow", []grant{allow("*"), deny("*")}, "reports.sales", false},
+		{"branch allow beats root deny", []grant{deny("*"), allow("reports.*")}, "reports.sales", true},
+		{"empty effect allows", []grant{{handle: "reports.sales"}}, "reports.sales", true},
+	}
+
+	for _, testCase := range testCases {
+		allowed := grantsAllow(testCase.grants, testCase.handle, "", "")
+
+		if allowed != testCase.expected {
+			t.Fatal(testCase.name, "expected", testCase.expected, "got", allowed)
+		}
+	}
+}
+
+func TestGrantsAllow_Resource(t *testing.T) {
+	grants := []grant{
+		{handle: "documents.edit", effect: ENTITY_PERMISSION_EFFECT_ALLOW},
+		{handle: "documents.edit", effect: ENTITY_PERMISSION_EFFECT_DENY, resourceType: "DOCUMENT", resourceID: "DOCUMENT_07"},
+		{handle: "documents.*", effect: ENTITY_PERMISSION_EFFECT_DENY, resourceType: "DOCUMENT", resourceID: "DOCUMENT_08"},
+	}
+
+	testCases := []struct {
+		resourceID string
+		expected   bool
+	}{
+		{"", true},
+		{"DOCUMENT_07", false},
+		{"DOCUMENT_08", true},
+		{"DOCUMENT_09", true},
+	}
+
+	for _, testCase := range testCases {
+		resourceType := "DOCUMENT"
+		if testCase.resourceID == "" {
+			resourceType = ""
+		}
+
+		allowed := grantsAllow(grants, "documents.edit", resourceType, testCase.resourceID)
+
+		if allowed != testCase.expected {
+			t.Fatal(testCase.resourceID, "expected", testCase.expected, "got", allowed)
+		}
+	}
+}
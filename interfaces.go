@@ -3,6 +3,8 @@ package permissionstore
 import (
 	"context"
 	"database/sql"
+	"io"
+	"time"
 
 	"github.com/dromara/carbon/v2"
 )
@@ -11,12 +13,25 @@ type StoreInterface interface {
 	// AutoMigrate auto migrates the database schema
 	AutoMigrate() error
 
+	// MigrateTo applies the pending schema migrations up to and including the given version
+	MigrateTo(ctx context.Context, version int) error
+
+	// MigrationStatus returns the state of all schema migrations
+	MigrationStatus(ctx context.Context) ([]Migration, error)
+
 	// EnableDebug enables or disables the debug mode
 	EnableDebug(debug bool)
 
+	// CacheStats returns the statistics of the effective permission cache
+	CacheStats() CacheStats
+
 	// DB returns the underlying database connection
 	DB() *sql.DB
 
+	// WithTransaction runs fn in a database transaction, committed when fn succeeds
+	// and rolled back when it fails or panics. Nested calls use savepoints
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
 	// == Permission Methods =======================================================//
 
 	// PermissionCount returns the number of permissions based on the given query options
@@ -37,6 +52,15 @@ type StoreInterface interface {
 	// PermissionFindByID returns a permission by its ID
 	PermissionFindByID(ctx context.Context, id string) (PermissionInterface, error)
 
+	// PermissionGetByHandle returns a permission by its handle, ErrNotFound if it does not exist
+	PermissionGetByHandle(ctx context.Context, handle string) (PermissionInterface, error)
+
+	// PermissionGetByID returns a permission by its ID, ErrNotFound if it does not exist
+	PermissionGetByID(ctx context.Context, id string) (PermissionInterface, error)
+
+	// PermissionHandleTree returns the handle tree of the permissions matching the given query options
+	PermissionHandleTree(ctx context.Context, query PermissionQueryInterface) ([]*PermissionHandleNode, error)
+
 	// PermissionList returns a list of permissions based on the given query options
 	PermissionList(ctx context.Context, query PermissionQueryInterface) ([]PermissionInterface, error)
 
@@ -49,6 +73,9 @@ type StoreInterface interface {
 	// PermissionUpdate updates a permission
 	PermissionUpdate(ctx context.Context, permission PermissionInterface) error
 
+	// SyncPermissions makes the permissions match the catalog in one transaction, upserting by handle
+	SyncPermissions(ctx context.Context, definitions []PermissionDefinition, options SyncOptions) (SyncReport, error)
+
 	// == EntityPermission Methods =================================================//
 
 	// EntityPermissionCount returns the number of permission entities mappings based on the given query options
@@ -57,29 +84,282 @@ type StoreInterface interface {
 	// EntityPermissionCreate creates a new permission entity mapping
 	EntityPermissionCreate(ctx context.Context, entityPermission EntityPermissionInterface) error
 
+	// EntityPermissionCreateMany creates the entity permissions in one transaction, skipping and reporting duplicates
+	EntityPermissionCreateMany(ctx context.Context, entityPermissions []EntityPermissionInterface) ([]BulkResult, error)
+
 	// EntityPermissionDelete deletes a permission entity mapping
 	EntityPermissionDelete(ctx context.Context, entityPermission EntityPermissionInterface) error
 
 	// EntityPermissionDeleteByID deletes a permission entity mapping by its ID
 	EntityPermissionDeleteByID(ctx context.Context, id string) error
 
-	// EntityPermissionFindByEntityAndPermission returns a permission entity mapping by its entity type, entity ID and permission ID
+	// EntityPermissionFindByEntityAndPermission returns a global (not resource scoped) permission entity mapping by its entity type, entity ID and permission ID
 	EntityPermissionFindByEntityAndPermission(ctx context.Context, entityType string, entityID string, permissionID string) (EntityPermissionInterface, error)
 
+	// EntityPermissionFindByEntityPermissionAndResource returns a permission entity mapping by its entity type, entity ID, permission ID and resource.
+	// Empty resource type and resource ID find the global mapping
+	EntityPermissionFindByEntityPermissionAndResource(ctx context.Context, entityType string, entityID string, permissionID string, resourceType string, resourceID string) (EntityPermissionInterface, error)
+
 	// EntityPermissionFindByID returns a permission entity mapping by its ID
 	EntityPermissionFindByID(ctx context.Context, id string) (EntityPermissionInterface, error)
 
+	// EntityPermissionGetByID returns a permission entity mapping by its ID, ErrNotFound if it does not exist
+	EntityPermissionGetByID(ctx context.Context, id string) (EntityPermissionInterface, error)
+
 	// EntityPermissionList returns a list of permission entity mappings based on the given query options
 	EntityPermissionList(ctx context.Context, query EntityPermissionQueryInterface) ([]EntityPermissionInterface, error)
 
+	// EntityPermissionPurgeExpired permanently deletes the entity permissions which expired more than olderThan ago
+	EntityPermissionPurgeExpired(ctx context.Context, olderThan time.Duration) (int64, error)
+
 	// EntityPermissionSoftDelete soft deletes a permission entity mapping
 	EntityPermissionSoftDelete(ctx context.Context, entityPermission EntityPermissionInterface) error
 
 	// EntityPermissionSoftDeleteByID soft deletes a permission entity mapping by its ID
 	EntityPermissionSoftDeleteByID(ctx context.Context, id string) error
 
+	// EntityPermissionSync makes the global allow grants of the entity match the set of permission IDs in one transaction
+	EntityPermissionSync(ctx context.Context, entityType string, entityID string, permissionIDs []string, options EntityPermissionSyncOptions) (added []string, removed []string, err error)
+
 	// EntityPermissionUpdate updates a permission entity mapping
 	EntityPermissionUpdate(ctx context.Context, entityPermission EntityPermissionInterface) error
+
+	// GrantToEntities grants the permission to the entities in one transaction
+	GrantToEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error)
+
+	// RevokeFromEntities deletes the global allow grants of the permission to the entities in one transaction
+	RevokeFromEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error)
+
+	// == Role Methods =============================================================//
+
+	// RoleCount returns the number of roles based on the given query options
+	RoleCount(ctx context.Context, options RoleQueryInterface) (int64, error)
+
+	// RoleCreate creates a new role
+	RoleCreate(ctx context.Context, role RoleInterface) error
+
+	// RoleDelete deletes a role
+	RoleDelete(ctx context.Context, role RoleInterface) error
+
+	// RoleDeleteByID deletes a role by its ID
+	RoleDeleteByID(ctx context.Context, id string) error
+
+	// RoleFindByHandle returns a role by its handle
+	RoleFindByHandle(ctx context.Context, handle string) (RoleInterface, error)
+
+	// RoleFindByID returns a role by its ID
+	RoleFindByID(ctx context.Context, id string) (RoleInterface, error)
+
+	// RoleGetByHandle returns a role by its handle, ErrNotFound if it does not exist
+	RoleGetByHandle(ctx context.Context, handle string) (RoleInterface, error)
+
+	// RoleGetByID returns a role by its ID, ErrNotFound if it does not exist
+	RoleGetByID(ctx context.Context, id string) (RoleInterface, error)
+
+	// RoleList returns a list of roles based on the given query options
+	RoleList(ctx context.Context, query RoleQueryInterface) ([]RoleInterface, error)
+
+	// RoleSoftDelete soft deletes a role
+	RoleSoftDelete(ctx context.Context, role RoleInterface) error
+
+	// RoleSoftDeleteByID soft deletes a role by its ID
+	RoleSoftDeleteByID(ctx context.Context, id string) error
+
+	// RoleUpdate updates a role
+	RoleUpdate(ctx context.Context, role RoleInterface) error
+
+	// == RolePermission Methods ===================================================//
+
+	// RolePermissionCount returns the number of role permission mappings based on the given query options
+	RolePermissionCount(ctx context.Context, options RolePermissionQueryInterface) (int64, error)
+
+	// RolePermissionCreate creates a new role permission mapping
+	RolePermissionCreate(ctx context.Context, rolePermission RolePermissionInterface) error
+
+	// RolePermissionDelete deletes a role permission mapping
+	RolePermissionDelete(ctx context.Context, rolePermission RolePermissionInterface) error
+
+	// RolePermissionDeleteByID deletes a role permission mapping by its ID
+	RolePermissionDeleteByID(ctx context.Context, id string) error
+
+	// RolePermissionFindByRoleAndPermission returns a role permission mapping by its role ID and permission ID
+	RolePermissionFindByRoleAndPermission(ctx context.Context, roleID string, permissionID string) (RolePermissionInterface, error)
+
+	// RolePermissionFindByID returns a role permission mapping by its ID
+	RolePermissionFindByID(ctx context.Context, id string) (RolePermissionInterface, error)
+
+	// RolePermissionGetByID returns a role permission mapping by its ID, ErrNotFound if it does not exist
+	RolePermissionGetByID(ctx context.Context, id string) (RolePermissionInterface, error)
+
+	// RolePermissionList returns a list of role permission mappings based on the given query options
+	RolePermissionList(ctx context.Context, query RolePermissionQueryInterface) ([]RolePermissionInterface, error)
+
+	// RolePermissionSoftDelete soft deletes a role permission mapping
+	RolePermissionSoftDelete(ctx context.Context, rolePermission RolePermissionInterface) error
+
+	// RolePermissionSoftDeleteByID soft deletes a role permission mapping by its ID
+	RolePermissionSoftDeleteByID(ctx context.Context, id string) error
+
+	// RolePermissionUpdate updates a role permission mapping
+	RolePermissionUpdate(ctx context.Context, rolePermission RolePermissionInterface) error
+
+	// == EntityRole Methods =======================================================//
+
+	// EntityRoleCount returns the number of entity role mappings based on the given query options
+	EntityRoleCount(ctx context.Context, options EntityRoleQueryInterface) (int64, error)
+
+	// EntityRoleCreate creates a new entity role mapping
+	EntityRoleCreate(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// EntityRoleDelete deletes an entity role mapping
+	EntityRoleDelete(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// EntityRoleDeleteByID deletes an entity role mapping by its ID
+	EntityRoleDeleteByID(ctx context.Context, id string) error
+
+	// EntityRoleFindByEntityAndRole returns an entity role mapping by its entity type, entity ID and role ID
+	EntityRoleFindByEntityAndRole(ctx context.Context, entityType string, entityID string, roleID string) (EntityRoleInterface, error)
+
+	// EntityRoleFindByID returns an entity role mapping by its ID
+	EntityRoleFindByID(ctx context.Context, id string) (EntityRoleInterface, error)
+
+	// EntityRoleGetByID returns an entity role mapping by its ID, ErrNotFound if it does not exist
+	EntityRoleGetByID(ctx context.Context, id string) (EntityRoleInterface, error)
+
+	// EntityRoleList returns a list of entity role mappings based on the given query options
+	EntityRoleList(ctx context.Context, query EntityRoleQueryInterface) ([]EntityRoleInterface, error)
+
+	// EntityRoleSoftDelete soft deletes an entity role mapping
+	EntityRoleSoftDelete(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// EntityRoleSoftDeleteByID soft deletes an entity role mapping by its ID
+	EntityRoleSoftDeleteByID(ctx context.Context, id string) error
+
+	// EntityRoleUpdate updates an entity role mapping
+	EntityRoleUpdate(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// == EntityMembership Methods =================================================//
+
+	// EntityAncestors returns the entities the entity is a member of, directly or transitively via other memberships
+	EntityAncestors(ctx context.Context, entityType string, entityID string) ([]EntityRef, error)
+
+	// EntityMembershipCount returns the number of entity memberships based on the given query options
+	EntityMembershipCount(ctx context.Context, options EntityMembershipQueryInterface) (int64, error)
+
+	// EntityMembershipCreate creates a new entity membership
+	EntityMembershipCreate(ctx context.Context, entityMembership EntityMembershipInterface) error
+
+	// EntityMembershipDelete deletes an entity membership
+	EntityMembershipDelete(ctx context.Context, entityMembership EntityMembershipInterface) error
+
+	// EntityMembershipDeleteByID deletes an entity membership by its ID
+	EntityMembershipDeleteByID(ctx context.Context, id string) error
+
+	// EntityMembershipFindByMemberAndParent returns an entity membership by its member entity and parent entity
+	EntityMembershipFindByMemberAndParent(ctx context.Context, memberType string, memberID string, parentType string, parentID string) (EntityMembershipInterface, error)
+
+	// EntityMembershipFindByID returns an entity membership by its ID
+	EntityMembershipFindByID(ctx context.Context, id string) (EntityMembershipInterface, error)
+
+	// EntityMembershipGetByID returns an entity membership by its ID, ErrNotFound if it does not exist
+	EntityMembershipGetByID(ctx context.Context, id string) (EntityMembershipInterface, error)
+
+	// EntityMembershipList returns a list of entity memberships based on the given query options
+	EntityMembershipList(ctx context.Context, query EntityMembershipQueryInterface) ([]EntityMembershipInterface, error)
+
+	// EntityMembershipSoftDelete soft deletes an entity membership
+	EntityMembershipSoftDelete(ctx context.Context, entityMembership EntityMembershipInterface) error
+
+	// EntityMembershipSoftDeleteByID soft deletes an entity membership by its ID
+	EntityMembershipSoftDeleteByID(ctx context.Context, id string) error
+
+	// EntityMembershipUpdate updates an entity membership
+	EntityMembershipUpdate(ctx context.Context, entityMembership EntityMembershipInterface) error
+
+	// == Check Methods ============================================================//
+
+	// EntityHasPermission checks whether the entity is granted the permission with the given handle
+	EntityHasPermission(ctx context.Context, entityType string, entityID string, handle string) (bool, error)
+
+	// EntityHasAnyPermission checks whether the entity is granted at least one of the permissions with the given handles
+	EntityHasAnyPermission(ctx context.Context, entityType string, entityID string, handles []string) (bool, error)
+
+	// EntityHasAllPermissions checks whether the entity is granted all of the permissions with the given handles
+	EntityHasAllPermissions(ctx context.Context, entityType string, entityID string, handles []string) (bool, error)
+
+	// EntityHasPermissionOnResource checks whether the entity is granted the permission with the given handle on the given resource
+	EntityHasPermissionOnResource(ctx context.Context, entityType string, entityID string, handle string, resourceType string, resourceID string) (bool, error)
+
+	// CheckBatch evaluates many checks at once with a bounded number of queries, returning the results in the order of the requests
+	CheckBatch(ctx context.Context, requests []CheckRequest) ([]CheckResult, error)
+
+	// EntityPermissionHandles returns the sorted handles of the active permissions, which are granted to the entity
+	EntityPermissionHandles(ctx context.Context, entityType string, entityID string) ([]string, error)
+
+	// EntityEffectivePermissions returns the active permissions with the handles returned by EntityPermissionHandles
+	EntityEffectivePermissions(ctx context.Context, entityType string, entityID string) ([]PermissionInterface, error)
+
+	// EntitiesWithPermission returns the distinct entities, which are granted the permission with the given handle, ordered by entity type and entity ID
+	EntitiesWithPermission(ctx context.Context, handle string, query EntitiesQuery) ([]EntityRef, error)
+
+	// EntitiesWithPermissionCount returns the number of distinct entities, which are granted the permission with the given handle
+	EntitiesWithPermissionCount(ctx context.Context, handle string, query EntitiesQuery) (int64, error)
+
+	// EntityPermissionExplain returns the decision trace of EntityHasPermission, the grants matched or skipped and the verdict
+	EntityPermissionExplain(ctx context.Context, entityType string, entityID string, handle string) (Explanation, error)
+
+	// == Policy Methods ===========================================================//
+
+	// Export writes the permissions and the entity permission grants as a versioned JSON or YAML policy document
+	Export(ctx context.Context, w io.Writer, options ExportOptions) error
+
+	// Import applies a policy document written by Export in one transaction, matching permissions by handle
+	Import(ctx context.Context, r io.Reader, options ImportOptions) (ImportReport, error)
+
+	// == Audit Methods ============================================================//
+
+	// AuditCount returns the number of audit records matching the query
+	AuditCount(ctx context.Context, query AuditQueryInterface) (int64, error)
+
+	// AuditList returns the audit records matching the query
+	AuditList(ctx context.Context, query AuditQueryInterface) ([]AuditRecordInterface, error)
+}
+
+type AuditRecordInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// setters and getters
+
+	Actor() string
+	SetActor(actor string) AuditRecordInterface
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) AuditRecordInterface
+
+	DataAfter() string
+	DataAfterMap() (map[string]string, error)
+	SetDataAfter(dataAfter string) AuditRecordInterface
+
+	DataBefore() string
+	DataBeforeMap() (map[string]string, error)
+	SetDataBefore(dataBefore string) AuditRecordInterface
+
+	ID() string
+	SetID(id string) AuditRecordInterface
+
+	Operation() string
+	SetOperation(operation string) AuditRecordInterface
+
+	RowID() string
+	SetRowID(rowID string) AuditRecordInterface
+
+	TableName() string
+	SetTableName(tableName string) AuditRecordInterface
 }
 
 type PermissionInterface interface {
@@ -139,7 +419,13 @@ type EntityPermissionInterface interface {
 
 	// methods
 
+	IsAllow() bool
+	IsDeny() bool
+	IsExpired() bool
+	IsPending() bool
+	IsResourceScoped() bool
 	IsSoftDeleted() bool
+	IsValid() bool
 
 	// setters and getters
 
@@ -147,12 +433,19 @@ type EntityPermissionInterface interface {
 	CreatedAtCarbon() carbon.Carbon
 	SetCreatedAt(createdAt string) EntityPermissionInterface
 
+	Effect() string
+	SetEffect(effect string) EntityPermissionInterface
+
 	EntityType() string
 	SetEntityType(entityType string) EntityPermissionInterface
 
 	EntityID() string
 	SetEntityID(entityID string) EntityPermissionInterface
 
+	ExpiresAt() string
+	ExpiresAtCarbon() carbon.Carbon
+	SetExpiresAt(expiresAt string) EntityPermissionInterface
+
 	ID() string
 	SetID(id string) EntityPermissionInterface
 
@@ -167,6 +460,12 @@ type EntityPermissionInterface interface {
 	PermissionID() string
 	SetPermissionID(permissionID string) EntityPermissionInterface
 
+	ResourceID() string
+	SetResourceID(resourceID string) EntityPermissionInterface
+
+	ResourceType() string
+	SetResourceType(resourceType string) EntityPermissionInterface
+
 	SoftDeletedAt() string
 	SoftDeletedAtCarbon() carbon.Carbon
 	SetSoftDeletedAt(softDeletedAt string) EntityPermissionInterface
@@ -174,6 +473,196 @@ type EntityPermissionInterface interface {
 	UpdatedAt() string
 	UpdatedAtCarbon() carbon.Carbon
 	SetUpdatedAt(updatedAt string) EntityPermissionInterface
+
+	ValidFrom() string
+	ValidFromCarbon() carbon.Carbon
+	SetValidFrom(validFrom string) EntityPermissionInterface
+}
+
+type RoleInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsActive() bool
+	IsInactive() bool
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) RoleInterface
+
+	Handle() string
+	SetHandle(handle string) RoleInterface
+
+	ID() string
+	SetID(id string) RoleInterface
+
+	Memo() string
+	SetMemo(memo string) RoleInterface
+
+	Meta(name string) string
+	SetMeta(name string, value string) error
+	Metas() (map[string]string, error)
+	SetMetas(metas map[string]string) error
+
+	Status() string
+	SetStatus(status string) RoleInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) RoleInterface
+
+	Title() string
+	SetTitle(title string) RoleInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) RoleInterface
+}
+
+type RolePermissionInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) RolePermissionInterface
+
+	ID() string
+	SetID(id string) RolePermissionInterface
+
+	Memo() string
+	SetMemo(memo string) RolePermissionInterface
+
+	Meta(name string) string
+	SetMeta(name string, value string) error
+	Metas() (map[string]string, error)
+	SetMetas(metas map[string]string) error
+
+	PermissionID() string
+	SetPermissionID(permissionID string) RolePermissionInterface
+
+	RoleID() string
+	SetRoleID(roleID string) RolePermissionInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) RolePermissionInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) RolePermissionInterface
+}
+
+type EntityRoleInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) EntityRoleInterface
+
+	EntityType() string
+	SetEntityType(entityType string) EntityRoleInterface
+
+	EntityID() string
+	SetEntityID(entityID string) EntityRoleInterface
+
+	ID() string
+	SetID(id string) EntityRoleInterface
+
+	Memo() string
+	SetMemo(memo string) EntityRoleInterface
+
+	Meta(name string) string
+	SetMeta(name string, value string) error
+	Metas() (map[string]string, error)
+	SetMetas(metas map[string]string) error
+
+	RoleID() string
+	SetRoleID(roleID string) EntityRoleInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) EntityRoleInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) EntityRoleInterface
+}
+
+type EntityMembershipInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) EntityMembershipInterface
+
+	ID() string
+	SetID(id string) EntityMembershipInterface
+
+	MemberType() string
+	SetMemberType(memberType string) EntityMembershipInterface
+
+	MemberID() string
+	SetMemberID(memberID string) EntityMembershipInterface
+
+	Memo() string
+	SetMemo(memo string) EntityMembershipInterface
+
+	Meta(name string) string
+	SetMeta(name string, value string) error
+	Metas() (map[string]string, error)
+	SetMetas(metas map[string]string) error
+
+	ParentType() string
+	SetParentType(parentType string) EntityMembershipInterface
+
+	ParentID() string
+	SetParentID(parentID string) EntityMembershipInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) EntityMembershipInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) EntityMembershipInterface
 }
 
 type UserInterface interface {
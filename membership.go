@@ -0,0 +1,93 @@
+package permissionstore
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+)
+
+// membershipLoader returns the entities one membership away from the entities, via
+// the memberships which are not soft deleted, in any order. Walking to the ancestors
+// it returns the parents of the entities, walking to the descendants their members
+type membershipLoader func(ctx context.Context, entities []EntityRef) ([]EntityRef, error)
+
+// entityAncestors walks the memberships breadth first from the entity to its ancestors,
+// following at most maxDepth memberships, one load per level, see membershipWalk
+func entityAncestors(ctx context.Context, load membershipLoader, entity EntityRef, maxDepth int) ([]EntityRef, error) {
+	return membershipWalk(ctx, load, []EntityRef{entity}, maxDepth)
+}
+
+// membershipWalk walks the memberships breadth first from the entities,
+// following at most maxDepth memberships, one load per level.
+//
+// Every entity is visited once, so a cycle of memberships ends the walk instead of
+// looping, and the entities themselves are never reached. The entities reached are
+// ordered by depth, then by entity type and entity ID.
+func membershipWalk(ctx context.Context, load membershipLoader, entities []EntityRef, maxDepth int) ([]EntityRef, error) {
+	visited := map[EntityRef]bool{}
+
+	for _, entity := range entities {
+		visited[entity] = true
+	}
+
+	reached := []EntityRef{}
+	level := entities
+
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		loaded, err := load(ctx, level)
+
+		if err != nil {
+			return nil, err
+		}
+
+		next := []EntityRef{}
+
+		for _, entity := range loaded {
+			if visited[entity] {
+				continue
+			}
+
+			visited[entity] = true
+			next = append(next, entity)
+		}
+
+		slices.SortFunc(next, entityRefCompare)
+
+		reached = append(reached, next...)
+		level = next
+	}
+
+	return reached, nil
+}
+
+// entityRefCompare orders the entities by entity type, then by entity ID
+func entityRefCompare(a EntityRef, b EntityRef) int {
+	return cmp.Or(cmp.Compare(a.EntityType, b.EntityType), cmp.Compare(a.EntityID, b.EntityID))
+}
+
+// entityMembershipValidate returns an error if the member or the parent of the
+// membership is not set, or if the entity would be a member of itself
+func entityMembershipValidate(entityMembership EntityMembershipInterface) error {
+	if entityMembership.MemberType() == "" {
+		return errors.New("entityMembership memberType is empty")
+	}
+
+	if entityMembership.MemberID() == "" {
+		return errors.New("entityMembership memberID is empty")
+	}
+
+	if entityMembership.ParentType() == "" {
+		return errors.New("entityMembership parentType is empty")
+	}
+
+	if entityMembership.ParentID() == "" {
+		return errors.New("entityMembership parentID is empty")
+	}
+
+	if entityMembership.MemberType() == entityMembership.ParentType() && entityMembership.MemberID() == entityMembership.ParentID() {
+		return errors.New("entityMembership member and parent are the same entity")
+	}
+
+	return nil
+}
@@ -0,0 +1,597 @@
+package permissionstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// memory table names
+const (
+	memoryTablePermission       = "permission"
+	memoryTableEntityPermission = "entity_permission"
+	memoryTableRole             = "role"
+	memoryTableRolePermission   = "role_permission"
+	memoryTableEntityRole       = "entity_role"
+	memoryTableEntityMembership = "entity_membership"
+)
+
+// errMemoryUniqueViolation is the unique constraint violation of a memory table,
+// the counterpart of the unique index violations reported by the database drivers
+var errMemoryUniqueViolation = errors.New("permissionstore: unique constraint failed")
+
+// == TYPE ====================================================================
+
+// memoryStore is a StoreInterface keeping the tables in memory, see NewMemoryStore
+type memoryStore struct {
+	// mu guards the tables
+	mu sync.RWMutex
+
+	// txMu serializes the transactions started by WithTransaction
+	txMu sync.Mutex
+
+	// tables are the memory tables keyed by name
+	tables map[string]*memoryTable
+
+	// membershipMaxDepth is the maximum number of memberships followed from an entity to its ancestors
+	membershipMaxDepth int
+}
+
+// memoryTable is a table of the memory store
+type memoryTable struct {
+	// columns are the columns of the table
+	columns []string
+
+	// unique are the columns of the unique index, nil if there is none.
+	// As in the database, the soft deleted rows are left out of the index
+	unique []string
+
+	// rows are the rows of the table in insertion order
+	rows []map[string]string
+}
+
+// == INTERFACE ===============================================================
+
+var _ StoreInterface = (*memoryStore)(nil) // verify it extends the interface
+
+// == CONSTRUCTOR =============================================================
+
+// NewMemoryStoreOptions define the options for creating a new memory store
+type NewMemoryStoreOptions struct {
+	// MembershipMaxDepth is the maximum number of memberships followed from an entity
+	// to its ancestors, optional. Defaults to MEMBERSHIP_MAX_DEPTH_DEFAULT
+	MembershipMaxDepth int
+}
+
+// NewMemoryStore creates a new store keeping the permissions, the roles and
+// their grants in memory, i.e. for tests, which do not need a database.
+//
+// The memory store honours the same query options and duplicate rules as the
+// database store, roles and memberships are always enabled. It does not keep an audit log and
+// has no schema to migrate. Transactions are rolled back by reverting their
+// own changes, so these are not isolated from changes made outside of them.
+func NewMemoryStore(opts NewMemoryStoreOptions) (StoreInterface, error) {
+	if opts.MembershipMaxDepth < 0 {
+		return nil, errors.New("permission store: MembershipMaxDepth " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	if opts.MembershipMaxDepth == 0 {
+		opts.MembershipMaxDepth = MEMBERSHIP_MAX_DEPTH_DEFAULT
+	}
+
+	store := &memoryStore{
+		tables: map[string]*memoryTable{
+			memoryTablePermission: {
+				columns: []string{COLUMN_ID, COLUMN_STATUS, COLUMN_HANDLE, COLUMN_TITLE, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+				unique:  []string{COLUMN_HANDLE},
+			},
+			memoryTableEntityPermission: {
+				columns: []string{COLUMN_ID, COLUMN_ENTITY_TYPE, COLUMN_ENTITY_ID, COLUMN_PERMISSION_ID, COLUMN_EFFECT, COLUMN_RESOURCE_TYPE, COLUMN_RESOURCE_ID, COLUMN_VALID_FROM, COLUMN_EXPIRES_AT, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+				unique:  []string{COLUMN_ENTITY_TYPE, COLUMN_ENTITY_ID, COLUMN_PERMISSION_ID, COLUMN_RESOURCE_TYPE, COLUMN_RESOURCE_ID},
+			},
+			memoryTableRole: {
+				columns: []string{COLUMN_ID, COLUMN_STATUS, COLUMN_HANDLE, COLUMN_TITLE, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+			},
+			memoryTableRolePermission: {
+				columns: []string{COLUMN_ID, COLUMN_ROLE_ID, COLUMN_PERMISSION_ID, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+			},
+			memoryTableEntityRole: {
+				columns: []string{COLUMN_ID, COLUMN_ENTITY_TYPE, COLUMN_ENTITY_ID, COLUMN_ROLE_ID, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+			},
+			memoryTableEntityMembership: {
+				columns: []string{COLUMN_ID, COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID, COLUMN_METAS, COLUMN_MEMO, COLUMN_CREATED_AT, COLUMN_UPDATED_AT, COLUMN_SOFT_DELETED_AT},
+			},
+		},
+		membershipMaxDepth: opts.MembershipMaxDepth,
+	}
+
+	return store, nil
+}
+
+// PUBLIC METHODS ============================================================
+
+// AutoMigrate does nothing, the memory store has no schema
+func (store *memoryStore) AutoMigrate() error {
+	return nil
+}
+
+// MigrateTo does nothing, the memory store has no schema
+func (store *memoryStore) MigrateTo(ctx context.Context, version int) error {
+	return nil
+}
+
+// MigrationStatus returns no migrations, the memory store has no schema
+func (store *memoryStore) MigrationStatus(ctx context.Context) ([]Migration, error) {
+	return []Migration{}, nil
+}
+
+// EnableDebug does nothing, the memory store does not log
+func (store *memoryStore) EnableDebug(debug bool) {}
+
+// CacheStats returns zero statistics, the memory store has no cache
+func (store *memoryStore) CacheStats() CacheStats {
+	return CacheStats{}
+}
+
+// DB returns nil, the memory store has no database
+func (store *memoryStore) DB() *sql.DB {
+	return nil
+}
+
+// WithTransaction runs fn in a transaction. The changes made by fn are
+// reverted, when fn returns an error or panics.
+//
+// Nested calls revert only the changes of their own fn, like savepoints.
+// Transactions are serialized, but not isolated from changes made outside
+// of them in the meantime, which are kept when the transaction is rolled back.
+func (store *memoryStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if fn == nil {
+		return errors.New("permissionstore > WithTransaction. fn is nil")
+	}
+
+	if transactionScopeFromContext(ctx) == nil {
+		store.txMu.Lock()
+		defer store.txMu.Unlock()
+	}
+
+	depth := 0
+
+	if outer := transactionScopeFromContext(ctx); outer != nil {
+		depth = outer.depth + 1
+	}
+
+	undo := &memoryUndoLog{}
+
+	defer func() {
+		if r := recover(); r != nil {
+			store.rollback(undo)
+			panic(r)
+		}
+	}()
+
+	txCtx := context.WithValue(ctx, transactionContextKey{}, &transactionScope{depth: depth})
+	txCtx = context.WithValue(txCtx, memoryUndoContextKey{}, undo)
+
+	if err := fn(txCtx); err != nil {
+		store.rollback(undo)
+		return err
+	}
+
+	// the changes of a nested transaction are reverted with the outer one
+	if outer := memoryUndoLogFromContext(ctx); outer != nil {
+		outer.record(undo.changes...)
+	}
+
+	return nil
+}
+
+// == Permission Methods ======================================================//
+
+// SyncPermissions makes the permissions match the catalog in one transaction, see the database store
+func (store *memoryStore) SyncPermissions(ctx context.Context, definitions []PermissionDefinition, options SyncOptions) (SyncReport, error) {
+	return syncPermissions(ctx, store, definitions, options)
+}
+
+// == EntityPermission Methods ================================================//
+
+// EntityPermissionSync makes the global allow grants of the entity match the set of permission IDs, see the database store
+func (store *memoryStore) EntityPermissionSync(ctx context.Context, entityType string, entityID string, permissionIDs []string, options EntityPermissionSyncOptions) (added []string, removed []string, err error) {
+	return entityPermissionSync(ctx, store, entityType, entityID, permissionIDs, options)
+}
+
+// GrantToEntities grants the permission to the entities in one transaction, see the database store
+func (store *memoryStore) GrantToEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error) {
+	return grantToEntities(ctx, store, permissionID, entities)
+}
+
+// == Policy Methods ==========================================================//
+
+// Export writes the permissions and the entity permission grants as a policy document, see the database store
+func (store *memoryStore) Export(ctx context.Context, w io.Writer, options ExportOptions) error {
+	return policyExport(ctx, store, w, options)
+}
+
+// Import applies a policy document written by Export in one transaction, see the database store
+func (store *memoryStore) Import(ctx context.Context, r io.Reader, options ImportOptions) (ImportReport, error) {
+	return policyImport(ctx, store, r, options)
+}
+
+// == Audit Methods ===========================================================//
+
+// AuditCount returns an error, the memory store does not keep an audit log
+func (store *memoryStore) AuditCount(ctx context.Context, options AuditQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("at audit count > audit query is nil")
+	}
+
+	if err := options.Validate(); err != nil {
+		return -1, newInvalidQueryError("AuditQuery", err)
+	}
+
+	return -1, errors.New("permissionstore: audit is not enabled")
+}
+
+// AuditList returns an error, the memory store does not keep an audit log
+func (store *memoryStore) AuditList(ctx context.Context, query AuditQueryInterface) ([]AuditRecordInterface, error) {
+	if query == nil {
+		return []AuditRecordInterface{}, errors.New("at audit list > audit query is nil")
+	}
+
+	if err := query.Validate(); err != nil {
+		return []AuditRecordInterface{}, newInvalidQueryError("AuditQuery", err)
+	}
+
+	return []AuditRecordInterface{}, errors.New("permissionstore: audit is not enabled")
+}
+
+// PRIVATE METHODS ===========================================================
+
+// memoryQueryOptions are the query options shared by the query interfaces
+type memoryQueryOptions interface {
+	Columns() []string
+	IsCountOnly() bool
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+
+	HasID() bool
+	ID() string
+
+	HasIDIn() bool
+	IDIn() []string
+
+	HasLimit() bool
+	Limit() int
+
+	HasOffset() bool
+	Offset() int
+
+	HasOrderBy() bool
+	OrderBy() string
+
+	HasSortDirection() bool
+	SortDirection() string
+
+	SoftDeletedIncluded() bool
+}
+
+// memoryCondition is a condition of the WHERE clause of a memory select
+type memoryCondition func(row map[string]string) bool
+
+// memoryEq returns the condition "column = value"
+func memoryEq(column string, value string) memoryCondition {
+	return func(row map[string]string) bool {
+		return row[column] == value
+	}
+}
+
+// memoryIn returns the condition "column IN (values)"
+func memoryIn(column string, values []string) memoryCondition {
+	return func(row map[string]string) bool {
+		return lo.Contains(values, row[column])
+	}
+}
+
+// memoryGt returns the condition "column > value"
+func memoryGt(column string, value string) memoryCondition {
+	return func(row map[string]string) bool {
+		return row[column] > value
+	}
+}
+
+// memoryLte returns the condition "column <= value"
+func memoryLte(column string, value string) memoryCondition {
+	return func(row map[string]string) bool {
+		return row[column] <= value
+	}
+}
+
+// memoryILike returns the condition "column ILIKE pattern", where
+// "%" matches any sequence of characters and "_" any single character
+func memoryILike(column string, pattern string) memoryCondition {
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.ReplaceAll(expression, "%", ".*")
+	expression = strings.ReplaceAll(expression, "_", ".")
+
+	re := regexp.MustCompile("(?is)^" + expression + "$")
+
+	return func(row map[string]string) bool {
+		return re.MatchString(row[column])
+	}
+}
+
+// memoryNotSoftDeleted returns the condition selecting the rows, which are not soft deleted
+func memoryNotSoftDeleted() memoryCondition {
+	return memoryGt(COLUMN_SOFT_DELETED_AT, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+}
+
+// selectRows returns copies of the rows of the table matching the conditions and
+// the query options shared by all queries, the same way the database store does
+func (store *memoryStore) selectRows(tableName string, options memoryQueryOptions, conditions ...memoryCondition) ([]map[string]string, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	table := store.tables[tableName]
+
+	columns := slices.Clone(options.Columns())
+
+	if options.HasOrderBy() {
+		columns = append(columns, options.OrderBy())
+	}
+
+	for _, column := range columns {
+		if !lo.Contains(table.columns, column) {
+			return nil, errors.New("permissionstore: no such column: " + column)
+		}
+	}
+
+	if options.HasID() {
+		conditions = append(conditions, memoryEq(COLUMN_ID, options.ID()))
+	}
+
+	if options.HasIDIn() {
+		conditions = append(conditions, memoryIn(COLUMN_ID, options.IDIn()))
+	}
+
+	if options.HasCreatedAtGte() {
+		conditions = append(conditions, func(row map[string]string) bool {
+			return row[COLUMN_CREATED_AT] >= options.CreatedAtGte()
+		})
+	}
+
+	if options.HasCreatedAtLte() {
+		conditions = append(conditions, memoryLte(COLUMN_CREATED_AT, options.CreatedAtLte()))
+	}
+
+	if !options.SoftDeletedIncluded() {
+		conditions = append(conditions, memoryNotSoftDeleted())
+	}
+
+	rows := lo.Filter(table.rows, func(row map[string]string, _ int) bool {
+		return lo.EveryBy(conditions, func(condition memoryCondition) bool {
+			return condition(row)
+		})
+	})
+
+	if options.HasOrderBy() {
+		column := options.OrderBy()
+		ascending := strings.EqualFold(lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC), sb.ASC)
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			if ascending {
+				return rows[i][column] < rows[j][column]
+			}
+
+			return rows[i][column] > rows[j][column]
+		})
+	}
+
+	if !options.IsCountOnly() {
+		if options.HasOffset() {
+			rows = rows[min(options.Offset(), len(rows)):]
+		}
+
+		if options.HasLimit() {
+			rows = rows[:min(options.Limit(), len(rows))]
+		}
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) map[string]string {
+		if len(options.Columns()) > 0 {
+			return lo.PickByKeys(row, options.Columns())
+		}
+
+		return maps.Clone(row)
+	}), nil
+}
+
+// insertRow appends a copy of the row to the table. Like the database schema,
+// every column is required. Returns errMemoryUniqueViolation if the row
+// violates the primary key or the unique index
+func (store *memoryStore) insertRow(ctx context.Context, tableName string, row map[string]string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	table := store.tables[tableName]
+
+	for _, column := range table.columns {
+		if _, exists := row[column]; !exists {
+			return errors.New("permissionstore: column cannot be null: " + column)
+		}
+	}
+
+	if table.violatesUnique(row, "") {
+		return errMemoryUniqueViolation
+	}
+
+	table.rows = append(table.rows, maps.Clone(row))
+
+	if log := memoryUndoLogFromContext(ctx); log != nil {
+		log.record(memoryChange{tableName: tableName, id: row[COLUMN_ID]})
+	}
+
+	return nil
+}
+
+// updateRow sets the changed columns of the row with the given ID, nothing happens if there is no such row.
+// Returns errMemoryUniqueViolation if the updated row violates the unique index
+func (store *memoryStore) updateRow(ctx context.Context, tableName string, id string, dataChanged map[string]string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	table := store.tables[tableName]
+
+	index := lo.IndexOf(lo.Map(table.rows, func(row map[string]string, _ int) string {
+		return row[COLUMN_ID]
+	}), id)
+
+	if index < 0 {
+		return nil
+	}
+
+	updated := maps.Clone(table.rows[index])
+	maps.Copy(updated, dataChanged)
+
+	if table.violatesUnique(updated, id) {
+		return errMemoryUniqueViolation
+	}
+
+	if log := memoryUndoLogFromContext(ctx); log != nil {
+		log.record(memoryChange{tableName: tableName, id: id, before: table.rows[index]})
+	}
+
+	table.rows[index] = updated
+
+	return nil
+}
+
+// deleteRows deletes the rows of the table matching the condition and returns these
+func (store *memoryStore) deleteRows(ctx context.Context, tableName string, condition memoryCondition) []map[string]string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	table := store.tables[tableName]
+
+	deleted, kept := lo.FilterReject(table.rows, func(row map[string]string, _ int) bool {
+		return condition(row)
+	})
+
+	if log := memoryUndoLogFromContext(ctx); log != nil {
+		for position, row := range deleted {
+			// the index of the row, once the rows deleted before it are restored
+			index := slices.IndexFunc(table.rows, func(existing map[string]string) bool {
+				return existing[COLUMN_ID] == row[COLUMN_ID]
+			})
+
+			log.record(memoryChange{tableName: tableName, id: row[COLUMN_ID], before: row, index: index - position})
+		}
+	}
+
+	table.rows = kept
+
+	return deleted
+}
+
+// violatesUnique returns true if the row has the ID of another row, or the
+// values of the unique index of another row, which is not soft deleted.
+// The row with the ID skipID, i.e. the updated row, is not compared
+func (table *memoryTable) violatesUnique(row map[string]string, skipID string) bool {
+	return lo.SomeBy(table.rows, func(existing map[string]string) bool {
+		if existing[COLUMN_ID] == skipID {
+			return false
+		}
+
+		if existing[COLUMN_ID] == row[COLUMN_ID] {
+			return true
+		}
+
+		if table.unique == nil || existing[COLUMN_SOFT_DELETED_AT] != sb.MAX_DATETIME || row[COLUMN_SOFT_DELETED_AT] != sb.MAX_DATETIME {
+			return false
+		}
+
+		return lo.EveryBy(table.unique, func(column string) bool {
+			return existing[column] == row[column]
+		})
+	})
+}
+
+// memoryUndoContextKey is the context key of the undo log of a transaction of the memory store
+type memoryUndoContextKey struct{}
+
+// memoryUndoLog records the row changes made in a transaction, in order
+type memoryUndoLog struct {
+	mu      sync.Mutex
+	changes []memoryChange
+}
+
+// memoryChange is a change of a single row. Before is nil for an inserted row,
+// index is the position of a deleted row in its table
+type memoryChange struct {
+	tableName string
+	id        string
+	before    map[string]string
+	index     int
+}
+
+// record appends the changes to the log
+func (log *memoryUndoLog) record(changes ...memoryChange) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	log.changes = append(log.changes, changes...)
+}
+
+// memoryUndoLogFromContext returns the undo log of the transaction, nil outside of transactions
+func memoryUndoLogFromContext(ctx context.Context) *memoryUndoLog {
+	if ctx == nil {
+		return nil
+	}
+
+	log, _ := ctx.Value(memoryUndoContextKey{}).(*memoryUndoLog)
+
+	return log
+}
+
+// rollback reverts the changes of the undo log in reverse order, leaving the
+// rows changed outside of the transaction as they are
+func (store *memoryStore) rollback(log *memoryUndoLog) {
+	log.mu.Lock()
+	changes := log.changes
+	log.changes = nil
+	log.mu.Unlock()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		table := store.tables[change.tableName]
+
+		index := slices.IndexFunc(table.rows, func(row map[string]string) bool {
+			return row[COLUMN_ID] == change.id
+		})
+
+		switch {
+		case change.before == nil && index >= 0:
+			table.rows = slices.Delete(table.rows, index, index+1)
+		case change.before != nil && index >= 0:
+			table.rows[index] = change.before
+		case change.before != nil:
+			table.rows = slices.Insert(table.rows, min(change.index, len(table.rows)), change.before)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package permissionstore
+
+import (
+	"context"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// EntityHasPermission checks whether the entity is granted the permission with the given handle, see the database store
+func (store *memoryStore) EntityHasPermission(ctx context.Context, entityType string, entityID string, handle string) (bool, error) {
+	return entityHasPermission(ctx, store.entityGrants, entityType, entityID, handle)
+}
+
+// EntityHasAnyPermission checks whether the entity is granted at least one of the permissions with the given handles
+func (store *memoryStore) EntityHasAnyPermission(ctx context.Context, entityType string, entityID string, handles []string) (bool, error) {
+	return entityHasAnyPermission(ctx, store.entityGrants, entityType, entityID, handles)
+}
+
+// EntityHasAllPermissions checks whether the entity is granted every one of the permissions with the given handles
+func (store *memoryStore) EntityHasAllPermissions(ctx context.Context, entityType string, entityID string, handles []string) (bool, error) {
+	return entityHasAllPermissions(ctx, store.entityGrants, entityType, entityID, handles)
+}
+
+// EntityHasPermissionOnResource checks whether the entity is granted the permission with the given handle on the given resource
+func (store *memoryStore) EntityHasPermissionOnResource(ctx context.Context, entityType string, entityID string, handle string, resourceType string, resourceID string) (bool, error) {
+	return entityHasPermissionOnResource(ctx, store.entityGrants, entityType, entityID, handle, resourceType, resourceID)
+}
+
+// CheckBatch evaluates many checks at once, returning the results in the order of the requests, see the database store
+func (store *memoryStore) CheckBatch(ctx context.Context, requests []CheckRequest) ([]CheckResult, error) {
+	return checkBatch(ctx, store.entityGrantsBatch, requests)
+}
+
+// entityGrantsBatch returns all grants of each of the entities, see batchGrantLoader.
+// Filtering the grants by handle is left to the evaluation
+func (store *memoryStore) entityGrantsBatch(ctx context.Context, entities []EntityRef, handles []string) (map[EntityRef][]grant, error) {
+	grants := map[EntityRef][]grant{}
+
+	for _, entity := range entities {
+		entityGrants, err := store.entityGrants(ctx, entity.EntityType, entity.EntityID, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		grants[entity] = entityGrants
+	}
+
+	return grants, nil
+}
+
+// EntityPermissionHandles returns the sorted handles of the active permissions, which are granted to the entity, see the database store
+func (store *memoryStore) EntityPermissionHandles(ctx context.Context, entityType string, entityID string) ([]string, error) {
+	return entityPermissionHandles(ctx, store.entityGrants, entityType, entityID)
+}
+
+// EntityEffectivePermissions returns the active permissions with the handles returned by EntityPermissionHandles
+func (store *memoryStore) EntityEffectivePermissions(ctx context.Context, entityType string, entityID string) ([]PermissionInterface, error) {
+	return entityEffectivePermissions(ctx, store, entityType, entityID)
+}
+
+// EntityPermissionExplain returns the decision trace of EntityHasPermission, see the database store
+func (store *memoryStore) EntityPermissionExplain(ctx context.Context, entityType string, entityID string, handle string) (Explanation, error) {
+	return entityPermissionExplain(ctx, store, explainSources{roles: true, memberships: true}, entityType, entityID, handle)
+}
+
+// entityGrants returns the grants of the entity matching the filter, all grants when the filter is nil.
+// It selects the same rows as the joins of the database store, see entityDirectGrantsQuery
+// and entityRoleGrantsQuery. The grants of the ancestors of the entity are inherited.
+// Grants via roles always allow and are always global
+func (store *memoryStore) entityGrants(ctx context.Context, entityType string, entityID string, filter *grantFilter) ([]grant, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+	active := func(tableName string, options memoryQueryOptions, status string) (map[string]map[string]string, error) {
+		rows, err := store.selectRows(tableName, options, memoryEq(COLUMN_STATUS, status))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return lo.KeyBy(rows, func(row map[string]string) string {
+			return row[COLUMN_ID]
+		}), nil
+	}
+
+	permissions, err := active(memoryTablePermission, NewPermissionQuery(), PERMISSION_STATUS_ACTIVE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	included := func(permissionID string) bool {
+		permission, exists := permissions[permissionID]
+
+		return exists && (filter == nil || lo.Contains(filter.handles, permission[COLUMN_HANDLE]))
+	}
+
+	entities := []EntityRef{{EntityType: entityType, EntityID: entityID}}
+
+	ancestors, err := entityAncestors(ctx, store.entityMembershipParents, entities[0], store.membershipMaxDepth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entities = append(entities, ancestors...)
+
+	entityPermissions, err := store.selectRows(memoryTableEntityPermission, NewEntityPermissionQuery(),
+		func(row map[string]string) bool {
+			return lo.Contains(entities, EntityRef{EntityType: row[COLUMN_ENTITY_TYPE], EntityID: row[COLUMN_ENTITY_ID]})
+		},
+		memoryGt(COLUMN_EXPIRES_AT, now),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	grants := []grant{}
+
+	for _, row := range entityPermissions {
+		if !included(row[COLUMN_PERMISSION_ID]) {
+			continue
+		}
+
+		g := grant{
+			handle:       permissions[row[COLUMN_PERMISSION_ID]][COLUMN_HANDLE],
+			effect:       row[COLUMN_EFFECT],
+			resourceType: row[COLUMN_RESOURCE_TYPE],
+			resourceID:   row[COLUMN_RESOURCE_ID],
+			validFrom:    row[COLUMN_VALID_FROM],
+			expiresAt:    row[COLUMN_EXPIRES_AT],
+		}
+
+		if filter != nil && g.isResourceScoped() && (g.resourceType != filter.resourceType || g.resourceID != filter.resourceID) {
+			continue
+		}
+
+		grants = append(grants, g)
+	}
+
+	roles, err := active(memoryTableRole, NewRoleQuery(), ROLE_STATUS_ACTIVE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entityRoles, err := store.selectRows(memoryTableEntityRole, NewEntityRoleQuery(),
+		memoryEq(COLUMN_ENTITY_TYPE, entityType),
+		memoryEq(COLUMN_ENTITY_ID, entityID),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := lo.Filter(lo.Map(entityRoles, func(row map[string]string, _ int) string {
+		return row[COLUMN_ROLE_ID]
+	}), func(roleID string, _ int) bool {
+		_, exists := roles[roleID]
+		return exists
+	})
+
+	if len(roleIDs) < 1 {
+		return grants, nil
+	}
+
+	rolePermissions, err := store.selectRows(memoryTableRolePermission, NewRolePermissionQuery(), memoryIn(COLUMN_ROLE_ID, roleIDs))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rolePermissions {
+		if !included(row[COLUMN_PERMISSION_ID]) {
+			continue
+		}
+
+		grants = append(grants, grant{
+			handle: permissions[row[COLUMN_PERMISSION_ID]][COLUMN_HANDLE],
+			effect: ENTITY_PERMISSION_EFFECT_ALLOW,
+		})
+	}
+
+	return grants, nil
+}
@@ -0,0 +1,462 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+func (store *memoryStore) EntityPermissionCount(ctx context.Context, options EntityPermissionQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("entityPermission options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.entityPermissionSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) EntityPermissionCreate(ctx context.Context, entityPermission EntityPermissionInterface) error {
+	if entityPermission == nil {
+		return errors.New("permissionstore > EntityPermissionCreate. entityPermission is nil")
+	}
+
+	if err := entityPermissionPrepareCreate(entityPermission); err != nil {
+		return errors.New("permissionstore > EntityPermissionCreate. " + err.Error())
+	}
+
+	entityPermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	entityPermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	err := store.insertRow(ctx, memoryTableEntityPermission, entityPermission.Data())
+
+	if errors.Is(err, errMemoryUniqueViolation) {
+		return newError("EntityPermissionCreate", ErrDuplicateGrant,
+			"entity_type", entityPermission.EntityType(),
+			"entity_id", entityPermission.EntityID(),
+			"permission_id", entityPermission.PermissionID(),
+			"resource_type", entityPermission.ResourceType(),
+			"resource_id", entityPermission.ResourceID(),
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	entityPermission.MarkAsNotDirty()
+
+	return nil
+}
+
+// EntityPermissionCreateMany creates the entity permissions in one transaction.
+// Entity permissions, which already exist or repeat an earlier one, are skipped
+// and reported as duplicates, see the database store.
+//
+// Returns a result for each entity permission in the given order.
+func (store *memoryStore) EntityPermissionCreateMany(ctx context.Context, entityPermissions []EntityPermissionInterface) ([]BulkResult, error) {
+	for index, entityPermission := range entityPermissions {
+		if entityPermission == nil {
+			return nil, errors.New("permissionstore > EntityPermissionCreateMany. entityPermission " + strconv.Itoa(index) + " is nil")
+		}
+
+		if err := entityPermissionPrepareCreate(entityPermission); err != nil {
+			return nil, errors.New("permissionstore > EntityPermissionCreateMany. entityPermission " + strconv.Itoa(index) + ": " + err.Error())
+		}
+	}
+
+	results := make([]BulkResult, len(entityPermissions))
+
+	err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+		rows, err := store.selectRows(memoryTableEntityPermission, NewEntityPermissionQuery())
+
+		if err != nil {
+			return err
+		}
+
+		existing := map[string]string{}
+
+		for _, row := range rows {
+			existing[bulkGrantKey(NewEntityPermissionFromExistingData(row))] = row[COLUMN_ID]
+		}
+
+		for index, entityPermission := range entityPermissions {
+			key := bulkGrantKey(entityPermission)
+
+			results[index] = BulkResult{
+				EntityType:   entityPermission.EntityType(),
+				EntityID:     entityPermission.EntityID(),
+				PermissionID: entityPermission.PermissionID(),
+				ResourceType: entityPermission.ResourceType(),
+				ResourceID:   entityPermission.ResourceID(),
+			}
+
+			if existingID, exists := existing[key]; exists {
+				results[index].EntityPermissionID = existingID
+				results[index].Status = BULK_STATUS_DUPLICATE
+				continue
+			}
+
+			entityPermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+			entityPermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+			err := store.insertRow(txCtx, memoryTableEntityPermission, entityPermission.Data())
+
+			if errors.Is(err, errMemoryUniqueViolation) {
+				return newError("EntityPermissionCreateMany", ErrDuplicateGrant)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			existing[key] = entityPermission.ID()
+
+			results[index].EntityPermissionID = entityPermission.ID()
+			results[index].Status = BULK_STATUS_CREATED
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entityPermission := range entityPermissions {
+		entityPermission.MarkAsNotDirty()
+	}
+
+	return results, nil
+}
+
+func (store *memoryStore) EntityPermissionDelete(ctx context.Context, entityPermission EntityPermissionInterface) error {
+	if entityPermission == nil {
+		return errors.New("entityPermission is nil")
+	}
+
+	return store.EntityPermissionDeleteByID(ctx, entityPermission.ID())
+}
+
+func (store *memoryStore) EntityPermissionDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("entityPermission id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTableEntityPermission, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+// EntityPermissionFindByEntityAndPermission returns the global (not resource scoped)
+// permission entity mapping by its entity type, entity ID and permission ID
+func (store *memoryStore) EntityPermissionFindByEntityAndPermission(ctx context.Context, entityType string, entityID string, permissionID string) (EntityPermissionInterface, error) {
+	return store.EntityPermissionFindByEntityPermissionAndResource(ctx, entityType, entityID, permissionID, "", "")
+}
+
+// EntityPermissionFindByEntityPermissionAndResource returns the permission entity mapping
+// by its entity type, entity ID, permission ID and resource. Empty resource type and
+// resource ID find the global mapping
+func (store *memoryStore) EntityPermissionFindByEntityPermissionAndResource(
+	ctx context.Context,
+	entityType string,
+	entityID string,
+	permissionID string,
+	resourceType string,
+	resourceID string,
+) (EntityPermissionInterface, error) {
+	if entityType == "" {
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource entityID is empty")
+	}
+
+	if permissionID == "" {
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource permissionID is empty")
+	}
+
+	list, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery().
+		SetEntityType(entityType).
+		SetEntityID(entityID).
+		SetPermissionID(permissionID).
+		SetResourceType(resourceType).
+		SetResourceID(resourceID).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) EntityPermissionFindByID(ctx context.Context, id string) (EntityPermissionInterface, error) {
+	if id == "" {
+		return nil, errors.New("entityPermission id is empty")
+	}
+
+	list, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// EntityPermissionGetByID returns a permission entity mapping by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) EntityPermissionGetByID(ctx context.Context, id string) (EntityPermissionInterface, error) {
+	entityPermission, err := store.EntityPermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entityPermission == nil {
+		return nil, newError("EntityPermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return entityPermission, nil
+}
+
+func (store *memoryStore) EntityPermissionList(ctx context.Context, query EntityPermissionQueryInterface) ([]EntityPermissionInterface, error) {
+	if query == nil {
+		return []EntityPermissionInterface{}, errors.New("at entityPermission list > entityPermission query is nil")
+	}
+
+	rows, err := store.entityPermissionSelectRows(query)
+
+	if err != nil {
+		return []EntityPermissionInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) EntityPermissionInterface {
+		return NewEntityPermissionFromExistingData(row)
+	}), nil
+}
+
+// EntityPermissionPurgeExpired permanently deletes the entity permissions,
+// which expired more than olderThan ago, and returns the number of deleted rows.
+// Use zero to delete all expired entity permissions.
+func (store *memoryStore) EntityPermissionPurgeExpired(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan < 0 {
+		return 0, errors.New("permissionstore > EntityPermissionPurgeExpired. olderThan " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	expiredBefore := carbon.CreateFromStdTime(time.Now().UTC().Add(-olderThan), carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	purged := store.deleteRows(ctx, memoryTableEntityPermission, memoryLte(COLUMN_EXPIRES_AT, expiredBefore))
+
+	return int64(len(purged)), nil
+}
+
+func (store *memoryStore) EntityPermissionSoftDelete(ctx context.Context, entityPermission EntityPermissionInterface) error {
+	if entityPermission == nil {
+		return errors.New("at entityPermission soft delete > entityPermission is nil")
+	}
+
+	entityPermission.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.EntityPermissionUpdate(ctx, entityPermission)
+}
+
+func (store *memoryStore) EntityPermissionSoftDeleteByID(ctx context.Context, id string) error {
+	entityPermission, err := store.EntityPermissionGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.EntityPermissionSoftDelete(ctx, entityPermission)
+}
+
+func (store *memoryStore) EntityPermissionUpdate(ctx context.Context, entityPermission EntityPermissionInterface) error {
+	if entityPermission == nil {
+		return errors.New("at entityPermission update > entityPermission is nil")
+	}
+
+	if !lo.Contains([]string{ENTITY_PERMISSION_EFFECT_ALLOW, ENTITY_PERMISSION_EFFECT_DENY}, entityPermission.Effect()) {
+		return errors.New("at entityPermission update > entityPermission effect must be allow or deny")
+	}
+
+	if (entityPermission.ResourceType() == "") != (entityPermission.ResourceID() == "") {
+		return errors.New("at entityPermission update > entityPermission resourceType and resourceID must be both set or both empty")
+	}
+
+	if err := entityPermissionValidateWindow(entityPermission); err != nil {
+		return errors.New("at entityPermission update > " + err.Error())
+	}
+
+	entityPermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := entityPermission.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	err := store.updateRow(ctx, memoryTableEntityPermission, entityPermission.ID(), dataChanged)
+
+	entityPermission.MarkAsNotDirty()
+
+	if errors.Is(err, errMemoryUniqueViolation) {
+		return newError("EntityPermissionUpdate", ErrDuplicateGrant,
+			"id", entityPermission.ID(),
+			"entity_type", entityPermission.EntityType(),
+			"entity_id", entityPermission.EntityID(),
+			"permission_id", entityPermission.PermissionID(),
+			"resource_type", entityPermission.ResourceType(),
+			"resource_id", entityPermission.ResourceID(),
+		)
+	}
+
+	return err
+}
+
+// RevokeFromEntities deletes the global allow grants of the permission to the
+// entities in one transaction. Entities without such a grant are reported as
+// not found, repeated entities as duplicates, see the database store.
+//
+// Returns a result for each entity in the given order.
+func (store *memoryStore) RevokeFromEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error) {
+	if permissionID == "" {
+		return nil, errors.New("permissionstore > RevokeFromEntities. permissionID is empty")
+	}
+
+	for index, entity := range entities {
+		if entity.EntityType == "" || entity.EntityID == "" {
+			return nil, errors.New("permissionstore > RevokeFromEntities. entity " + strconv.Itoa(index) + " entityType or entityID is empty")
+		}
+	}
+
+	results := make([]BulkResult, len(entities))
+
+	err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+		rows, err := store.selectRows(memoryTableEntityPermission, NewEntityPermissionQuery(),
+			memoryEq(COLUMN_PERMISSION_ID, permissionID),
+			memoryEq(COLUMN_EFFECT, ENTITY_PERMISSION_EFFECT_ALLOW),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		rowsByKey := map[string]map[string]string{}
+
+		for _, row := range rows {
+			rowsByKey[bulkGrantKey(NewEntityPermissionFromExistingData(row))] = row
+		}
+
+		revoked := map[string]string{}
+
+		for index, entity := range entities {
+			key := bulkGrantKey(NewEntityPermission().
+				SetEntityType(entity.EntityType).
+				SetEntityID(entity.EntityID).
+				SetPermissionID(permissionID))
+
+			results[index] = BulkResult{
+				EntityType:   entity.EntityType,
+				EntityID:     entity.EntityID,
+				PermissionID: permissionID,
+				Status:       BULK_STATUS_NOT_FOUND,
+			}
+
+			if revokedID, exists := revoked[key]; exists {
+				results[index].EntityPermissionID = revokedID
+				results[index].Status = BULK_STATUS_DUPLICATE
+				continue
+			}
+
+			row, exists := rowsByKey[key]
+
+			if !exists {
+				continue
+			}
+
+			revoked[key] = row[COLUMN_ID]
+			store.deleteRows(txCtx, memoryTableEntityPermission, memoryEq(COLUMN_ID, row[COLUMN_ID]))
+			results[index].EntityPermissionID = row[COLUMN_ID]
+			results[index].Status = BULK_STATUS_REVOKED
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// entityPermissionSelectRows returns the rows of the entity permissions matching the query options,
+// the counterpart of entityPermissionSelectQuery of the database store
+func (store *memoryStore) entityPermissionSelectRows(options EntityPermissionQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("EntityPermissionQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasEffect() {
+		conditions = append(conditions, memoryEq(COLUMN_EFFECT, options.Effect()))
+	}
+
+	if options.HasEntityID() {
+		conditions = append(conditions, memoryEq(COLUMN_ENTITY_ID, options.EntityID()))
+	}
+
+	if options.HasEntityType() {
+		conditions = append(conditions, memoryEq(COLUMN_ENTITY_TYPE, options.EntityType()))
+	}
+
+	if options.HasPermissionID() {
+		conditions = append(conditions, memoryEq(COLUMN_PERMISSION_ID, options.PermissionID()))
+	}
+
+	if options.HasResourceType() {
+		conditions = append(conditions, memoryEq(COLUMN_RESOURCE_TYPE, options.ResourceType()))
+	}
+
+	if options.HasResourceID() {
+		conditions = append(conditions, memoryEq(COLUMN_RESOURCE_ID, options.ResourceID()))
+	}
+
+	if options.HasValidity() {
+		now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+		switch options.Validity() {
+		case ENTITY_PERMISSION_VALIDITY_ACTIVE:
+			conditions = append(conditions, memoryLte(COLUMN_VALID_FROM, now), memoryGt(COLUMN_EXPIRES_AT, now))
+		case ENTITY_PERMISSION_VALIDITY_EXPIRED:
+			conditions = append(conditions, memoryLte(COLUMN_EXPIRES_AT, now))
+		case ENTITY_PERMISSION_VALIDITY_PENDING:
+			conditions = append(conditions, memoryGt(COLUMN_VALID_FROM, now))
+		}
+	}
+
+	return store.selectRows(memoryTableEntityPermission, options, conditions...)
+}
@@ -0,0 +1,94 @@
+package permissionstore
+
+import (
+	"context"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// EntitiesWithPermission returns the distinct entities, which are granted the permission, see the database store
+func (store *memoryStore) EntitiesWithPermission(ctx context.Context, handle string, query EntitiesQuery) ([]EntityRef, error) {
+	entities, _, err := entitiesWithPermission(ctx, store.entityGrantsBatch, store.grantHolders, store.entityMembershipMembers, store.membershipMaxDepth, handle, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// EntitiesWithPermissionCount returns the number of distinct entities, which are granted the permission, see the database store
+func (store *memoryStore) EntitiesWithPermissionCount(ctx context.Context, handle string, query EntitiesQuery) (int64, error) {
+	_, total, err := entitiesWithPermission(ctx, store.entityGrantsBatch, store.grantHolders, store.entityMembershipMembers, store.membershipMaxDepth, handle, query)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return total, nil
+}
+
+// grantHolders returns the entities holding a global allow grant on any of the
+// permissions with the given handles, the counterpart of grantHolders of the database store
+func (store *memoryStore) grantHolders(ctx context.Context, handles []string) ([]EntityRef, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+	permissions, err := store.selectRows(memoryTablePermission, NewPermissionQuery(),
+		memoryEq(COLUMN_STATUS, PERMISSION_STATUS_ACTIVE),
+		memoryIn(COLUMN_HANDLE, handles),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	permissionIDs := lo.Map(permissions, func(row map[string]string, _ int) string {
+		return row[COLUMN_ID]
+	})
+
+	entityPermissions, err := store.selectRows(memoryTableEntityPermission, NewEntityPermissionQuery(),
+		memoryIn(COLUMN_PERMISSION_ID, permissionIDs),
+		memoryGt(COLUMN_EXPIRES_AT, now),
+		memoryEq(COLUMN_RESOURCE_TYPE, ""),
+		memoryEq(COLUMN_RESOURCE_ID, ""),
+		func(row map[string]string) bool {
+			return row[COLUMN_EFFECT] != ENTITY_PERMISSION_EFFECT_DENY
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := store.selectRows(memoryTableRole, NewRoleQuery(), memoryEq(COLUMN_STATUS, ROLE_STATUS_ACTIVE))
+
+	if err != nil {
+		return nil, err
+	}
+
+	rolePermissions, err := store.selectRows(memoryTableRolePermission, NewRolePermissionQuery(),
+		memoryIn(COLUMN_PERMISSION_ID, permissionIDs),
+		memoryIn(COLUMN_ROLE_ID, lo.Map(roles, func(row map[string]string, _ int) string {
+			return row[COLUMN_ID]
+		})),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entityRoles, err := store.selectRows(memoryTableEntityRole, NewEntityRoleQuery(),
+		memoryIn(COLUMN_ROLE_ID, lo.Map(rolePermissions, func(row map[string]string, _ int) string {
+			return row[COLUMN_ROLE_ID]
+		})),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(append(entityPermissions, entityRoles...), func(row map[string]string, _ int) EntityRef {
+		return EntityRef{EntityType: row[COLUMN_ENTITY_TYPE], EntityID: row[COLUMN_ENTITY_ID]}
+	}), nil
+}
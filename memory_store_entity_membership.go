@@ -0,0 +1,274 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// EntityAncestors returns the entities the entity is a member of, directly or transitively, see the database store
+func (store *memoryStore) EntityAncestors(ctx context.Context, entityType string, entityID string) ([]EntityRef, error) {
+	if entityType == "" {
+		return nil, errors.New("permissionstore > EntityAncestors. entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("permissionstore > EntityAncestors. entityID is empty")
+	}
+
+	return entityAncestors(ctx, store.entityMembershipParents, EntityRef{EntityType: entityType, EntityID: entityID}, store.membershipMaxDepth)
+}
+
+func (store *memoryStore) EntityMembershipCount(ctx context.Context, options EntityMembershipQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("entityMembership options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.entityMembershipSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) EntityMembershipCreate(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("permissionstore > EntityMembershipCreate. entityMembership is nil")
+	}
+
+	if err := entityMembershipValidate(entityMembership); err != nil {
+		return errors.New("permissionstore > EntityMembershipCreate. " + err.Error())
+	}
+
+	entityMembershipExists, err := store.EntityMembershipFindByMemberAndParent(ctx, entityMembership.MemberType(), entityMembership.MemberID(), entityMembership.ParentType(), entityMembership.ParentID())
+
+	if err != nil {
+		return err
+	}
+
+	if entityMembershipExists != nil {
+		return newError("EntityMembershipCreate", ErrDuplicateGrant, "member_type", entityMembership.MemberType(), "member_id", entityMembership.MemberID(), "parent_type", entityMembership.ParentType(), "parent_id", entityMembership.ParentID())
+	}
+
+	entityMembership.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	entityMembership.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	if err := store.insertRow(ctx, memoryTableEntityMembership, entityMembership.Data()); err != nil {
+		return err
+	}
+
+	entityMembership.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *memoryStore) EntityMembershipDelete(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("entityMembership is nil")
+	}
+
+	return store.EntityMembershipDeleteByID(ctx, entityMembership.ID())
+}
+
+func (store *memoryStore) EntityMembershipDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("entityMembership id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTableEntityMembership, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+func (store *memoryStore) EntityMembershipFindByMemberAndParent(ctx context.Context, memberType string, memberID string, parentType string, parentID string) (EntityMembershipInterface, error) {
+	if memberType == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent memberType is empty")
+	}
+
+	if memberID == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent memberID is empty")
+	}
+
+	if parentType == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent parentType is empty")
+	}
+
+	if parentID == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent parentID is empty")
+	}
+
+	list, err := store.EntityMembershipList(ctx, NewEntityMembershipQuery().
+		SetMemberType(memberType).
+		SetMemberID(memberID).
+		SetParentType(parentType).
+		SetParentID(parentID).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) EntityMembershipFindByID(ctx context.Context, id string) (EntityMembershipInterface, error) {
+	if id == "" {
+		return nil, errors.New("entityMembership id is empty")
+	}
+
+	list, err := store.EntityMembershipList(ctx, NewEntityMembershipQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// EntityMembershipGetByID returns an entity membership by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) EntityMembershipGetByID(ctx context.Context, id string) (EntityMembershipInterface, error) {
+	entityMembership, err := store.EntityMembershipFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entityMembership == nil {
+		return nil, newError("EntityMembershipGetByID", ErrNotFound, "id", id)
+	}
+
+	return entityMembership, nil
+}
+
+func (store *memoryStore) EntityMembershipList(ctx context.Context, query EntityMembershipQueryInterface) ([]EntityMembershipInterface, error) {
+	if query == nil {
+		return []EntityMembershipInterface{}, errors.New("at entityMembership list > entityMembership query is nil")
+	}
+
+	rows, err := store.entityMembershipSelectRows(query)
+
+	if err != nil {
+		return []EntityMembershipInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) EntityMembershipInterface {
+		return NewEntityMembershipFromExistingData(row)
+	}), nil
+}
+
+func (store *memoryStore) EntityMembershipSoftDelete(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("at entityMembership soft delete > entityMembership is nil")
+	}
+
+	entityMembership.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.EntityMembershipUpdate(ctx, entityMembership)
+}
+
+func (store *memoryStore) EntityMembershipSoftDeleteByID(ctx context.Context, id string) error {
+	entityMembership, err := store.EntityMembershipGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.EntityMembershipSoftDelete(ctx, entityMembership)
+}
+
+func (store *memoryStore) EntityMembershipUpdate(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("at entityMembership update > entityMembership is nil")
+	}
+
+	if err := entityMembershipValidate(entityMembership); err != nil {
+		return errors.New("permissionstore > EntityMembershipUpdate. " + err.Error())
+	}
+
+	entityMembership.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := entityMembership.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	err := store.updateRow(ctx, memoryTableEntityMembership, entityMembership.ID(), dataChanged)
+
+	entityMembership.MarkAsNotDirty()
+
+	return err
+}
+
+// entityMembershipSelectRows returns the rows of the entity memberships matching the query options,
+// the counterpart of entityMembershipSelectQuery of the database store
+func (store *memoryStore) entityMembershipSelectRows(options EntityMembershipQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("EntityMembershipQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasMemberID() {
+		conditions = append(conditions, memoryEq(COLUMN_MEMBER_ID, options.MemberID()))
+	}
+
+	if options.HasMemberType() {
+		conditions = append(conditions, memoryEq(COLUMN_MEMBER_TYPE, options.MemberType()))
+	}
+
+	if options.HasParentID() {
+		conditions = append(conditions, memoryEq(COLUMN_PARENT_ID, options.ParentID()))
+	}
+
+	if options.HasParentType() {
+		conditions = append(conditions, memoryEq(COLUMN_PARENT_TYPE, options.ParentType()))
+	}
+
+	return store.selectRows(memoryTableEntityMembership, options, conditions...)
+}
+
+// entityMembershipParents returns the parents of the entities via the memberships,
+// which are not soft deleted, see membershipLoader
+func (store *memoryStore) entityMembershipParents(ctx context.Context, entities []EntityRef) ([]EntityRef, error) {
+	return store.entityMembershipLinked(entities, COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID)
+}
+
+// entityMembershipMembers returns the members of the entities via the memberships,
+// which are not soft deleted, see membershipLoader
+func (store *memoryStore) entityMembershipMembers(ctx context.Context, entities []EntityRef) ([]EntityRef, error) {
+	return store.entityMembershipLinked(entities, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID, COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID)
+}
+
+// entityMembershipLinked returns the entities in the columns toType and toID of the
+// memberships, which are not soft deleted, having one of the entities in the columns
+// fromType and fromID, the counterpart of entityMembershipLinked of the database store
+func (store *memoryStore) entityMembershipLinked(entities []EntityRef, fromType string, fromID string, toType string, toID string) ([]EntityRef, error) {
+	rows, err := store.selectRows(memoryTableEntityMembership, NewEntityMembershipQuery(), func(row map[string]string) bool {
+		return lo.Contains(entities, EntityRef{EntityType: row[fromType], EntityID: row[fromID]})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) EntityRef {
+		return EntityRef{EntityType: row[toType], EntityID: row[toID]}
+	}), nil
+}
@@ -0,0 +1,227 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+func (store *memoryStore) EntityRoleCount(ctx context.Context, options EntityRoleQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("entityRole options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.entityRoleSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) EntityRoleCreate(ctx context.Context, entityRole EntityRoleInterface) error {
+	if entityRole == nil {
+		return errors.New("permissionstore > EntityRoleCreate. entityRole is nil")
+	}
+
+	if entityRole.RoleID() == "" {
+		return errors.New("permissionstore > EntityRoleCreate. entityRole roleID is empty")
+	}
+
+	if entityRole.EntityID() == "" {
+		return errors.New("permissionstore > EntityRoleCreate. entityRole entityID is empty")
+	}
+
+	if entityRole.EntityType() == "" {
+		return errors.New("permissionstore > EntityRoleCreate. entityRole entityType is empty")
+	}
+
+	entityRoleExists, err := store.EntityRoleFindByEntityAndRole(ctx, entityRole.EntityType(), entityRole.EntityID(), entityRole.RoleID())
+
+	if err != nil {
+		return err
+	}
+
+	if entityRoleExists != nil {
+		return newError("EntityRoleCreate", ErrDuplicateGrant, "entity_type", entityRole.EntityType(), "entity_id", entityRole.EntityID(), "role_id", entityRole.RoleID())
+	}
+
+	entityRole.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	entityRole.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	if err := store.insertRow(ctx, memoryTableEntityRole, entityRole.Data()); err != nil {
+		return err
+	}
+
+	entityRole.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *memoryStore) EntityRoleDelete(ctx context.Context, entityRole EntityRoleInterface) error {
+	if entityRole == nil {
+		return errors.New("entityRole is nil")
+	}
+
+	return store.EntityRoleDeleteByID(ctx, entityRole.ID())
+}
+
+func (store *memoryStore) EntityRoleDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("entityRole id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTableEntityRole, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+func (store *memoryStore) EntityRoleFindByEntityAndRole(ctx context.Context, entityType string, entityID string, roleID string) (EntityRoleInterface, error) {
+	if entityType == "" {
+		return nil, errors.New("EntityRoleFindByEntityAndRole entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("EntityRoleFindByEntityAndRole entityID is empty")
+	}
+
+	if roleID == "" {
+		return nil, errors.New("EntityRoleFindByEntityAndRole roleID is empty")
+	}
+
+	list, err := store.EntityRoleList(ctx, NewEntityRoleQuery().
+		SetEntityType(entityType).
+		SetEntityID(entityID).
+		SetRoleID(roleID).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) EntityRoleFindByID(ctx context.Context, id string) (EntityRoleInterface, error) {
+	if id == "" {
+		return nil, errors.New("entityRole id is empty")
+	}
+
+	list, err := store.EntityRoleList(ctx, NewEntityRoleQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// EntityRoleGetByID returns an entity role mapping by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) EntityRoleGetByID(ctx context.Context, id string) (EntityRoleInterface, error) {
+	entityRole, err := store.EntityRoleFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entityRole == nil {
+		return nil, newError("EntityRoleGetByID", ErrNotFound, "id", id)
+	}
+
+	return entityRole, nil
+}
+
+func (store *memoryStore) EntityRoleList(ctx context.Context, query EntityRoleQueryInterface) ([]EntityRoleInterface, error) {
+	if query == nil {
+		return []EntityRoleInterface{}, errors.New("at entityRole list > entityRole query is nil")
+	}
+
+	rows, err := store.entityRoleSelectRows(query)
+
+	if err != nil {
+		return []EntityRoleInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) EntityRoleInterface {
+		return NewEntityRoleFromExistingData(row)
+	}), nil
+}
+
+func (store *memoryStore) EntityRoleSoftDelete(ctx context.Context, entityRole EntityRoleInterface) error {
+	if entityRole == nil {
+		return errors.New("at entityRole soft delete > entityRole is nil")
+	}
+
+	entityRole.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.EntityRoleUpdate(ctx, entityRole)
+}
+
+func (store *memoryStore) EntityRoleSoftDeleteByID(ctx context.Context, id string) error {
+	entityRole, err := store.EntityRoleGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.EntityRoleSoftDelete(ctx, entityRole)
+}
+
+func (store *memoryStore) EntityRoleUpdate(ctx context.Context, entityRole EntityRoleInterface) error {
+	if entityRole == nil {
+		return errors.New("at entityRole update > entityRole is nil")
+	}
+
+	entityRole.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := entityRole.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	err := store.updateRow(ctx, memoryTableEntityRole, entityRole.ID(), dataChanged)
+
+	entityRole.MarkAsNotDirty()
+
+	return err
+}
+
+// entityRoleSelectRows returns the rows of the entity roles matching the query options,
+// the counterpart of entityRoleSelectQuery of the database store
+func (store *memoryStore) entityRoleSelectRows(options EntityRoleQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("EntityRoleQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasEntityID() {
+		conditions = append(conditions, memoryEq(COLUMN_ENTITY_ID, options.EntityID()))
+	}
+
+	if options.HasEntityType() {
+		conditions = append(conditions, memoryEq(COLUMN_ENTITY_TYPE, options.EntityType()))
+	}
+
+	if options.HasRoleID() {
+		conditions = append(conditions, memoryEq(COLUMN_ROLE_ID, options.RoleID()))
+	}
+
+	return store.selectRows(memoryTableEntityRole, options, conditions...)
+}
@@ -0,0 +1,247 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+func (store *memoryStore) PermissionCount(ctx context.Context, options PermissionQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("permission options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.permissionSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) PermissionCreate(ctx context.Context, permission PermissionInterface) error {
+	if permission == nil {
+		return errors.New("permission is nil")
+	}
+
+	if err := PermissionHandleValidate(permission.Handle()); err != nil {
+		return errors.New("permissionstore > PermissionCreate. " + err.Error())
+	}
+
+	permission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	permission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	err := store.insertRow(ctx, memoryTablePermission, permission.Data())
+
+	if errors.Is(err, errMemoryUniqueViolation) {
+		return newError("PermissionCreate", ErrDuplicateHandle, "handle", permission.Handle())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	permission.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *memoryStore) PermissionDelete(ctx context.Context, permission PermissionInterface) error {
+	if permission == nil {
+		return errors.New("permission is nil")
+	}
+
+	return store.PermissionDeleteByID(ctx, permission.ID())
+}
+
+func (store *memoryStore) PermissionDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("permission id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTablePermission, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+func (store *memoryStore) PermissionFindByHandle(ctx context.Context, handle string) (PermissionInterface, error) {
+	if handle == "" {
+		return nil, errors.New("permission handle is empty")
+	}
+
+	list, err := store.PermissionList(ctx, NewPermissionQuery().SetHandle(handle).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) PermissionFindByID(ctx context.Context, id string) (PermissionInterface, error) {
+	if id == "" {
+		return nil, errors.New("permission id is empty")
+	}
+
+	list, err := store.PermissionList(ctx, NewPermissionQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// PermissionGetByHandle returns a permission by its handle, ErrNotFound if it does not exist
+func (store *memoryStore) PermissionGetByHandle(ctx context.Context, handle string) (PermissionInterface, error) {
+	permission, err := store.PermissionFindByHandle(ctx, handle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if permission == nil {
+		return nil, newError("PermissionGetByHandle", ErrNotFound, "handle", handle)
+	}
+
+	return permission, nil
+}
+
+// PermissionGetByID returns a permission by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) PermissionGetByID(ctx context.Context, id string) (PermissionInterface, error) {
+	permission, err := store.PermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if permission == nil {
+		return nil, newError("PermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return permission, nil
+}
+
+// PermissionHandleTree returns the handle tree of the permissions matching the given query options
+func (store *memoryStore) PermissionHandleTree(ctx context.Context, query PermissionQueryInterface) ([]*PermissionHandleNode, error) {
+	permissions, err := store.PermissionList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionHandleTreeBuild(permissions), nil
+}
+
+func (store *memoryStore) PermissionList(ctx context.Context, query PermissionQueryInterface) ([]PermissionInterface, error) {
+	if query == nil {
+		return []PermissionInterface{}, errors.New("at permission list > permission query is nil")
+	}
+
+	rows, err := store.permissionSelectRows(query)
+
+	if err != nil {
+		return []PermissionInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) PermissionInterface {
+		return NewPermissionFromExistingData(row)
+	}), nil
+}
+
+func (store *memoryStore) PermissionSoftDelete(ctx context.Context, permission PermissionInterface) error {
+	if permission == nil {
+		return errors.New("at permission soft delete > permission is nil")
+	}
+
+	permission.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.PermissionUpdate(ctx, permission)
+}
+
+func (store *memoryStore) PermissionSoftDeleteByID(ctx context.Context, id string) error {
+	permission, err := store.PermissionGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.PermissionSoftDelete(ctx, permission)
+}
+
+func (store *memoryStore) PermissionUpdate(ctx context.Context, permission PermissionInterface) error {
+	if permission == nil {
+		return errors.New("at permission update > permission is nil")
+	}
+
+	permission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := permission.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	if handle, changed := dataChanged[COLUMN_HANDLE]; changed {
+		if err := PermissionHandleValidate(handle); err != nil {
+			return errors.New("permissionstore > PermissionUpdate. " + err.Error())
+		}
+	}
+
+	err := store.updateRow(ctx, memoryTablePermission, permission.ID(), dataChanged)
+
+	permission.MarkAsNotDirty()
+
+	if errors.Is(err, errMemoryUniqueViolation) {
+		return newError("PermissionUpdate", ErrDuplicateHandle, "id", permission.ID(), "handle", permission.Handle())
+	}
+
+	return err
+}
+
+// permissionSelectRows returns the rows of the permissions matching the query options,
+// the counterpart of permissionSelectQuery of the database store
+func (store *memoryStore) permissionSelectRows(options PermissionQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("PermissionQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasStatus() {
+		conditions = append(conditions, memoryEq(COLUMN_STATUS, options.Status()))
+	}
+
+	if options.HasStatusIn() {
+		conditions = append(conditions, memoryIn(COLUMN_STATUS, options.StatusIn()))
+	}
+
+	if options.HasHandle() {
+		conditions = append(conditions, memoryEq(COLUMN_HANDLE, options.Handle()))
+	}
+
+	if options.HasHandleIn() {
+		conditions = append(conditions, memoryIn(COLUMN_HANDLE, options.HandleIn()))
+	}
+
+	if options.HasTitleLike() {
+		conditions = append(conditions, memoryILike(COLUMN_TITLE, `%`+options.TitleLike()+`%`))
+	}
+
+	return store.selectRows(memoryTablePermission, options, conditions...)
+}
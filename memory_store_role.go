@@ -0,0 +1,212 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+func (store *memoryStore) RoleCount(ctx context.Context, options RoleQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("role options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.roleSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) RoleCreate(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("role is nil")
+	}
+
+	role.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	role.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	if err := store.insertRow(ctx, memoryTableRole, role.Data()); err != nil {
+		return err
+	}
+
+	role.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *memoryStore) RoleDelete(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("role is nil")
+	}
+
+	return store.RoleDeleteByID(ctx, role.ID())
+}
+
+func (store *memoryStore) RoleDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("role id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTableRole, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+func (store *memoryStore) RoleFindByHandle(ctx context.Context, handle string) (RoleInterface, error) {
+	if handle == "" {
+		return nil, errors.New("role handle is empty")
+	}
+
+	list, err := store.RoleList(ctx, NewRoleQuery().SetHandle(handle).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) RoleFindByID(ctx context.Context, id string) (RoleInterface, error) {
+	if id == "" {
+		return nil, errors.New("role id is empty")
+	}
+
+	list, err := store.RoleList(ctx, NewRoleQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// RoleGetByHandle returns a role by its handle, ErrNotFound if it does not exist
+func (store *memoryStore) RoleGetByHandle(ctx context.Context, handle string) (RoleInterface, error) {
+	role, err := store.RoleFindByHandle(ctx, handle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, newError("RoleGetByHandle", ErrNotFound, "handle", handle)
+	}
+
+	return role, nil
+}
+
+// RoleGetByID returns a role by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) RoleGetByID(ctx context.Context, id string) (RoleInterface, error) {
+	role, err := store.RoleFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, newError("RoleGetByID", ErrNotFound, "id", id)
+	}
+
+	return role, nil
+}
+
+func (store *memoryStore) RoleList(ctx context.Context, query RoleQueryInterface) ([]RoleInterface, error) {
+	if query == nil {
+		return []RoleInterface{}, errors.New("at role list > role query is nil")
+	}
+
+	rows, err := store.roleSelectRows(query)
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) RoleInterface {
+		return NewRoleFromExistingData(row)
+	}), nil
+}
+
+func (store *memoryStore) RoleSoftDelete(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("at role soft delete > role is nil")
+	}
+
+	role.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.RoleUpdate(ctx, role)
+}
+
+func (store *memoryStore) RoleSoftDeleteByID(ctx context.Context, id string) error {
+	role, err := store.RoleGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.RoleSoftDelete(ctx, role)
+}
+
+func (store *memoryStore) RoleUpdate(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("at role update > role is nil")
+	}
+
+	role.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := role.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	err := store.updateRow(ctx, memoryTableRole, role.ID(), dataChanged)
+
+	role.MarkAsNotDirty()
+
+	return err
+}
+
+// roleSelectRows returns the rows of the roles matching the query options,
+// the counterpart of roleSelectQuery of the database store
+func (store *memoryStore) roleSelectRows(options RoleQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("RoleQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasStatus() {
+		conditions = append(conditions, memoryEq(COLUMN_STATUS, options.Status()))
+	}
+
+	if options.HasStatusIn() {
+		conditions = append(conditions, memoryIn(COLUMN_STATUS, options.StatusIn()))
+	}
+
+	if options.HasHandle() {
+		conditions = append(conditions, memoryEq(COLUMN_HANDLE, options.Handle()))
+	}
+
+	if options.HasTitleLike() {
+		conditions = append(conditions, memoryILike(COLUMN_TITLE, `%`+options.TitleLike()+`%`))
+	}
+
+	return store.selectRows(memoryTableRole, options, conditions...)
+}
@@ -0,0 +1,214 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+func (store *memoryStore) RolePermissionCount(ctx context.Context, options RolePermissionQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("rolePermission options is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	rows, err := store.rolePermissionSelectRows(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+func (store *memoryStore) RolePermissionCreate(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission is nil")
+	}
+
+	if rolePermission.PermissionID() == "" {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission permissionID is empty")
+	}
+
+	if rolePermission.RoleID() == "" {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission roleID is empty")
+	}
+
+	rolePermissionExists, err := store.RolePermissionFindByRoleAndPermission(ctx, rolePermission.RoleID(), rolePermission.PermissionID())
+
+	if err != nil {
+		return err
+	}
+
+	if rolePermissionExists != nil {
+		return newError("RolePermissionCreate", ErrDuplicateGrant, "role_id", rolePermission.RoleID(), "permission_id", rolePermission.PermissionID())
+	}
+
+	rolePermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	rolePermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	if err := store.insertRow(ctx, memoryTableRolePermission, rolePermission.Data()); err != nil {
+		return err
+	}
+
+	rolePermission.MarkAsNotDirty()
+
+	return nil
+}
+
+func (store *memoryStore) RolePermissionDelete(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("rolePermission is nil")
+	}
+
+	return store.RolePermissionDeleteByID(ctx, rolePermission.ID())
+}
+
+func (store *memoryStore) RolePermissionDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("rolePermission id is empty")
+	}
+
+	store.deleteRows(ctx, memoryTableRolePermission, memoryEq(COLUMN_ID, id))
+
+	return nil
+}
+
+func (store *memoryStore) RolePermissionFindByRoleAndPermission(ctx context.Context, roleID string, permissionID string) (RolePermissionInterface, error) {
+	if roleID == "" {
+		return nil, errors.New("RolePermissionFindByRoleAndPermission roleID is empty")
+	}
+
+	if permissionID == "" {
+		return nil, errors.New("RolePermissionFindByRoleAndPermission permissionID is empty")
+	}
+
+	list, err := store.RolePermissionList(ctx, NewRolePermissionQuery().
+		SetRoleID(roleID).
+		SetPermissionID(permissionID).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *memoryStore) RolePermissionFindByID(ctx context.Context, id string) (RolePermissionInterface, error) {
+	if id == "" {
+		return nil, errors.New("rolePermission id is empty")
+	}
+
+	list, err := store.RolePermissionList(ctx, NewRolePermissionQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// RolePermissionGetByID returns a role permission mapping by its ID, ErrNotFound if it does not exist
+func (store *memoryStore) RolePermissionGetByID(ctx context.Context, id string) (RolePermissionInterface, error) {
+	rolePermission, err := store.RolePermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rolePermission == nil {
+		return nil, newError("RolePermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return rolePermission, nil
+}
+
+func (store *memoryStore) RolePermissionList(ctx context.Context, query RolePermissionQueryInterface) ([]RolePermissionInterface, error) {
+	if query == nil {
+		return []RolePermissionInterface{}, errors.New("at rolePermission list > rolePermission query is nil")
+	}
+
+	rows, err := store.rolePermissionSelectRows(query)
+
+	if err != nil {
+		return []RolePermissionInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) RolePermissionInterface {
+		return NewRolePermissionFromExistingData(row)
+	}), nil
+}
+
+func (store *memoryStore) RolePermissionSoftDelete(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("at rolePermission soft delete > rolePermission is nil")
+	}
+
+	rolePermission.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.RolePermissionUpdate(ctx, rolePermission)
+}
+
+func (store *memoryStore) RolePermissionSoftDeleteByID(ctx context.Context, id string) error {
+	rolePermission, err := store.RolePermissionGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.RolePermissionSoftDelete(ctx, rolePermission)
+}
+
+func (store *memoryStore) RolePermissionUpdate(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("at rolePermission update > rolePermission is nil")
+	}
+
+	rolePermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := rolePermission.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	err := store.updateRow(ctx, memoryTableRolePermission, rolePermission.ID(), dataChanged)
+
+	rolePermission.MarkAsNotDirty()
+
+	return err
+}
+
+// rolePermissionSelectRows returns the rows of the role permissions matching the query options,
+// the counterpart of rolePermissionSelectQuery of the database store
+func (store *memoryStore) rolePermissionSelectRows(options RolePermissionQueryInterface) ([]map[string]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, newInvalidQueryError("RolePermissionQuery", err)
+	}
+
+	conditions := []memoryCondition{}
+
+	if options.HasPermissionID() {
+		conditions = append(conditions, memoryEq(COLUMN_PERMISSION_ID, options.PermissionID()))
+	}
+
+	if options.HasRoleID() {
+		conditions = append(conditions, memoryEq(COLUMN_ROLE_ID, options.RoleID()))
+	}
+
+	return store.selectRows(memoryTableRolePermission, options, conditions...)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+)
+
+// permissionsContextKey is the context key of the granted permissions
+type permissionsContextKey struct{}
+
+// Permissions are the permissions granted to the entity of an authorized request
+type Permissions struct {
+	EntityType string
+	EntityID   string
+
+	// Handles are the checked handles, which are granted to the entity.
+	// When middleware is nested, the handles of all of them are included
+	Handles []string
+}
+
+// Has checks whether the handle is in the granted handles
+func (p Permissions) Has(handle string) bool {
+	return slices.Contains(p.Handles, handle)
+}
+
+// PermissionsFromContext returns the permissions placed in the context by
+// the middleware, ok is false when the request has not been authorized
+func PermissionsFromContext(ctx context.Context) (permissions Permissions, ok bool) {
+	permissions, ok = ctx.Value(permissionsContextKey{}).(Permissions)
+	return permissions, ok
+}
+
+// withPermissions returns a context carrying the granted handles, merged
+// with the handles granted to the same entity by outer middleware
+func withPermissions(ctx context.Context, entityType string, entityID string, handles []string) context.Context {
+	permissions := Permissions{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Handles:    slices.Clone(handles),
+	}
+
+	if outer, ok := PermissionsFromContext(ctx); ok && outer.EntityType == entityType && outer.EntityID == entityID {
+		for _, handle := range outer.Handles {
+			if !permissions.Has(handle) {
+				permissions.Handles = append(permissions.Handles, handle)
+			}
+		}
+	}
+
+	return context.WithValue(ctx, permissionsContextKey{}, permissions)
+}
@@ -0,0 +1,168 @@
+// Package middleware provides net/http middleware, which authorizes requests
+// against a permission store.
+//
+// The entity, i.e. the user, of a request is resolved by an EntityResolver.
+// Requests without an entity are answered with 401 Unauthorized and requests
+// of entities lacking the required permissions with 403 Forbidden. Both
+// responses can be replaced with options.
+//
+// Authorized requests carry the granted permissions in their context, see
+// PermissionsFromContext.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gouniverse/permissionstore"
+	"github.com/samber/lo"
+)
+
+// EntityResolver returns the entity type and entity ID of the request,
+// ok is false when the request has no entity, i.e. is not authenticated
+type EntityResolver func(r *http.Request) (entityType string, entityID string, ok bool)
+
+// ErrorHandler writes the response for a failed permission check
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option configures the middleware
+type Option func(*config)
+
+// config is the configuration of a middleware
+type config struct {
+	unauthorized http.Handler
+	forbidden    http.Handler
+	errorHandler ErrorHandler
+}
+
+// WithUnauthorizedHandler sets the handler answering requests without an entity,
+// defaults to a plain text 401 Unauthorized
+func WithUnauthorizedHandler(handler http.Handler) Option {
+	return func(c *config) {
+		c.unauthorized = handler
+	}
+}
+
+// WithForbiddenHandler sets the handler answering requests of entities lacking
+// the required permissions, defaults to a plain text 403 Forbidden
+func WithForbiddenHandler(handler http.Handler) Option {
+	return func(c *config) {
+		c.forbidden = handler
+	}
+}
+
+// WithErrorHandler sets the handler answering requests, whose permission check
+// failed with an error, defaults to a plain text 500 Internal Server Error
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(c *config) {
+		c.errorHandler = handler
+	}
+}
+
+// RequirePermission returns middleware, which passes the request to the next
+// handler only when its entity is granted the permission with the given handle
+func RequirePermission(store permissionstore.StoreInterface, handle string, resolver EntityResolver, options ...Option) func(http.Handler) http.Handler {
+	return require(store, []string{handle}, resolver, requireAll, options)
+}
+
+// RequireAny returns middleware, which passes the request to the next handler
+// only when its entity is granted at least one of the permissions with the given handles
+func RequireAny(store permissionstore.StoreInterface, handles []string, resolver EntityResolver, options ...Option) func(http.Handler) http.Handler {
+	return require(store, handles, resolver, requireAny, options)
+}
+
+// RequireAll returns middleware, which passes the request to the next handler
+// only when its entity is granted every one of the permissions with the given handles
+func RequireAll(store permissionstore.StoreInterface, handles []string, resolver EntityResolver, options ...Option) func(http.Handler) http.Handler {
+	return require(store, handles, resolver, requireAll, options)
+}
+
+// requirement decides whether the granted handles satisfy the required handles
+type requirement func(required []string, granted []string) bool
+
+func requireAny(_ []string, granted []string) bool {
+	return len(granted) > 0
+}
+
+func requireAll(required []string, granted []string) bool {
+	return len(granted) == len(required)
+}
+
+func require(store permissionstore.StoreInterface, handles []string, resolver EntityResolver, satisfied requirement, options []Option) func(http.Handler) http.Handler {
+	if store == nil {
+		panic("permissionstore/middleware: store is nil")
+	}
+
+	if resolver == nil {
+		panic("permissionstore/middleware: resolver is nil")
+	}
+
+	if len(handles) < 1 || slices.Contains(handles, "") {
+		panic("permissionstore/middleware: handles must not be empty")
+	}
+
+	c := &config{
+		unauthorized: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}),
+		forbidden: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		}),
+		errorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		},
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	required := lo.Uniq(handles)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entityType, entityID, ok := resolver(r)
+
+			if !ok || entityType == "" || entityID == "" {
+				c.unauthorized.ServeHTTP(w, r)
+				return
+			}
+
+			granted, err := grantedHandles(r.Context(), store, entityType, entityID, required)
+
+			if err != nil {
+				c.errorHandler(w, r, err)
+				return
+			}
+
+			if !satisfied(required, granted) {
+				c.forbidden.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPermissions(r.Context(), entityType, entityID, granted)))
+		})
+	}
+}
+
+// grantedHandles returns the subset of the handles, which are granted to the entity.
+// Each handle is checked separately, so deny grants apply as in EntityHasPermission
+func grantedHandles(ctx context.Context, store permissionstore.StoreInterface, entityType string, entityID string, handles []string) ([]string, error) {
+	granted := []string{}
+
+	for _, handle := range handles {
+		allowed, err := store.EntityHasPermission(ctx, entityType, entityID, handle)
+
+		if err != nil {
+			return nil, fmt.Errorf("permissionstore/middleware: check of %s failed: %w", handle, err)
+		}
+
+		if allowed {
+			granted = append(granted, handle)
+		}
+	}
+
+	return granted, nil
+}
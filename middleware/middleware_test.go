@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gouniverse/permissionstore"
+	_ "modernc.org/sqlite"
+)
+
+func initStore(t *testing.T) permissionstore.StoreInterface {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	store, err := permissionstore.NewStore(permissionstore.NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return store
+}
+
+// initGrant creates the permission and grants it to the user
+func initGrant(t *testing.T, store permissionstore.StoreInterface, userID string, handle string) {
+	t.Helper()
+
+	permission, err := store.PermissionFindByHandle(context.Background(), handle)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if permission == nil {
+		permission = permissionstore.NewPermission().
+			SetHandle(handle).
+			SetTitle(handle).
+			SetStatus(permissionstore.PERMISSION_STATUS_ACTIVE)
+
+		if err := store.PermissionCreate(context.Background(), permission); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if userID == "" {
+		return
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), permissionstore.NewEntityPermission().
+		SetEntityType("user").
+		SetEntityID(userID).
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+// userResolver resolves the user from the X-User header
+func userResolver(r *http.Request) (string, string, bool) {
+	userID := r.Header.Get("X-User")
+	return "user", userID, userID != ""
+}
+
+// serve sends a request of the user through the middleware and returns the
+// response and the permissions seen by the next handler
+func serve(t *testing.T, middleware func(http.Handler) http.Handler, userID string) (*httptest.ResponseRecorder, Permissions, bool) {
+	t.Helper()
+
+	var permissions Permissions
+	var found bool
+
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permissions, found = PermissionsFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/reports", nil)
+
+	if userID != "" {
+		r.Header.Set("X-User", userID)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w, permissions, found
+}
+
+func TestRequirePermission(t *testing.T) {
+	store := initStore(t)
+	initGrant(t, store, "U1", "reports.view")
+	initGrant(t, store, "", "reports.edit")
+
+	view := RequirePermission(store, "reports.view", userResolver)
+
+	w, permissions, found := serve(t, view, "U1")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatal("granted user must pass, found:", w.Code)
+	}
+
+	if !found || permissions.EntityType != "user" || permissions.EntityID != "U1" || !permissions.Has("reports.view") {
+		t.Fatal("permissions must be in the context, found:", permissions)
+	}
+
+	if w, _, _ := serve(t, view, ""); w.Code != http.StatusUnauthorized {
+		t.Fatal("request without user must be unauthorized, found:", w.Code)
+	}
+
+	if w, _, _ := serve(t, view, "U2"); w.Code != http.StatusForbidden {
+		t.Fatal("user without grant must be forbidden, found:", w.Code)
+	}
+
+	if w, _, _ := serve(t, RequirePermission(store, "reports.edit", userResolver), "U1"); w.Code != http.StatusForbidden {
+		t.Fatal("user without grant of the handle must be forbidden, found:", w.Code)
+	}
+}
+
+func TestRequireAnyAndAll(t *testing.T) {
+	store := initStore(t)
+	initGrant(t, store, "U1", "reports.view")
+	initGrant(t, store, "U1", "reports.export")
+	initGrant(t, store, "", "reports.edit")
+
+	w, permissions, _ := serve(t, RequireAny(store, []string{"reports.edit", "reports.view"}, userResolver), "U1")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatal("user with one of the grants must pass RequireAny, found:", w.Code)
+	}
+
+	if len(permissions.Handles) != 1 || !permissions.Has("reports.view") {
+		t.Fatal("only the granted handles must be in the context, found:", permissions.Handles)
+	}
+
+	if w, _, _ := serve(t, RequireAny(store, []string{"reports.edit"}, userResolver), "U1"); w.Code != http.StatusForbidden {
+		t.Fatal("user without any of the grants must be forbidden, found:", w.Code)
+	}
+
+	if w, _, _ := serve(t, RequireAll(store, []string{"reports.view", "reports.edit"}, userResolver), "U1"); w.Code != http.StatusForbidden {
+		t.Fatal("user without all of the grants must be forbidden, found:", w.Code)
+	}
+
+	w, permissions, _ = serve(t, RequireAll(store, []string{"reports.view", "reports.export", "reports.view"}, userResolver), "U1")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatal("user with all of the grants must pass RequireAll, found:", w.Code)
+	}
+
+	if len(permissions.Handles) != 2 {
+		t.Fatal("both handles must be in the context, found:", permissions.Handles)
+	}
+}
+
+func TestNestedMiddlewareMergesPermissions(t *testing.T) {
+	store := initStore(t)
+	initGrant(t, store, "U1", "reports.view")
+	initGrant(t, store, "U1", "reports.export")
+
+	outer := RequirePermission(store, "reports.view", userResolver)
+	inner := RequirePermission(store, "reports.export", userResolver)
+
+	w, permissions, _ := serve(t, func(next http.Handler) http.Handler {
+		return outer(inner(next))
+	}, "U1")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatal("granted user must pass, found:", w.Code)
+	}
+
+	if !permissions.Has("reports.view") || !permissions.Has("reports.export") {
+		t.Fatal("handles of both middleware must be in the context, found:", permissions.Handles)
+	}
+}
+
+func TestCustomResponses(t *testing.T) {
+	store := initStore(t)
+	initGrant(t, store, "", "reports.view")
+
+	json := func(status int, body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	middleware := RequirePermission(store, "reports.view", userResolver,
+		WithUnauthorizedHandler(json(http.StatusUnauthorized, `{"error":"login required"}`)),
+		WithForbiddenHandler(json(http.StatusForbidden, `{"error":"not allowed"}`)))
+
+	if w, _, _ := serve(t, middleware, ""); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "login required") {
+		t.Fatal("custom unauthorized response expected, found:", w.Code, w.Body.String())
+	}
+
+	if w, _, _ := serve(t, middleware, "U1"); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "not allowed") {
+		t.Fatal("custom forbidden response expected, found:", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	store := initStore(t)
+
+	if err := store.DB().Close(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var handled error
+
+	middleware := RequirePermission(store, "reports.view", userResolver, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	w, _, _ := serve(t, middleware, "U1")
+
+	if w.Code != http.StatusServiceUnavailable || handled == nil {
+		t.Fatal("store error must be passed to the error handler, found:", w.Code, handled)
+	}
+}
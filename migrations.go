@@ -0,0 +1,282 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// Migration is the state of a single schema migration
+type Migration struct {
+	// Version is the version of the schema after the migration
+	Version int
+
+	// Description describes the migration
+	Description string
+
+	// Enabled is false for the migrations of optional features, i.e. roles or audit,
+	// which are not configured. These are skipped until the feature is configured
+	Enabled bool
+
+	// Applied is true if the migration has been applied
+	Applied bool
+
+	// AppliedAt is the time the migration has been applied, empty if not applied
+	AppliedAt string
+}
+
+// migration is a single up migration of the schema
+type migration struct {
+	version     int
+	description string
+
+	// enabled returns false for the migrations of optional features,
+	// which are not configured, nil for migrations which always apply
+	enabled func(store *store) bool
+
+	// up applies the migration. Migrations must be safe to apply to
+	// databases created before the versioned migrations were introduced
+	up func(ctx database.QueryableContext, store *store) error
+}
+
+// migrationsSupportedDialects are the dialects the migrations are built for
+var migrationsSupportedDialects = []string{
+	sb.DIALECT_MYSQL,
+	sb.DIALECT_POSTGRES,
+	sb.DIALECT_SQLITE,
+}
+
+// migrations returns the up migrations ordered by version.
+//
+// New migrations are appended with the next version, already released
+// migrations must never be changed or reordered.
+func migrations() []migration {
+	return []migration{
+		{
+			version:     1,
+			description: "create permission table",
+			up: func(ctx database.QueryableContext, store *store) error {
+				return migrationExec(ctx, store, store.sqlPermissionTableCreate())
+			},
+		},
+		{
+			version:     2,
+			description: "create entity permission table",
+			up: func(ctx database.QueryableContext, store *store) error {
+				return migrationExec(ctx, store, store.sqlEntityPermissionTableCreate())
+			},
+		},
+		{
+			version:     3,
+			description: "add resource, effect and validity window columns to entity permission table",
+			up: func(ctx database.QueryableContext, store *store) error {
+				return migrationColumnsAdd(ctx, store, store.entityPermissionTableName, []migrationColumn{
+					{column: sb.Column{Name: COLUMN_EFFECT, Type: sb.COLUMN_TYPE_STRING, Length: 20}, value: ENTITY_PERMISSION_EFFECT_ALLOW},
+					{column: sb.Column{Name: COLUMN_RESOURCE_TYPE, Type: sb.COLUMN_TYPE_STRING, Length: 80}, value: ""},
+					{column: sb.Column{Name: COLUMN_RESOURCE_ID, Type: sb.COLUMN_TYPE_STRING, Length: 40}, value: ""},
+					{column: sb.Column{Name: COLUMN_VALID_FROM, Type: sb.COLUMN_TYPE_DATETIME}, value: sb.NULL_DATETIME},
+					{column: sb.Column{Name: COLUMN_EXPIRES_AT, Type: sb.COLUMN_TYPE_DATETIME}, value: sb.MAX_DATETIME},
+				})
+			},
+		},
+		{
+			version:     4,
+			description: "create role, role permission and entity role tables",
+			enabled:     (*store).rolesEnabled,
+			up: func(ctx database.QueryableContext, store *store) error {
+				return migrationExec(ctx, store,
+					store.sqlRoleTableCreate(),
+					store.sqlRolePermissionTableCreate(),
+					store.sqlEntityRoleTableCreate(),
+				)
+			},
+		},
+		{
+			version:     5,
+			description: "create audit table",
+			enabled:     (*store).auditEnabled,
+			up: func(ctx database.QueryableContext, store *store) error {
+				return migrationExec(ctx, store, store.sqlAuditTableCreate())
+			},
+		},
+		{
+			version:     6,
+			description: "add unique and lookup indexes to permission and entity permission tables",
+			up: func(ctx database.QueryableContext, store *store) error {
+				err := migrationDuplicatesCheck(ctx, store, store.permissionTableName, permissionUniqueColumns, ErrDuplicateHandle)
+
+				if err != nil {
+					return err
+				}
+
+				err = migrationDuplicatesCheck(ctx, store, store.entityPermissionTableName, entityPermissionUniqueColumns, ErrDuplicateGrant)
+
+				if err != nil {
+					return err
+				}
+
+				sqlStrings := append(store.sqlPermissionIndexesCreate(), store.sqlEntityPermissionIndexesCreate()...)
+				return migrationExec(ctx, store, sqlStrings...)
+			},
+		},
+		{
+			version:     7,
+			description: "create entity membership table",
+			enabled:     (*store).membershipsEnabled,
+			up: func(ctx database.QueryableContext, store *store) error {
+				sqlStrings := append([]string{store.sqlEntityMembershipTableCreate()}, store.sqlEntityMembershipIndexesCreate()...)
+				return migrationExec(ctx, store, sqlStrings...)
+			},
+		},
+	}
+}
+
+// migrationColumn is a column added by a migration, with the value set on the existing rows
+type migrationColumn struct {
+	column sb.Column
+	value  string
+}
+
+// migrationExec executes the sql statements of a migration
+func migrationExec(ctx database.QueryableContext, store *store, sqlStrings ...string) error {
+	for _, sqlStr := range sqlStrings {
+		if sqlStr == "" {
+			return errors.New("permissionstore: migration sql is empty")
+		}
+
+		store.logSql("migrate", sqlStr)
+
+		if _, err := database.Execute(ctx, sqlStr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrationDuplicatesCheck returns a *MigrationConflictError listing the keys shared
+// by rows of the table, which are not soft deleted, as the unique index on the
+// columns cannot be created until these are resolved
+func migrationDuplicatesCheck(ctx database.QueryableContext, store *store, tableName string, columns []string, sentinel error) error {
+	selected := lo.Map(columns, func(column string, _ int) any {
+		return goqu.C(column)
+	})
+
+	order := lo.Map(columns, func(column string, _ int) exp.OrderedExpression {
+		return goqu.C(column).Asc()
+	})
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Prepared(true).
+		Select(selected...).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Eq(sb.MAX_DATETIME)).
+		GroupBy(selected...).
+		Having(goqu.COUNT(goqu.Star()).Gt(1)).
+		Order(order...).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	rows, err := database.SelectToMapString(ctx, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(rows) < 1 {
+		return nil
+	}
+
+	return &MigrationConflictError{
+		Table:   tableName,
+		Columns: columns,
+		Keys: lo.Map(rows, func(row map[string]string, _ int) []string {
+			return lo.Map(columns, func(column string, _ int) string {
+				return row[column]
+			})
+		}),
+		Err: sentinel,
+	}
+}
+
+// migrationColumnsAdd adds the columns missing from the table and sets their
+// value on the existing rows. The columns are added as nullable, as not all
+// dialects can add a required column to a table with rows.
+func migrationColumnsAdd(ctx database.QueryableContext, store *store, tableName string, columns []migrationColumn) error {
+	existing, err := store.tableColumnNames(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	builder := sb.NewBuilder(store.dbDriverName)
+
+	for _, column := range columns {
+		if lo.Contains(existing, column.column.Name) {
+			continue
+		}
+
+		column.column.Nullable = true
+
+		sqlStr, err := builder.TableColumnAdd(tableName, column.column)
+
+		if err != nil {
+			return err
+		}
+
+		if err := migrationExec(ctx, store, sqlStr); err != nil {
+			return err
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Update(tableName).
+			Prepared(true).
+			Set(goqu.Record{column.column.Name: column.value}).
+			Where(goqu.C(column.column.Name).IsNull()).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		store.logSql("migrate", sqlStr, params...)
+
+		if _, err := database.Execute(ctx, sqlStr, params...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableColumnNames returns the names of the columns of the table
+func (store *store) tableColumnNames(ctx context.Context, tableName string) ([]string, error) {
+	sqlStr, _, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Where(goqu.L("1 = 0")).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	rows, err := store.toQuerableContext(ctx).Queryable().QueryContext(ctx, sqlStr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return rows.Columns()
+}
@@ -0,0 +1,162 @@
+package permissionstore
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// permissionHandleSegmentRegex matches a single literal segment of a permission handle
+var permissionHandleSegmentRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// PermissionHandleValidate checks whether the handle follows the permission handle grammar.
+//
+// A handle is a dot separated list of segments, i.e. "billing.invoice.read".
+// Each segment consists of letters, digits, underscores and dashes.
+// The last segment may be the wildcard "*", which matches every descendant
+// handle, i.e. "billing.*" matches "billing.invoice" and "billing.invoice.read".
+// The handle "*" on its own matches every handle.
+func PermissionHandleValidate(handle string) error {
+	if handle == "" {
+		return errors.New("permission handle is empty")
+	}
+
+	if len(handle) > PERMISSION_HANDLE_MAX_LENGTH {
+		return errors.New("permission handle is longer than " + strconv.Itoa(PERMISSION_HANDLE_MAX_LENGTH) + " characters")
+	}
+
+	segments := strings.Split(handle, PERMISSION_HANDLE_SEPARATOR)
+
+	for index, segment := range segments {
+		if segment == PERMISSION_HANDLE_WILDCARD && index == len(segments)-1 {
+			continue
+		}
+
+		if segment == "" {
+			return errors.New("permission handle " + handle + " contains an empty segment")
+		}
+
+		if !permissionHandleSegmentRegex.MatchString(segment) {
+			return errors.New("permission handle " + handle + " contains an invalid segment " + segment)
+		}
+	}
+
+	return nil
+}
+
+// PermissionHandleIsWildcard returns true if the handle ends with the wildcard segment
+func PermissionHandleIsWildcard(handle string) bool {
+	return handle == PERMISSION_HANDLE_WILDCARD ||
+		strings.HasSuffix(handle, PERMISSION_HANDLE_SEPARATOR+PERMISSION_HANDLE_WILDCARD)
+}
+
+// PermissionHandleMatch returns true if the granted handle satisfies the requested handle.
+//
+// A handle matches itself. A wildcard handle matches every descendant handle,
+// i.e. "billing.*" matches "billing.invoice.read" and "billing.invoice.*",
+// but not "billing" itself.
+func PermissionHandleMatch(granted string, requested string) bool {
+	if granted == requested {
+		return true
+	}
+
+	if granted == PERMISSION_HANDLE_WILDCARD {
+		return requested != ""
+	}
+
+	if !PermissionHandleIsWildcard(granted) {
+		return false
+	}
+
+	prefix := strings.TrimSuffix(granted, PERMISSION_HANDLE_WILDCARD)
+
+	return strings.HasPrefix(requested, prefix) && len(requested) > len(prefix)
+}
+
+// permissionHandlePatterns returns the handles, which if granted satisfy the requested handle.
+// These are the handle itself and the wildcard handles of all its ancestors,
+// i.e. for "billing.invoice.read" these are "billing.invoice.read",
+// "billing.invoice.*", "billing.*" and "*".
+func permissionHandlePatterns(handle string) []string {
+	patterns := []string{handle}
+
+	segments := strings.Split(strings.TrimSuffix(handle, PERMISSION_HANDLE_SEPARATOR+PERMISSION_HANDLE_WILDCARD), PERMISSION_HANDLE_SEPARATOR)
+
+	if handle == PERMISSION_HANDLE_WILDCARD {
+		segments = []string{}
+	}
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		ancestor := strings.Join(segments[:i], PERMISSION_HANDLE_SEPARATOR)
+
+		pattern := PERMISSION_HANDLE_WILDCARD
+		if ancestor != "" {
+			pattern = ancestor + PERMISSION_HANDLE_SEPARATOR + PERMISSION_HANDLE_WILDCARD
+		}
+
+		if pattern != handle {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// PermissionHandleNode is a node of the permission handle tree.
+//
+// Every segment of a handle is a node, i.e. the handle "billing.invoice.read"
+// results in the nodes "billing", "billing.invoice" and "billing.invoice.read".
+type PermissionHandleNode struct {
+	// Segment is the last segment of the handle of the node, i.e. "invoice"
+	Segment string
+
+	// Handle is the full handle of the node, i.e. "billing.invoice"
+	Handle string
+
+	// Permission is the permission with the handle of the node, nil if there is none
+	Permission PermissionInterface
+
+	// Children are the child nodes, sorted by segment
+	Children []*PermissionHandleNode
+}
+
+// permissionHandleTreeBuild builds the handle tree of the given permissions
+// and returns its root nodes sorted by segment
+func permissionHandleTreeBuild(permissions []PermissionInterface) []*PermissionHandleNode {
+	root := &PermissionHandleNode{}
+	nodes := map[string]*PermissionHandleNode{"": root}
+
+	for _, permission := range permissions {
+		if permission.Handle() == "" {
+			continue
+		}
+
+		segments := strings.Split(permission.Handle(), PERMISSION_HANDLE_SEPARATOR)
+		parent := root
+
+		for index, segment := range segments {
+			handle := strings.Join(segments[:index+1], PERMISSION_HANDLE_SEPARATOR)
+			node, exists := nodes[handle]
+
+			if !exists {
+				node = &PermissionHandleNode{Segment: segment, Handle: handle}
+				nodes[handle] = node
+				parent.Children = append(parent.Children, node)
+			}
+
+			parent = node
+		}
+
+		parent.Permission = permission
+	}
+
+	for _, node := range nodes {
+		sort.Slice(node.Children, func(i, j int) bool {
+			return node.Children[i].Segment < node.Children[j].Segment
+		})
+	}
+
+	return root.Children
+}
@@ -0,0 +1,141 @@
+package permissionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPermissionHandleValidate(t *testing.T) {
+	valid := []string{
+		"PERMISSION_HANDLE",
+		"billing",
+		"billing.invoice.read",
+		"billing.invoice-line.read",
+		"billing.*",
+		"billing.invoice.*",
+		"*",
+	}
+
+	for _, handle := range valid {
+		if err := PermissionHandleValidate(handle); err != nil {
+			t.Fatal("handle", handle, "MUST be valid, got error:", err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		".billing",
+		"billing.",
+		"billing..read",
+		"billing.*.read",
+		"*.read",
+		"billing.in voice",
+		"billing.invoice*",
+		strings.Repeat("a", PERMISSION_HANDLE_MAX_LENGTH+1),
+	}
+
+	for _, handle := range invalid {
+		if err := PermissionHandleValidate(handle); err == nil {
+			t.Fatal("handle", handle, "MUST be invalid")
+		}
+	}
+}
+
+func TestPermissionHandleMatch(t *testing.T) {
+	matches := [][2]string{
+		{"billing.invoice.read", "billing.invoice.read"},
+		{"billing.invoice.*", "billing.invoice.read"},
+		{"billing.*", "billing.invoice.read"},
+		{"billing.*", "billing.invoice.*"},
+		{"billing.*", "billing.*"},
+		{"*", "billing.invoice.read"},
+		{"*", "*"},
+	}
+
+	for _, match := range matches {
+		if !PermissionHandleMatch(match[0], match[1]) {
+			t.Fatal(match[0], "MUST match", match[1])
+		}
+	}
+
+	mismatches := [][2]string{
+		{"billing.invoice.read", "billing.invoice.write"},
+		{"billing.invoice.read", "billing.invoice.*"},
+		{"billing.*", "billing"},
+		{"billing.*", "billings.invoice"},
+		{"billing.invoice.*", "billing.*"},
+		{"billing", "billing.invoice"},
+	}
+
+	for _, mismatch := range mismatches {
+		if PermissionHandleMatch(mismatch[0], mismatch[1]) {
+			t.Fatal(mismatch[0], "MUST NOT match", mismatch[1])
+		}
+	}
+}
+
+func TestPermissionHandlePatterns(t *testing.T) {
+	patterns := permissionHandlePatterns("billing.invoice.read")
+	expected := []string{"billing.invoice.read", "billing.invoice.*", "billing.*", "*"}
+
+	if strings.Join(patterns, ",") != strings.Join(expected, ",") {
+		t.Fatal("unexpected patterns:", patterns)
+	}
+
+	patterns = permissionHandlePatterns("billing.*")
+	expected = []string{"billing.*", "*"}
+
+	if strings.Join(patterns, ",") != strings.Join(expected, ",") {
+		t.Fatal("unexpected patterns:", patterns)
+	}
+
+	patterns = permissionHandlePatterns("*")
+	expected = []string{"*"}
+
+	if strings.Join(patterns, ",") != strings.Join(expected, ",") {
+		t.Fatal("unexpected patterns:", patterns)
+	}
+}
+
+func TestPermissionHandleTreeBuild(t *testing.T) {
+	permissions := []PermissionInterface{
+		NewPermission().SetHandle("billing.invoice.read"),
+		NewPermission().SetHandle("billing.*"),
+		NewPermission().SetHandle("audit"),
+		NewPermission().SetHandle("billing.invoice.write"),
+	}
+
+	tree := permissionHandleTreeBuild(permissions)
+
+	if len(tree) != 2 {
+		t.Fatal("unexpected root nodes:", len(tree))
+	}
+
+	if tree[0].Handle != "audit" || tree[0].Permission == nil {
+		t.Fatal("unexpected first root node:", tree[0].Handle)
+	}
+
+	billing := tree[1]
+
+	if billing.Handle != "billing" || billing.Permission != nil {
+		t.Fatal("unexpected second root node:", billing.Handle)
+	}
+
+	if len(billing.Children) != 2 {
+		t.Fatal("unexpected billing children:", len(billing.Children))
+	}
+
+	if billing.Children[0].Handle != "billing.*" || billing.Children[0].Permission == nil {
+		t.Fatal("unexpected billing child:", billing.Children[0].Handle)
+	}
+
+	invoice := billing.Children[1]
+
+	if invoice.Handle != "billing.invoice" || len(invoice.Children) != 2 {
+		t.Fatal("unexpected invoice node:", invoice.Handle)
+	}
+
+	if invoice.Children[0].Segment != "read" || invoice.Children[1].Segment != "write" {
+		t.Fatal("unexpected invoice children:", invoice.Children[0].Segment, invoice.Children[1].Segment)
+	}
+}
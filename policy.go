@@ -0,0 +1,109 @@
+package permissionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyDocument is the exported form of the permissions and the entity
+// permission grants. It does not depend on the table names or the IDs of
+// the store, the grants refer to the permissions by handle
+type PolicyDocument struct {
+	// Version is the version of the document format, see POLICY_DOCUMENT_VERSION
+	Version int `json:"version" yaml:"version"`
+
+	// Permissions are the permissions keyed by handle
+	Permissions map[string]PolicyPermission `json:"permissions" yaml:"permissions"`
+
+	// Grants are the entity permissions ordered by entity, permission and resource
+	Grants []PolicyGrant `json:"grants" yaml:"grants"`
+}
+
+// PolicyPermission is a permission of a policy document
+type PolicyPermission struct {
+	Status string            `json:"status" yaml:"status"`
+	Title  string            `json:"title" yaml:"title"`
+	Memo   string            `json:"memo,omitempty" yaml:"memo,omitempty"`
+	Metas  map[string]string `json:"metas,omitempty" yaml:"metas,omitempty"`
+}
+
+// PolicyGrant is an entity permission of a policy document. The validity
+// window is omitted, when the grant is not time-bounded
+type PolicyGrant struct {
+	EntityType   string            `json:"entity_type" yaml:"entity_type"`
+	EntityID     string            `json:"entity_id" yaml:"entity_id"`
+	Permission   string            `json:"permission" yaml:"permission"`
+	Effect       string            `json:"effect" yaml:"effect"`
+	ResourceType string            `json:"resource_type,omitempty" yaml:"resource_type,omitempty"`
+	ResourceID   string            `json:"resource_id,omitempty" yaml:"resource_id,omitempty"`
+	ValidFrom    string            `json:"valid_from,omitempty" yaml:"valid_from,omitempty"`
+	ExpiresAt    string            `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Memo         string            `json:"memo,omitempty" yaml:"memo,omitempty"`
+	Metas        map[string]string `json:"metas,omitempty" yaml:"metas,omitempty"`
+}
+
+// key returns the key of the unique entity, permission and resource combination
+func (g PolicyGrant) key() string {
+	return g.EntityType + "\x00" + g.EntityID + "\x00" + g.Permission + "\x00" + g.ResourceType + "\x00" + g.ResourceID
+}
+
+// policyGrantsSort orders the grants by entity, permission and resource
+func policyGrantsSort(grants []PolicyGrant) {
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].key() < grants[j].key()
+	})
+}
+
+// policyDocumentEncode writes the document in the format
+func policyDocumentEncode(w io.Writer, document PolicyDocument, format string) error {
+	switch format {
+	case POLICY_FORMAT_JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case POLICY_FORMAT_YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	}
+
+	return errors.New("format must be json or yaml")
+}
+
+// policyDocumentDecode reads a document in the format and checks its version
+func policyDocumentDecode(r io.Reader, format string) (PolicyDocument, error) {
+	document := PolicyDocument{}
+
+	switch format {
+	case POLICY_FORMAT_JSON:
+		if err := json.NewDecoder(r).Decode(&document); err != nil {
+			return document, err
+		}
+	case POLICY_FORMAT_YAML:
+		if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+			return document, err
+		}
+	default:
+		return document, errors.New("format must be json or yaml")
+	}
+
+	if document.Version != POLICY_DOCUMENT_VERSION {
+		return document, errors.New("unsupported document version " + strconv.Itoa(document.Version))
+	}
+
+	if document.Permissions == nil {
+		document.Permissions = map[string]PolicyPermission{}
+	}
+
+	return document, nil
+}
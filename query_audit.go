@@ -0,0 +1,353 @@
+package permissionstore
+
+import "errors"
+
+type AuditQueryInterface interface {
+	Validate() error
+
+	Columns() []string
+	SetColumns(columns []string) AuditQueryInterface
+
+	HasCountOnly() bool
+	IsCountOnly() bool
+	SetCountOnly(countOnly bool) AuditQueryInterface
+
+	HasActor() bool
+	Actor() string
+	SetActor(actor string) AuditQueryInterface
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+	SetCreatedAtGte(createdAtGte string) AuditQueryInterface
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+	SetCreatedAtLte(createdAtLte string) AuditQueryInterface
+
+	HasID() bool
+	ID() string
+	SetID(id string) AuditQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) AuditQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) AuditQueryInterface
+
+	HasOperation() bool
+	Operation() string
+	SetOperation(operation string) AuditQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) AuditQueryInterface
+
+	HasRowID() bool
+	RowID() string
+	SetRowID(rowID string) AuditQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) AuditQueryInterface
+
+	HasTableName() bool
+	TableName() string
+	SetTableName(tableName string) AuditQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewAuditQuery() AuditQueryInterface {
+	return &auditQueryImplementation{
+		properties: make(map[string]any),
+	}
+}
+
+type auditQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *auditQueryImplementation) Validate() error {
+	if c.HasActor() && c.Actor() == "" {
+		return errors.New("audit query. actor cannot be empty")
+	}
+
+	if c.HasCreatedAtGte() && c.CreatedAtGte() == "" {
+		return errors.New("audit query. created_at_gte cannot be empty")
+	}
+
+	if c.HasCreatedAtLte() && c.CreatedAtLte() == "" {
+		return errors.New("audit query. created_at_lte cannot be empty")
+	}
+
+	if c.HasID() && c.ID() == "" {
+		return errors.New("audit query. id cannot be empty")
+	}
+
+	if c.HasOperation() && c.Operation() == "" {
+		return errors.New("audit query. operation cannot be empty")
+	}
+
+	if c.HasOrderBy() && c.OrderBy() == "" {
+		return errors.New("audit query. order_by cannot be empty")
+	}
+
+	if c.HasRowID() && c.RowID() == "" {
+		return errors.New("audit query. row_id cannot be empty")
+	}
+
+	if c.HasSortDirection() && c.SortDirection() == "" {
+		return errors.New("audit query. sort_direction cannot be empty")
+	}
+
+	if c.HasTableName() && c.TableName() == "" {
+		return errors.New("audit query. table_name cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("audit query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("audit query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *auditQueryImplementation) Columns() []string {
+	if !c.hasProperty("columns") {
+		return []string{}
+	}
+
+	return c.properties["columns"].([]string)
+}
+
+func (c *auditQueryImplementation) SetColumns(columns []string) AuditQueryInterface {
+	c.properties["columns"] = columns
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasCountOnly() bool {
+	return c.hasProperty("count_only")
+}
+
+func (c *auditQueryImplementation) IsCountOnly() bool {
+	if !c.HasCountOnly() {
+		return false
+	}
+
+	return c.properties["count_only"].(bool)
+}
+
+func (c *auditQueryImplementation) SetCountOnly(countOnly bool) AuditQueryInterface {
+	c.properties["count_only"] = countOnly
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasActor() bool {
+	return c.hasProperty("actor")
+}
+
+func (c *auditQueryImplementation) Actor() string {
+	if !c.HasActor() {
+		return ""
+	}
+
+	return c.properties["actor"].(string)
+}
+
+func (c *auditQueryImplementation) SetActor(actor string) AuditQueryInterface {
+	c.properties["actor"] = actor
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasCreatedAtGte() bool {
+	return c.hasProperty("created_at_gte")
+}
+
+func (c *auditQueryImplementation) CreatedAtGte() string {
+	if !c.HasCreatedAtGte() {
+		return ""
+	}
+
+	return c.properties["created_at_gte"].(string)
+}
+
+func (c *auditQueryImplementation) SetCreatedAtGte(createdAtGte string) AuditQueryInterface {
+	c.properties["created_at_gte"] = createdAtGte
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasCreatedAtLte() bool {
+	return c.hasProperty("created_at_lte")
+}
+
+func (c *auditQueryImplementation) CreatedAtLte() string {
+	if !c.HasCreatedAtLte() {
+		return ""
+	}
+
+	return c.properties["created_at_lte"].(string)
+}
+
+func (c *auditQueryImplementation) SetCreatedAtLte(createdAtLte string) AuditQueryInterface {
+	c.properties["created_at_lte"] = createdAtLte
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *auditQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *auditQueryImplementation) SetID(id string) AuditQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *auditQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *auditQueryImplementation) SetLimit(limit int) AuditQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *auditQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *auditQueryImplementation) SetOffset(offset int) AuditQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasOperation() bool {
+	return c.hasProperty("operation")
+}
+
+func (c *auditQueryImplementation) Operation() string {
+	if !c.HasOperation() {
+		return ""
+	}
+
+	return c.properties["operation"].(string)
+}
+
+func (c *auditQueryImplementation) SetOperation(operation string) AuditQueryInterface {
+	c.properties["operation"] = operation
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasOrderBy() bool {
+	return c.hasProperty("order_by")
+}
+
+func (c *auditQueryImplementation) OrderBy() string {
+	if !c.HasOrderBy() {
+		return ""
+	}
+
+	return c.properties["order_by"].(string)
+}
+
+func (c *auditQueryImplementation) SetOrderBy(orderBy string) AuditQueryInterface {
+	c.properties["order_by"] = orderBy
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasRowID() bool {
+	return c.hasProperty("row_id")
+}
+
+func (c *auditQueryImplementation) RowID() string {
+	if !c.HasRowID() {
+		return ""
+	}
+
+	return c.properties["row_id"].(string)
+}
+
+func (c *auditQueryImplementation) SetRowID(rowID string) AuditQueryInterface {
+	c.properties["row_id"] = rowID
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *auditQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *auditQueryImplementation) SetSortDirection(sortDirection string) AuditQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *auditQueryImplementation) HasTableName() bool {
+	return c.hasProperty("table_name")
+}
+
+func (c *auditQueryImplementation) TableName() string {
+	if !c.HasTableName() {
+		return ""
+	}
+
+	return c.properties["table_name"].(string)
+}
+
+func (c *auditQueryImplementation) SetTableName(tableName string) AuditQueryInterface {
+	c.properties["table_name"] = tableName
+
+	return c
+}
+
+func (c *auditQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
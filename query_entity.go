@@ -1,6 +1,10 @@
 package permissionstore
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/samber/lo"
+)
 
 type EntityPermissionQueryInterface interface {
 	Validate() error
@@ -20,6 +24,10 @@ type EntityPermissionQueryInterface interface {
 	CreatedAtLte() string
 	SetCreatedAtLte(createdAtLte string) EntityPermissionQueryInterface
 
+	HasEffect() bool
+	Effect() string
+	SetEffect(effect string) EntityPermissionQueryInterface
+
 	HasEntityID() bool
 	EntityID() string
 	SetEntityID(entityID string) EntityPermissionQueryInterface
@@ -52,6 +60,18 @@ type EntityPermissionQueryInterface interface {
 	PermissionID() string
 	SetPermissionID(permissionID string) EntityPermissionQueryInterface
 
+	// HasResourceID and SetResourceID filter by resource ID,
+	// an empty resource ID matches the global (not resource scoped) mappings
+	HasResourceID() bool
+	ResourceID() string
+	SetResourceID(resourceID string) EntityPermissionQueryInterface
+
+	// HasResourceType and SetResourceType filter by resource type,
+	// an empty resource type matches the global (not resource scoped) mappings
+	HasResourceType() bool
+	ResourceType() string
+	SetResourceType(resourceType string) EntityPermissionQueryInterface
+
 	HasSortDirection() bool
 	SortDirection() string
 	SetSortDirection(sortDirection string) EntityPermissionQueryInterface
@@ -60,6 +80,13 @@ type EntityPermissionQueryInterface interface {
 	SoftDeletedIncluded() bool
 	SetSoftDeletedIncluded(softDeletedIncluded bool) EntityPermissionQueryInterface
 
+	// HasValidity and SetValidity filter by the validity window of the mappings,
+	// one of ENTITY_PERMISSION_VALIDITY_ACTIVE, ENTITY_PERMISSION_VALIDITY_EXPIRED
+	// or ENTITY_PERMISSION_VALIDITY_PENDING
+	HasValidity() bool
+	Validity() string
+	SetValidity(validity string) EntityPermissionQueryInterface
+
 	hasProperty(name string) bool
 }
 
@@ -82,6 +109,10 @@ func (c *permissionEntityQueryImplementation) Validate() error {
 		return errors.New("permission query. created_at_lte cannot be empty")
 	}
 
+	if c.HasEffect() && c.Effect() == "" {
+		return errors.New("permission query. effect cannot be empty")
+	}
+
 	if c.HasEntityID() && c.EntityID() == "" {
 		return errors.New("permission query. entity_id cannot be empty")
 	}
@@ -106,6 +137,14 @@ func (c *permissionEntityQueryImplementation) Validate() error {
 		return errors.New("permission query. sort_direction cannot be empty")
 	}
 
+	if c.HasValidity() && !lo.Contains([]string{
+		ENTITY_PERMISSION_VALIDITY_ACTIVE,
+		ENTITY_PERMISSION_VALIDITY_EXPIRED,
+		ENTITY_PERMISSION_VALIDITY_PENDING,
+	}, c.Validity()) {
+		return errors.New("permission query. validity must be one of active, expired or pending")
+	}
+
 	if c.HasLimit() && c.Limit() <= 0 {
 		return errors.New("permission query. limit must be greater than 0")
 	}
@@ -185,6 +224,24 @@ func (c *permissionEntityQueryImplementation) SetCreatedAtLte(createdAtLte strin
 	return c
 }
 
+func (c *permissionEntityQueryImplementation) HasEffect() bool {
+	return c.hasProperty("effect")
+}
+
+func (c *permissionEntityQueryImplementation) Effect() string {
+	if !c.HasEffect() {
+		return ""
+	}
+
+	return c.properties["effect"].(string)
+}
+
+func (c *permissionEntityQueryImplementation) SetEffect(effect string) EntityPermissionQueryInterface {
+	c.properties["effect"] = effect
+
+	return c
+}
+
 func (c *permissionEntityQueryImplementation) HasEntityType() bool {
 	return c.hasProperty("entity_type")
 }
@@ -329,6 +386,42 @@ func (c *permissionEntityQueryImplementation) SetPermissionID(permissionID strin
 	return c
 }
 
+func (c *permissionEntityQueryImplementation) HasResourceID() bool {
+	return c.hasProperty("resource_id")
+}
+
+func (c *permissionEntityQueryImplementation) ResourceID() string {
+	if !c.HasResourceID() {
+		return ""
+	}
+
+	return c.properties["resource_id"].(string)
+}
+
+func (c *permissionEntityQueryImplementation) SetResourceID(resourceID string) EntityPermissionQueryInterface {
+	c.properties["resource_id"] = resourceID
+
+	return c
+}
+
+func (c *permissionEntityQueryImplementation) HasResourceType() bool {
+	return c.hasProperty("resource_type")
+}
+
+func (c *permissionEntityQueryImplementation) ResourceType() string {
+	if !c.HasResourceType() {
+		return ""
+	}
+
+	return c.properties["resource_type"].(string)
+}
+
+func (c *permissionEntityQueryImplementation) SetResourceType(resourceType string) EntityPermissionQueryInterface {
+	c.properties["resource_type"] = resourceType
+
+	return c
+}
+
 func (c *permissionEntityQueryImplementation) HasSortDirection() bool {
 	return c.hasProperty("sort_direction")
 }
@@ -383,6 +476,24 @@ func (c *permissionEntityQueryImplementation) SetTitleLike(titleLike string) Ent
 	return c
 }
 
+func (c *permissionEntityQueryImplementation) HasValidity() bool {
+	return c.hasProperty("validity")
+}
+
+func (c *permissionEntityQueryImplementation) Validity() string {
+	if !c.HasValidity() {
+		return ""
+	}
+
+	return c.properties["validity"].(string)
+}
+
+func (c *permissionEntityQueryImplementation) SetValidity(validity string) EntityPermissionQueryInterface {
+	c.properties["validity"] = validity
+
+	return c
+}
+
 func (c *permissionEntityQueryImplementation) hasProperty(name string) bool {
 	_, ok := c.properties[name]
 	return ok
@@ -0,0 +1,401 @@
+package permissionstore
+
+import "errors"
+
+type EntityMembershipQueryInterface interface {
+	Validate() error
+
+	Columns() []string
+	SetColumns(columns []string) EntityMembershipQueryInterface
+
+	HasCountOnly() bool
+	IsCountOnly() bool
+	SetCountOnly(countOnly bool) EntityMembershipQueryInterface
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+	SetCreatedAtGte(createdAtGte string) EntityMembershipQueryInterface
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+	SetCreatedAtLte(createdAtLte string) EntityMembershipQueryInterface
+
+	HasMemberID() bool
+	MemberID() string
+	SetMemberID(memberID string) EntityMembershipQueryInterface
+
+	HasMemberType() bool
+	MemberType() string
+	SetMemberType(memberType string) EntityMembershipQueryInterface
+
+	HasID() bool
+	ID() string
+	SetID(id string) EntityMembershipQueryInterface
+
+	HasIDIn() bool
+	IDIn() []string
+	SetIDIn(idIn []string) EntityMembershipQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) EntityMembershipQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) EntityMembershipQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) EntityMembershipQueryInterface
+
+	HasParentID() bool
+	ParentID() string
+	SetParentID(parentID string) EntityMembershipQueryInterface
+
+	HasParentType() bool
+	ParentType() string
+	SetParentType(parentType string) EntityMembershipQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) EntityMembershipQueryInterface
+
+	HasSoftDeletedIncluded() bool
+	SoftDeletedIncluded() bool
+	SetSoftDeletedIncluded(softDeletedIncluded bool) EntityMembershipQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewEntityMembershipQuery() EntityMembershipQueryInterface {
+	return &entityMembershipQueryImplementation{
+		properties: make(map[string]any),
+	}
+}
+
+type entityMembershipQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *entityMembershipQueryImplementation) Validate() error {
+	if c.HasCreatedAtGte() && c.CreatedAtGte() == "" {
+		return errors.New("entity membership query. created_at_gte cannot be empty")
+	}
+
+	if c.HasCreatedAtLte() && c.CreatedAtLte() == "" {
+		return errors.New("entity membership query. created_at_lte cannot be empty")
+	}
+
+	if c.HasMemberID() && c.MemberID() == "" {
+		return errors.New("entity membership query. member_id cannot be empty")
+	}
+
+	if c.HasMemberType() && c.MemberType() == "" {
+		return errors.New("entity membership query. member_type cannot be empty")
+	}
+
+	if c.HasID() && c.ID() == "" {
+		return errors.New("entity membership query. id cannot be empty")
+	}
+
+	if c.HasIDIn() && len(c.IDIn()) == 0 {
+		return errors.New("entity membership query. id_in cannot be empty")
+	}
+
+	if c.HasParentID() && c.ParentID() == "" {
+		return errors.New("entity membership query. parent_id cannot be empty")
+	}
+
+	if c.HasParentType() && c.ParentType() == "" {
+		return errors.New("entity membership query. parent_type cannot be empty")
+	}
+
+	if c.HasOrderBy() && c.OrderBy() == "" {
+		return errors.New("entity membership query. order_by cannot be empty")
+	}
+
+	if c.HasSortDirection() && c.SortDirection() == "" {
+		return errors.New("entity membership query. sort_direction cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("entity membership query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("entity membership query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *entityMembershipQueryImplementation) Columns() []string {
+	if !c.hasProperty("columns") {
+		return []string{}
+	}
+
+	return c.properties["columns"].([]string)
+}
+
+func (c *entityMembershipQueryImplementation) SetColumns(columns []string) EntityMembershipQueryInterface {
+	c.properties["columns"] = columns
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasCountOnly() bool {
+	return c.hasProperty("count_only")
+}
+
+func (c *entityMembershipQueryImplementation) IsCountOnly() bool {
+	if !c.HasCountOnly() {
+		return false
+	}
+
+	return c.properties["count_only"].(bool)
+}
+
+func (c *entityMembershipQueryImplementation) SetCountOnly(countOnly bool) EntityMembershipQueryInterface {
+	c.properties["count_only"] = countOnly
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasCreatedAtGte() bool {
+	return c.hasProperty("created_at_gte")
+}
+
+func (c *entityMembershipQueryImplementation) CreatedAtGte() string {
+	if !c.HasCreatedAtGte() {
+		return ""
+	}
+
+	return c.properties["created_at_gte"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetCreatedAtGte(createdAtGte string) EntityMembershipQueryInterface {
+	c.properties["created_at_gte"] = createdAtGte
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasCreatedAtLte() bool {
+	return c.hasProperty("created_at_lte")
+}
+
+func (c *entityMembershipQueryImplementation) CreatedAtLte() string {
+	if !c.HasCreatedAtLte() {
+		return ""
+	}
+
+	return c.properties["created_at_lte"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetCreatedAtLte(createdAtLte string) EntityMembershipQueryInterface {
+	c.properties["created_at_lte"] = createdAtLte
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasMemberType() bool {
+	return c.hasProperty("member_type")
+}
+
+func (c *entityMembershipQueryImplementation) MemberType() string {
+	if !c.HasMemberType() {
+		return ""
+	}
+
+	return c.properties["member_type"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetMemberType(memberType string) EntityMembershipQueryInterface {
+	c.properties["member_type"] = memberType
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasMemberID() bool {
+	return c.hasProperty("member_id")
+}
+
+func (c *entityMembershipQueryImplementation) MemberID() string {
+	if !c.HasMemberID() {
+		return ""
+	}
+
+	return c.properties["member_id"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetMemberID(memberID string) EntityMembershipQueryInterface {
+	c.properties["member_id"] = memberID
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *entityMembershipQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetID(id string) EntityMembershipQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasIDIn() bool {
+	return c.hasProperty("id_in")
+}
+
+func (c *entityMembershipQueryImplementation) IDIn() []string {
+	if !c.HasIDIn() {
+		return []string{}
+	}
+
+	return c.properties["id_in"].([]string)
+}
+
+func (c *entityMembershipQueryImplementation) SetIDIn(idIn []string) EntityMembershipQueryInterface {
+	c.properties["id_in"] = idIn
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *entityMembershipQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *entityMembershipQueryImplementation) SetLimit(limit int) EntityMembershipQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *entityMembershipQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *entityMembershipQueryImplementation) SetOffset(offset int) EntityMembershipQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasOrderBy() bool {
+	return c.hasProperty("order_by")
+}
+
+func (c *entityMembershipQueryImplementation) OrderBy() string {
+	if !c.HasOrderBy() {
+		return ""
+	}
+
+	return c.properties["order_by"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetOrderBy(orderBy string) EntityMembershipQueryInterface {
+	c.properties["order_by"] = orderBy
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasParentID() bool {
+	return c.hasProperty("parent_id")
+}
+
+func (c *entityMembershipQueryImplementation) ParentID() string {
+	if !c.HasParentID() {
+		return ""
+	}
+
+	return c.properties["parent_id"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetParentID(parentID string) EntityMembershipQueryInterface {
+	c.properties["parent_id"] = parentID
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasParentType() bool {
+	return c.hasProperty("parent_type")
+}
+
+func (c *entityMembershipQueryImplementation) ParentType() string {
+	if !c.HasParentType() {
+		return ""
+	}
+
+	return c.properties["parent_type"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetParentType(parentType string) EntityMembershipQueryInterface {
+	c.properties["parent_type"] = parentType
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *entityMembershipQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *entityMembershipQueryImplementation) SetSortDirection(sortDirection string) EntityMembershipQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) HasSoftDeletedIncluded() bool {
+	return c.hasProperty("soft_deleted_included")
+}
+
+func (c *entityMembershipQueryImplementation) SoftDeletedIncluded() bool {
+	if !c.HasSoftDeletedIncluded() {
+		return false
+	}
+
+	return c.properties["soft_deleted_included"].(bool)
+}
+
+func (c *entityMembershipQueryImplementation) SetSoftDeletedIncluded(softDeletedIncluded bool) EntityMembershipQueryInterface {
+	c.properties["soft_deleted_included"] = softDeletedIncluded
+
+	return c
+}
+
+func (c *entityMembershipQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
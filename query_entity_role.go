@@ -0,0 +1,371 @@
+package permissionstore
+
+import "errors"
+
+type EntityRoleQueryInterface interface {
+	Validate() error
+
+	Columns() []string
+	SetColumns(columns []string) EntityRoleQueryInterface
+
+	HasCountOnly() bool
+	IsCountOnly() bool
+	SetCountOnly(countOnly bool) EntityRoleQueryInterface
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+	SetCreatedAtGte(createdAtGte string) EntityRoleQueryInterface
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+	SetCreatedAtLte(createdAtLte string) EntityRoleQueryInterface
+
+	HasEntityID() bool
+	EntityID() string
+	SetEntityID(entityID string) EntityRoleQueryInterface
+
+	HasEntityType() bool
+	EntityType() string
+	SetEntityType(entityType string) EntityRoleQueryInterface
+
+	HasID() bool
+	ID() string
+	SetID(id string) EntityRoleQueryInterface
+
+	HasIDIn() bool
+	IDIn() []string
+	SetIDIn(idIn []string) EntityRoleQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) EntityRoleQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) EntityRoleQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) EntityRoleQueryInterface
+
+	HasRoleID() bool
+	RoleID() string
+	SetRoleID(roleID string) EntityRoleQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) EntityRoleQueryInterface
+
+	HasSoftDeletedIncluded() bool
+	SoftDeletedIncluded() bool
+	SetSoftDeletedIncluded(softDeletedIncluded bool) EntityRoleQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewEntityRoleQuery() EntityRoleQueryInterface {
+	return &entityRoleQueryImplementation{
+		properties: make(map[string]any),
+	}
+}
+
+type entityRoleQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *entityRoleQueryImplementation) Validate() error {
+	if c.HasCreatedAtGte() && c.CreatedAtGte() == "" {
+		return errors.New("entity role query. created_at_gte cannot be empty")
+	}
+
+	if c.HasCreatedAtLte() && c.CreatedAtLte() == "" {
+		return errors.New("entity role query. created_at_lte cannot be empty")
+	}
+
+	if c.HasEntityID() && c.EntityID() == "" {
+		return errors.New("entity role query. entity_id cannot be empty")
+	}
+
+	if c.HasEntityType() && c.EntityType() == "" {
+		return errors.New("entity role query. entity_type cannot be empty")
+	}
+
+	if c.HasID() && c.ID() == "" {
+		return errors.New("entity role query. id cannot be empty")
+	}
+
+	if c.HasIDIn() && len(c.IDIn()) == 0 {
+		return errors.New("entity role query. id_in cannot be empty")
+	}
+
+	if c.HasOrderBy() && c.OrderBy() == "" {
+		return errors.New("entity role query. order_by cannot be empty")
+	}
+
+	if c.HasSortDirection() && c.SortDirection() == "" {
+		return errors.New("entity role query. sort_direction cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("entity role query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("entity role query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *entityRoleQueryImplementation) Columns() []string {
+	if !c.hasProperty("columns") {
+		return []string{}
+	}
+
+	return c.properties["columns"].([]string)
+}
+
+func (c *entityRoleQueryImplementation) SetColumns(columns []string) EntityRoleQueryInterface {
+	c.properties["columns"] = columns
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasCountOnly() bool {
+	return c.hasProperty("count_only")
+}
+
+func (c *entityRoleQueryImplementation) IsCountOnly() bool {
+	if !c.HasCountOnly() {
+		return false
+	}
+
+	return c.properties["count_only"].(bool)
+}
+
+func (c *entityRoleQueryImplementation) SetCountOnly(countOnly bool) EntityRoleQueryInterface {
+	c.properties["count_only"] = countOnly
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasCreatedAtGte() bool {
+	return c.hasProperty("created_at_gte")
+}
+
+func (c *entityRoleQueryImplementation) CreatedAtGte() string {
+	if !c.HasCreatedAtGte() {
+		return ""
+	}
+
+	return c.properties["created_at_gte"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetCreatedAtGte(createdAtGte string) EntityRoleQueryInterface {
+	c.properties["created_at_gte"] = createdAtGte
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasCreatedAtLte() bool {
+	return c.hasProperty("created_at_lte")
+}
+
+func (c *entityRoleQueryImplementation) CreatedAtLte() string {
+	if !c.HasCreatedAtLte() {
+		return ""
+	}
+
+	return c.properties["created_at_lte"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetCreatedAtLte(createdAtLte string) EntityRoleQueryInterface {
+	c.properties["created_at_lte"] = createdAtLte
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasEntityType() bool {
+	return c.hasProperty("entity_type")
+}
+
+func (c *entityRoleQueryImplementation) EntityType() string {
+	if !c.HasEntityType() {
+		return ""
+	}
+
+	return c.properties["entity_type"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetEntityType(entityType string) EntityRoleQueryInterface {
+	c.properties["entity_type"] = entityType
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasEntityID() bool {
+	return c.hasProperty("entity_id")
+}
+
+func (c *entityRoleQueryImplementation) EntityID() string {
+	if !c.HasEntityID() {
+		return ""
+	}
+
+	return c.properties["entity_id"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetEntityID(entityID string) EntityRoleQueryInterface {
+	c.properties["entity_id"] = entityID
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *entityRoleQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetID(id string) EntityRoleQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasIDIn() bool {
+	return c.hasProperty("id_in")
+}
+
+func (c *entityRoleQueryImplementation) IDIn() []string {
+	if !c.HasIDIn() {
+		return []string{}
+	}
+
+	return c.properties["id_in"].([]string)
+}
+
+func (c *entityRoleQueryImplementation) SetIDIn(idIn []string) EntityRoleQueryInterface {
+	c.properties["id_in"] = idIn
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *entityRoleQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *entityRoleQueryImplementation) SetLimit(limit int) EntityRoleQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *entityRoleQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *entityRoleQueryImplementation) SetOffset(offset int) EntityRoleQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasOrderBy() bool {
+	return c.hasProperty("order_by")
+}
+
+func (c *entityRoleQueryImplementation) OrderBy() string {
+	if !c.HasOrderBy() {
+		return ""
+	}
+
+	return c.properties["order_by"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetOrderBy(orderBy string) EntityRoleQueryInterface {
+	c.properties["order_by"] = orderBy
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasRoleID() bool {
+	return c.hasProperty("role_id")
+}
+
+func (c *entityRoleQueryImplementation) RoleID() string {
+	if !c.HasRoleID() {
+		return ""
+	}
+
+	return c.properties["role_id"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetRoleID(roleID string) EntityRoleQueryInterface {
+	c.properties["role_id"] = roleID
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *entityRoleQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *entityRoleQueryImplementation) SetSortDirection(sortDirection string) EntityRoleQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) HasSoftDeletedIncluded() bool {
+	return c.hasProperty("soft_deleted_included")
+}
+
+func (c *entityRoleQueryImplementation) SoftDeletedIncluded() bool {
+	if !c.HasSoftDeletedIncluded() {
+		return false
+	}
+
+	return c.properties["soft_deleted_included"].(bool)
+}
+
+func (c *entityRoleQueryImplementation) SetSoftDeletedIncluded(softDeletedIncluded bool) EntityRoleQueryInterface {
+	c.properties["soft_deleted_included"] = softDeletedIncluded
+
+	return c
+}
+
+func (c *entityRoleQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
@@ -24,6 +24,10 @@ type PermissionQueryInterface interface {
 	Handle() string
 	SetHandle(handle string) PermissionQueryInterface
 
+	HasHandleIn() bool
+	HandleIn() []string
+	SetHandleIn(handleIn []string) PermissionQueryInterface
+
 	HasID() bool
 	ID() string
 	SetID(id string) PermissionQueryInterface
@@ -86,6 +90,10 @@ func (c *permissionQueryImplementation) Validate() error {
 		return errors.New("permission query. id_in cannot be empty")
 	}
 
+	if c.HasHandleIn() && len(c.HandleIn()) == 0 {
+		return errors.New("permission query. handle_in cannot be empty")
+	}
+
 	if c.HasStatus() && c.Status() == "" {
 		return errors.New("permission query. status cannot be empty")
 	}
@@ -203,6 +211,24 @@ func (c *permissionQueryImplementation) SetHandle(handle string) PermissionQuery
 	return c
 }
 
+func (c *permissionQueryImplementation) HasHandleIn() bool {
+	return c.hasProperty("handle_in")
+}
+
+func (c *permissionQueryImplementation) HandleIn() []string {
+	if !c.HasHandleIn() {
+		return []string{}
+	}
+
+	return c.properties["handle_in"].([]string)
+}
+
+func (c *permissionQueryImplementation) SetHandleIn(handleIn []string) PermissionQueryInterface {
+	c.properties["handle_in"] = handleIn
+
+	return c
+}
+
 func (c *permissionQueryImplementation) ID() string {
 	if !c.HasID() {
 		return ""
@@ -0,0 +1,349 @@
+package permissionstore
+
+import "errors"
+
+type RolePermissionQueryInterface interface {
+	Validate() error
+
+	Columns() []string
+	SetColumns(columns []string) RolePermissionQueryInterface
+
+	HasCountOnly() bool
+	IsCountOnly() bool
+	SetCountOnly(countOnly bool) RolePermissionQueryInterface
+
+	HasCreatedAtGte() bool
+	CreatedAtGte() string
+	SetCreatedAtGte(createdAtGte string) RolePermissionQueryInterface
+
+	HasCreatedAtLte() bool
+	CreatedAtLte() string
+	SetCreatedAtLte(createdAtLte string) RolePermissionQueryInterface
+
+	HasID() bool
+	ID() string
+	SetID(id string) RolePermissionQueryInterface
+
+	HasIDIn() bool
+	IDIn() []string
+	SetIDIn(idIn []string) RolePermissionQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) RolePermissionQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) RolePermissionQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) RolePermissionQueryInterface
+
+	HasPermissionID() bool
+	PermissionID() string
+	SetPermissionID(permissionID string) RolePermissionQueryInterface
+
+	HasRoleID() bool
+	RoleID() string
+	SetRoleID(roleID string) RolePermissionQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) RolePermissionQueryInterface
+
+	HasSoftDeletedIncluded() bool
+	SoftDeletedIncluded() bool
+	SetSoftDeletedIncluded(softDeletedIncluded bool) RolePermissionQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewRolePermissionQuery() RolePermissionQueryInterface {
+	return &rolePermissionQueryImplementation{
+		properties: make(map[string]any),
+	}
+}
+
+type rolePermissionQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *rolePermissionQueryImplementation) Validate() error {
+	if c.HasCreatedAtGte() && c.CreatedAtGte() == "" {
+		return errors.New("role permission query. created_at_gte cannot be empty")
+	}
+
+	if c.HasCreatedAtLte() && c.CreatedAtLte() == "" {
+		return errors.New("role permission query. created_at_lte cannot be empty")
+	}
+
+	if c.HasID() && c.ID() == "" {
+		return errors.New("role permission query. id cannot be empty")
+	}
+
+	if c.HasIDIn() && len(c.IDIn()) == 0 {
+		return errors.New("role permission query. id_in cannot be empty")
+	}
+
+	if c.HasPermissionID() && c.PermissionID() == "" {
+		return errors.New("role permission query. permission_id cannot be empty")
+	}
+
+	if c.HasRoleID() && c.RoleID() == "" {
+		return errors.New("role permission query. role_id cannot be empty")
+	}
+
+	if c.HasOrderBy() && c.OrderBy() == "" {
+		return errors.New("role permission query. order_by cannot be empty")
+	}
+
+	if c.HasSortDirection() && c.SortDirection() == "" {
+		return errors.New("role permission query. sort_direction cannot be empty")
+	}
+
+	if c.HasLimit() && c.Limit() <= 0 {
+		return errors.New("role permission query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("role permission query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *rolePermissionQueryImplementation) Columns() []string {
+	if !c.hasProperty("columns") {
+		return []string{}
+	}
+
+	return c.properties["columns"].([]string)
+}
+
+func (c *rolePermissionQueryImplementation) SetColumns(columns []string) RolePermissionQueryInterface {
+	c.properties["columns"] = columns
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasCountOnly() bool {
+	return c.hasProperty("count_only")
+}
+
+func (c *rolePermissionQueryImplementation) IsCountOnly() bool {
+	if !c.HasCountOnly() {
+		return false
+	}
+
+	return c.properties["count_only"].(bool)
+}
+
+func (c *rolePermissionQueryImplementation) SetCountOnly(countOnly bool) RolePermissionQueryInterface {
+	c.properties["count_only"] = countOnly
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasCreatedAtGte() bool {
+	return c.hasProperty("created_at_gte")
+}
+
+func (c *rolePermissionQueryImplementation) CreatedAtGte() string {
+	if !c.HasCreatedAtGte() {
+		return ""
+	}
+
+	return c.properties["created_at_gte"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetCreatedAtGte(createdAtGte string) RolePermissionQueryInterface {
+	c.properties["created_at_gte"] = createdAtGte
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasCreatedAtLte() bool {
+	return c.hasProperty("created_at_lte")
+}
+
+func (c *rolePermissionQueryImplementation) CreatedAtLte() string {
+	if !c.HasCreatedAtLte() {
+		return ""
+	}
+
+	return c.properties["created_at_lte"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetCreatedAtLte(createdAtLte string) RolePermissionQueryInterface {
+	c.properties["created_at_lte"] = createdAtLte
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *rolePermissionQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetID(id string) RolePermissionQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasIDIn() bool {
+	return c.hasProperty("id_in")
+}
+
+func (c *rolePermissionQueryImplementation) IDIn() []string {
+	if !c.HasIDIn() {
+		return []string{}
+	}
+
+	return c.properties["id_in"].([]string)
+}
+
+func (c *rolePermissionQueryImplementation) SetIDIn(idIn []string) RolePermissionQueryInterface {
+	c.properties["id_in"] = idIn
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *rolePermissionQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *rolePermissionQueryImplementation) SetLimit(limit int) RolePermissionQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *rolePermissionQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *rolePermissionQueryImplementation) SetOffset(offset int) RolePermissionQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasOrderBy() bool {
+	return c.hasProperty("order_by")
+}
+
+func (c *rolePermissionQueryImplementation) OrderBy() string {
+	if !c.HasOrderBy() {
+		return ""
+	}
+
+	return c.properties["order_by"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetOrderBy(orderBy string) RolePermissionQueryInterface {
+	c.properties["order_by"] = orderBy
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasPermissionID() bool {
+	return c.hasProperty("permission_id")
+}
+
+func (c *rolePermissionQueryImplementation) PermissionID() string {
+	if !c.HasPermissionID() {
+		return ""
+	}
+
+	return c.properties["permission_id"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetPermissionID(permissionID string) RolePermissionQueryInterface {
+	c.properties["permission_id"] = permissionID
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasRoleID() bool {
+	return c.hasProperty("role_id")
+}
+
+func (c *rolePermissionQueryImplementation) RoleID() string {
+	if !c.HasRoleID() {
+		return ""
+	}
+
+	return c.properties["role_id"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetRoleID(roleID string) RolePermissionQueryInterface {
+	c.properties["role_id"] = roleID
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *rolePermissionQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *rolePermissionQueryImplementation) SetSortDirection(sortDirection string) RolePermissionQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) HasSoftDeletedIncluded() bool {
+	return c.hasProperty("soft_deleted_included")
+}
+
+func (c *rolePermissionQueryImplementation) SoftDeletedIncluded() bool {
+	if !c.HasSoftDeletedIncluded() {
+		return false
+	}
+
+	return c.properties["soft_deleted_included"].(bool)
+}
+
+func (c *rolePermissionQueryImplementation) SetSoftDeletedIncluded(softDeletedIncluded bool) RolePermissionQueryInterface {
+	c.properties["soft_deleted_included"] = softDeletedIncluded
+
+	return c
+}
+
+func (c *rolePermissionQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
@@ -1,12 +1,15 @@
 package permissionstore
 
 import (
+	"strings"
+
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // sqlPermissionTableCreate returns a SQL string for creating the permission table
 func (st *store) sqlPermissionTableCreate() string {
-	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+	sql := sb.NewBuilder(st.dbDriverName).
 		Table(st.permissionTableName).
 		Column(sb.Column{
 			Name:       COLUMN_ID,
@@ -22,7 +25,7 @@ func (st *store) sqlPermissionTableCreate() string {
 		Column(sb.Column{
 			Name:   COLUMN_HANDLE,
 			Type:   sb.COLUMN_TYPE_STRING,
-			Length: 50,
+			Length: PERMISSION_HANDLE_MAX_LENGTH,
 		}).
 		Column(sb.Column{
 			Name:   COLUMN_TITLE,
@@ -59,7 +62,7 @@ func (st *store) sqlPermissionTableCreate() string {
 
 // sqlEntityPermissionTableCreate returns a SQL string for creating the  entity to permission relation table
 func (st *store) sqlEntityPermissionTableCreate() string {
-	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+	sql := sb.NewBuilder(st.dbDriverName).
 		Table(st.entityPermissionTableName).
 		Column(sb.Column{
 			Name:       COLUMN_ID,
@@ -82,6 +85,31 @@ func (st *store) sqlEntityPermissionTableCreate() string {
 			Type:   sb.COLUMN_TYPE_STRING,
 			Length: 40,
 		}).
+		Column(sb.Column{
+			Name:   COLUMN_EFFECT,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 20,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_RESOURCE_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_RESOURCE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_VALID_FROM,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EXPIRES_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
 		Column(sb.Column{
 			Name: COLUMN_METAS,
 			Type: sb.COLUMN_TYPE_TEXT,
@@ -109,3 +137,375 @@ func (st *store) sqlEntityPermissionTableCreate() string {
 
 	return sql
 }
+
+// sqlRoleTableCreate returns a SQL string for creating the role table
+func (st *store) sqlRoleTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.roleTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_HANDLE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 50,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TITLE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_METAS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlRolePermissionTableCreate returns a SQL string for creating the role to permission relation table
+func (st *store) sqlRolePermissionTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.rolePermissionTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_PERMISSION_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_METAS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlEntityRoleTableCreate returns a SQL string for creating the entity to role relation table
+func (st *store) sqlEntityRoleTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.entityRoleTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_METAS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlEntityMembershipTableCreate returns a SQL string for creating the member entity to parent entity relation table
+func (st *store) sqlEntityMembershipTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.entityMembershipTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_MEMBER_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_MEMBER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_PARENT_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_PARENT_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_METAS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlAuditTableCreate returns a SQL string for creating the audit log table
+func (st *store) sqlAuditTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.auditTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_OPERATION,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 20,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TABLE_NAME,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROW_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DATA_BEFORE,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DATA_AFTER,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ACTOR,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlMigrationTableCreate returns a SQL string for creating the schema version table
+func (st *store) sqlMigrationTableCreate() string {
+	sql := sb.NewBuilder(st.dbDriverName).
+		Table(st.migrationTableName).
+		Column(sb.Column{
+			Name:       COLUMN_VERSION,
+			Type:       sb.COLUMN_TYPE_INTEGER,
+			PrimaryKey: true,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DESCRIPTION,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 255,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_APPLIED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// permissionUniqueColumns are the columns of the unique index of the permission table
+var permissionUniqueColumns = []string{COLUMN_HANDLE}
+
+// entityPermissionUniqueColumns are the columns of the unique index of the
+// entity to permission relation table
+var entityPermissionUniqueColumns = []string{
+	COLUMN_ENTITY_TYPE,
+	COLUMN_ENTITY_ID,
+	COLUMN_PERMISSION_ID,
+	COLUMN_RESOURCE_TYPE,
+	COLUMN_RESOURCE_ID,
+}
+
+// sqlPermissionIndexesCreate returns the SQL strings for creating the indexes
+// of the permission table
+func (st *store) sqlPermissionIndexesCreate() []string {
+	return []string{
+		st.sqlUniqueIndexCreate(st.permissionTableName+"_handle_unique", st.permissionTableName, permissionUniqueColumns...),
+		sb.NewBuilder(st.dbDriverName).
+			Table(st.permissionTableName).
+			CreateIndex(st.permissionTableName+"_soft_deleted_at_index", COLUMN_SOFT_DELETED_AT),
+	}
+}
+
+// sqlEntityPermissionIndexesCreate returns the SQL strings for creating the indexes
+// of the entity to permission relation table. The unique index is led by the
+// entity columns, so it also serves the lookups by entity
+func (st *store) sqlEntityPermissionIndexesCreate() []string {
+	return []string{
+		st.sqlUniqueIndexCreate(
+			st.entityPermissionTableName+"_grant_unique",
+			st.entityPermissionTableName,
+			entityPermissionUniqueColumns...,
+		),
+		sb.NewBuilder(st.dbDriverName).
+			Table(st.entityPermissionTableName).
+			CreateIndex(st.entityPermissionTableName+"_permission_id_index", COLUMN_PERMISSION_ID),
+		sb.NewBuilder(st.dbDriverName).
+			Table(st.entityPermissionTableName).
+			CreateIndex(st.entityPermissionTableName+"_soft_deleted_at_index", COLUMN_SOFT_DELETED_AT),
+	}
+}
+
+// sqlEntityMembershipIndexesCreate returns the SQL strings for creating the indexes
+// of the member entity to parent entity relation table, used to walk from the
+// members to their parents
+func (st *store) sqlEntityMembershipIndexesCreate() []string {
+	return []string{
+		sb.NewBuilder(st.dbDriverName).
+			Table(st.entityMembershipTableName).
+			CreateIndex(st.entityMembershipTableName+"_member_index", COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID),
+	}
+}
+
+// sqlUniqueIndexCreate returns a SQL string for creating a unique index on the
+// columns of the rows, which are not soft deleted.
+//
+// SQLite and PostgreSQL support partial indexes, so soft deleted rows are left out.
+// MySQL does not, so the soft deleted at column is added to the index instead,
+// as the rows which are not soft deleted all share the same maximum date.
+func (st *store) sqlUniqueIndexCreate(indexName string, tableName string, columns ...string) string {
+	dialect := st.dbDriverName
+
+	quote := func(name string) string {
+		if dialect == sb.DIALECT_MYSQL {
+			return "`" + name + "`"
+		}
+
+		return `"` + name + `"`
+	}
+
+	if dialect == sb.DIALECT_MYSQL {
+		columns = append(columns, COLUMN_SOFT_DELETED_AT)
+	}
+
+	quoted := lo.Map(columns, func(column string, _ int) string {
+		return quote(column)
+	})
+
+	sql := "CREATE UNIQUE INDEX " + quote(indexName) + " ON " + quote(tableName) + " (" + strings.Join(quoted, ", ") + ")"
+
+	if dialect != sb.DIALECT_MYSQL {
+		sql += " WHERE " + quote(COLUMN_SOFT_DELETED_AT) + " = '" + sb.MAX_DATETIME + "'"
+	}
+
+	return sql + ";"
+}
@@ -3,10 +3,12 @@ package permissionstore
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"log/slog"
 
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
 )
 
 // == TYPE ====================================================================
@@ -18,6 +20,30 @@ type store struct {
 	// entityPermissionTableName is the name of the permission entity relation table
 	entityPermissionTableName string
 
+	// roleTableName is the name of the role table
+	roleTableName string
+
+	// rolePermissionTableName is the name of the role to permission relation table
+	rolePermissionTableName string
+
+	// entityRoleTableName is the name of the entity to role relation table
+	entityRoleTableName string
+
+	// entityMembershipTableName is the name of the member entity to parent entity relation table
+	entityMembershipTableName string
+
+	// membershipMaxDepth is the maximum number of memberships followed from an entity to its ancestors
+	membershipMaxDepth int
+
+	// auditTableName is the name of the audit log table
+	auditTableName string
+
+	// migrationTableName is the name of the schema version table
+	migrationTableName string
+
+	// cache is the effective permission cache, nil when caching is disabled
+	cache *grantCache
+
 	// db is the underlying database connection
 	db *sql.DB
 
@@ -40,37 +66,26 @@ var _ StoreInterface = (*store)(nil) // verify it extends the interface
 
 // PUBLIC METHODS ============================================================
 
-// AutoMigrate auto-migrates the database schema
+// AutoMigrate auto-migrates the database schema,
+// applying all pending migrations, see MigrateTo
 func (store *store) AutoMigrate() error {
 	if store.db == nil {
-		return errors.New("permissionstore: database is nil")
-	}
-
-	sqlStr := store.sqlPermissionTableCreate()
-
-	if sqlStr == "" {
-		return errors.New("permissionstore: permission table create sql is empty")
+		return ErrNilDatabase
 	}
 
-	_, err := store.db.Exec(sqlStr)
+	all := migrations()
 
-	if err != nil {
-		return err
-	}
-
-	sqlStr = store.sqlEntityPermissionTableCreate()
-
-	if sqlStr == "" {
-		return errors.New("permissionstore: entity permission table create sql is empty")
-	}
-
-	_, err = store.db.Exec(sqlStr)
+	return store.MigrateTo(context.Background(), all[len(all)-1].version)
+}
 
-	if err != nil {
-		return err
+// CacheStats returns the statistics of the effective permission cache,
+// zero statistics when the cache is disabled
+func (store *store) CacheStats() CacheStats {
+	if store.cache == nil {
+		return CacheStats{}
 	}
 
-	return nil
+	return store.cache.statistics()
 }
 
 // DB returns the underlying database connection
@@ -83,6 +98,66 @@ func (st *store) EnableDebug(debug bool) {
 	st.debugEnabled = debug
 }
 
+// auditEnabled returns true if the audit table is configured
+func (store *store) auditEnabled() bool {
+	return store.auditTableName != ""
+}
+
+// rolesEnabled returns true if the role tables are configured
+func (store *store) rolesEnabled() bool {
+	return store.roleTableName != "" &&
+		store.rolePermissionTableName != "" &&
+		store.entityRoleTableName != ""
+}
+
+// membershipsEnabled returns true if the entity membership table is configured
+func (store *store) membershipsEnabled() bool {
+	return store.entityMembershipTableName != ""
+}
+
+// cacheInvalidate removes the effective permissions of the entity from the cache.
+// Inside a transaction started by WithTransaction the entity is removed again
+// after the commit, as concurrent readers may have cached the state before it.
+//
+// When memberships are enabled the whole cache is flushed instead, as the
+// entity may be the ancestor of other entities inheriting its permissions
+func (store *store) cacheInvalidate(ctx context.Context, entityType string, entityID string) {
+	if store.cache == nil {
+		return
+	}
+
+	if store.membershipsEnabled() {
+		store.cacheFlush(ctx)
+		return
+	}
+
+	store.cache.invalidate(entityType, entityID)
+
+	if scope := transactionScopeFromContext(ctx); scope != nil {
+		scope.pending.invalidate(entityType, entityID)
+	}
+}
+
+// cacheFlush removes the effective permissions of all entities from the cache,
+// used when a change, i.e. to a permission or a role, may affect any entity.
+// Inside a transaction started by WithTransaction the cache is flushed again after the commit
+func (store *store) cacheFlush(ctx context.Context) {
+	if store.cache == nil {
+		return
+	}
+
+	store.cache.flush()
+
+	if scope := transactionScopeFromContext(ctx); scope != nil {
+		scope.pending.flush()
+	}
+}
+
+// inTransaction returns true if the context carries a database transaction
+func (store *store) inTransaction(ctx context.Context) bool {
+	return database.IsQueryableContext(ctx) && ctx.(database.QueryableContext).IsTx()
+}
+
 // logSql logs sql to the sql logger, if debug mode is enabled
 func (store *store) logSql(sqlOperationType string, sql string, params ...interface{}) {
 	if !store.debugEnabled {
@@ -94,6 +169,16 @@ func (store *store) logSql(sqlOperationType string, sql string, params ...interf
 	}
 }
 
+// titleLike returns the case insensitive match of the title against the pattern.
+// Only PostgreSQL has ILIKE, LIKE is case insensitive in MySQL and SQLite already
+func (store *store) titleLike(pattern string) exp.BooleanExpression {
+	if store.dbDriverName == sb.DIALECT_POSTGRES {
+		return goqu.C(COLUMN_TITLE).ILike(pattern)
+	}
+
+	return goqu.C(COLUMN_TITLE).Like(pattern)
+}
+
 // toQuerableContext converts the context to a QueryableContext
 func (store *store) toQuerableContext(ctx context.Context) database.QueryableContext {
 	if database.IsQueryableContext(ctx) {
@@ -0,0 +1,319 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/utils"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// actorContextKey is the context key of the actor recorded in the audit log
+type actorContextKey struct{}
+
+// WithActor returns a context carrying the actor, i.e. the ID of the user
+// making the changes, to be recorded in the audit log.
+//
+// When the context carries a database transaction the returned context
+// carries the same transaction.
+func WithActor(ctx context.Context, actor string) context.Context {
+	if database.IsQueryableContext(ctx) {
+		qc := ctx.(database.QueryableContext)
+		return database.Context(context.WithValue(qc.Context, actorContextKey{}, actor), qc.Queryable())
+	}
+
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by the context, empty if none
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// AuditCount returns the number of audit records matching the query
+func (store *store) AuditCount(ctx context.Context, options AuditQueryInterface) (int64, error) {
+	if options == nil {
+		return -1, errors.New("at audit count > audit query is nil")
+	}
+
+	options.SetCountOnly(true)
+
+	q, _, err := store.auditSelectQuery(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		Limit(1).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return -1, nil
+	}
+
+	return strconv.ParseInt(mapped[0]["count"], 10, 64)
+}
+
+// AuditList returns the audit records matching the query
+func (store *store) AuditList(ctx context.Context, query AuditQueryInterface) ([]AuditRecordInterface, error) {
+	if query == nil {
+		return []AuditRecordInterface{}, errors.New("at audit list > audit query is nil")
+	}
+
+	q, columns, err := store.auditSelectQuery(query)
+
+	if err != nil {
+		return []AuditRecordInterface{}, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+	if errSql != nil {
+		return []AuditRecordInterface{}, errSql
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return []AuditRecordInterface{}, ErrNilDatabase
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []AuditRecordInterface{}, err
+	}
+
+	list := []AuditRecordInterface{}
+
+	lo.ForEach(modelMaps, func(modelMap map[string]string, index int) {
+		list = append(list, NewAuditRecordFromExistingData(modelMap))
+	})
+
+	return list, nil
+}
+
+func (store *store) auditSelectQuery(options AuditQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
+	if options == nil {
+		return nil, nil, errors.New("audit options is nil")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, nil, newInvalidQueryError("AuditQuery", err)
+	}
+
+	if !store.auditEnabled() {
+		return nil, nil, errors.New("permissionstore: audit is not enabled")
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.auditTableName)
+
+	if options.HasActor() {
+		q = q.Where(goqu.C(COLUMN_ACTOR).Eq(options.Actor()))
+	}
+
+	if options.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(options.ID()))
+	}
+
+	if options.HasOperation() {
+		q = q.Where(goqu.C(COLUMN_OPERATION).Eq(options.Operation()))
+	}
+
+	if options.HasRowID() {
+		q = q.Where(goqu.C(COLUMN_ROW_ID).Eq(options.RowID()))
+	}
+
+	if options.HasTableName() {
+		q = q.Where(goqu.C(COLUMN_TABLE_NAME).Eq(options.TableName()))
+	}
+
+	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
+		q = q.Where(
+			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
+			goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
+		)
+	} else if options.HasCreatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
+	} else if options.HasCreatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+	}
+
+	if !options.IsCountOnly() {
+		if options.HasLimit() {
+			q = q.Limit(cast.ToUint(options.Limit()))
+		}
+
+		if options.HasOffset() {
+			q = q.Offset(cast.ToUint(options.Offset()))
+		}
+	}
+
+	if options.HasOrderBy() {
+		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
+		if strings.EqualFold(sort, sb.ASC) {
+			q = q.Order(goqu.I(options.OrderBy()).Asc())
+		} else {
+			q = q.Order(goqu.I(options.OrderBy()).Desc())
+		}
+	}
+
+	columns = []any{}
+
+	for _, column := range options.Columns() {
+		columns = append(columns, column)
+	}
+
+	return q, columns, nil
+}
+
+// executeAudited executes the mutating sql statement on the row with the given ID.
+//
+// When the audit is enabled, the statement and the audit record are executed
+// in the same transaction. The data before the change is read from the row,
+// for updates only the changed columns are recorded.
+func (store *store) executeAudited(ctx context.Context, operation string, tableName string, rowID string, dataAfter map[string]string, sqlStr string, params ...any) error {
+	if !store.auditEnabled() {
+		_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+		return err
+	}
+
+	return store.transaction(ctx, func(txCtx database.QueryableContext) error {
+		dataBefore := map[string]string{}
+
+		if operation != AUDIT_OPERATION_CREATE {
+			row, err := store.auditRowData(txCtx, tableName, rowID)
+
+			if err != nil {
+				return err
+			}
+
+			if row == nil {
+				// nothing to change, nothing to record
+				_, err = database.Execute(txCtx, sqlStr, params...)
+				return err
+			}
+
+			dataBefore = row
+
+			if operation != AUDIT_OPERATION_DELETE {
+				dataBefore = lo.PickByKeys(row, lo.Keys(dataAfter))
+			}
+		}
+
+		if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+			return err
+		}
+
+		return store.auditRecordCreate(txCtx, operation, tableName, rowID, dataBefore, dataAfter)
+	})
+}
+
+// auditRecordCreate appends a record to the audit log, the actor is taken from the context
+func (store *store) auditRecordCreate(ctx database.QueryableContext, operation string, tableName string, rowID string, dataBefore map[string]string, dataAfter map[string]string) error {
+	dataBeforeStr, err := auditDataToJSON(dataBefore)
+
+	if err != nil {
+		return err
+	}
+
+	dataAfterStr, err := auditDataToJSON(dataAfter)
+
+	if err != nil {
+		return err
+	}
+
+	record := NewAuditRecord().
+		SetOperation(operation).
+		SetTableName(tableName).
+		SetRowID(rowID).
+		SetDataBefore(dataBeforeStr).
+		SetDataAfter(dataAfterStr).
+		SetActor(ActorFromContext(ctx))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.auditTableName).
+		Prepared(true).
+		Rows(record.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	_, err = database.Execute(ctx, sqlStr, params...)
+
+	return err
+}
+
+// auditRowData returns the current data of the row with the given ID, nil if there is no such row
+func (store *store) auditRowData(ctx database.QueryableContext, tableName string, rowID string) (map[string]string, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(tableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(rowID)).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	rows, err := database.SelectToMapString(ctx, sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) < 1 {
+		return nil, nil
+	}
+
+	return rows[0], nil
+}
+
+// auditUpdateOperation returns the audit operation of an update with the given changes,
+// updates setting the soft deleted timestamp are recorded as soft deletes
+func auditUpdateOperation(dataChanged map[string]string) string {
+	softDeletedAt, changed := dataChanged[COLUMN_SOFT_DELETED_AT]
+
+	if changed && softDeletedAt != sb.MAX_DATETIME {
+		return AUDIT_OPERATION_SOFT_DELETE
+	}
+
+	return AUDIT_OPERATION_UPDATE
+}
+
+// auditDataToJSON encodes the row data as json, empty data encodes to empty string
+func auditDataToJSON(data map[string]string) (string, error) {
+	if len(data) < 1 {
+		return "", nil
+	}
+
+	return utils.ToJSON(data)
+}
@@ -0,0 +1,297 @@
+package permissionstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gouniverse/base/database"
+)
+
+func TestStoreAuditList(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := WithActor(context.Background(), "ADMIN_01")
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles")
+
+	err = store.PermissionCreate(ctx, permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permission.SetTitle("Read all articles")
+
+	err = store.PermissionUpdate(ctx, permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionSoftDelete(ctx, permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionDelete(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	records, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetTableName("permissions_permission_table").
+		SetRowID(permission.ID()).
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortDirection("asc"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatal("expected 4 audit records, found:", len(records))
+	}
+
+	expected := []struct {
+		operation string
+		actor     string
+	}{
+		{AUDIT_OPERATION_CREATE, "ADMIN_01"},
+		{AUDIT_OPERATION_UPDATE, "ADMIN_01"},
+		{AUDIT_OPERATION_SOFT_DELETE, "ADMIN_01"},
+		{AUDIT_OPERATION_DELETE, ""},
+	}
+
+	// records created within the same second have no defined order
+	for _, e := range expected {
+		found := false
+
+		for _, record := range records {
+			if record.Operation() == e.operation && record.Actor() == e.actor {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("audit record not found:", e.operation, e.actor)
+		}
+	}
+
+	for _, record := range records {
+		before, err := record.DataBeforeMap()
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		after, err := record.DataAfterMap()
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		switch record.Operation() {
+		case AUDIT_OPERATION_CREATE:
+			if len(before) != 0 || after[COLUMN_TITLE] != "Read articles" {
+				t.Fatal("unexpected create data:", before, after)
+			}
+		case AUDIT_OPERATION_UPDATE:
+			if before[COLUMN_TITLE] != "Read articles" || after[COLUMN_TITLE] != "Read all articles" {
+				t.Fatal("unexpected update data:", before, after)
+			}
+
+			if _, exists := after[COLUMN_HANDLE]; exists {
+				t.Fatal("update MUST record the changed columns only:", after)
+			}
+		case AUDIT_OPERATION_DELETE:
+			if before[COLUMN_HANDLE] != "articles.read" || len(after) != 0 {
+				t.Fatal("unexpected delete data:", before, after)
+			}
+		}
+	}
+
+	count, err := store.AuditCount(context.Background(), NewAuditQuery().
+		SetActor("ADMIN_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Fatal("expected 3 audit records of ADMIN_01, found:", count)
+	}
+}
+
+func TestStoreAuditList_EntityPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := WithActor(context.Background(), "ADMIN_01")
+
+	entityPermission := NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.EntityPermissionCreate(ctx, entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityPermissionDeleteByID(ctx, entityPermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	records, err := store.AuditList(context.Background(), NewAuditQuery().
+		SetTableName("permissions_entity_permission_table").
+		SetOperation(AUDIT_OPERATION_DELETE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatal("expected 1 audit record, found:", len(records))
+	}
+
+	before, err := records[0].DataBeforeMap()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if before[COLUMN_ENTITY_ID] != "USER_01" {
+		t.Fatal("unexpected data before:", before)
+	}
+
+	if records[0].Actor() != "ADMIN_01" {
+		t.Fatal("unexpected actor:", records[0].Actor())
+	}
+}
+
+func TestStoreAudit_Transaction(t *testing.T) {
+	store, err := initStore(filepath.Join(t.TempDir(), "test_store_audit_transaction.db"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tx, err := store.DB().Begin()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	txCtx := WithActor(database.Context(context.Background(), tx), "ADMIN_01")
+
+	if !database.IsQueryableContext(txCtx) {
+		t.Fatal("WithActor MUST keep the transaction of the context")
+	}
+
+	err = store.PermissionCreate(txCtx, NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err := store.AuditCount(txCtx, NewAuditQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("expected 1 audit record within the transaction, found:", count)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err = store.AuditCount(context.Background(), NewAuditQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("audit record MUST be rolled back with the change, found:", count)
+	}
+}
+
+func TestStoreAuditDisabled(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.AuditList(context.Background(), NewAuditQuery())
+
+	if err == nil {
+		t.Fatal("must return error as audit is not enabled")
+	}
+}
@@ -0,0 +1,335 @@
+package permissionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func initStoreWithCache(t *testing.T, filepath string) StoreInterface {
+	t.Helper()
+
+	db, err := initDB(filepath)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		RoleTableName:             "permissions_role_table",
+		RolePermissionTableName:   "permissions_role_permission_table",
+		EntityRoleTableName:       "permissions_entity_role_table",
+		CacheSize:                 10,
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return store
+}
+
+func cacheTestHas(t *testing.T, store StoreInterface, entityID string, handle string, expected bool) {
+	t.Helper()
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", entityID, handle)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has != expected {
+		t.Fatal(entityID, handle, "expected", expected, "got", has)
+	}
+}
+
+func TestStoreCache(t *testing.T) {
+	store := initStoreWithCache(t, ":memory:")
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if (store.CacheStats() != CacheStats{}) {
+		t.Fatal("new cache MUST have zero stats")
+	}
+
+	permission, entityPermission := checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+	cacheTestHas(t, store, "USER_01", "articles.write", false)
+
+	stats := store.CacheStats()
+
+	if stats.Misses != 1 || stats.Hits != 2 || stats.Size != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// granting to USER_01 invalidates USER_01
+	checkTestGrant(t, store, "USER", "USER_01", "articles.write", PERMISSION_STATUS_ACTIVE)
+	cacheTestHas(t, store, "USER_01", "articles.write", true)
+
+	// soft deleting a grant invalidates its entity
+	err := store.EntityPermissionSoftDelete(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_01", "articles.read", false)
+
+	// deleting a grant by ID invalidates its entity
+	_, writeGrant := checkTestGrant(t, store, "USER", "USER_02", "articles.publish", PERMISSION_STATUS_ACTIVE)
+	cacheTestHas(t, store, "USER_02", "articles.publish", true)
+
+	err = store.EntityPermissionDeleteByID(context.Background(), writeGrant.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_02", "articles.publish", false)
+
+	// updating a permission flushes the cache
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_03").
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_03", "articles.read", true)
+
+	permission.SetStatus(PERMISSION_STATUS_INACTIVE)
+
+	err = store.PermissionUpdate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_03", "articles.read", false)
+
+	if store.CacheStats().Invalidations < 1 {
+		t.Fatal("invalidations MUST be counted")
+	}
+}
+
+func TestStoreCache_Role(t *testing.T) {
+	store := initStoreWithCache(t, ":memory:")
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("editor").
+		SetTitle("Editor")
+
+	err := store.RoleCreate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.publish").
+		SetTitle("Publish articles")
+
+	err = store.PermissionCreate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID())
+
+	err = store.EntityRoleCreate(context.Background(), entityRole)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_01", "articles.publish", false)
+
+	// adding a permission to a role flushes the cache
+	err = store.RolePermissionCreate(context.Background(), NewRolePermission().
+		SetRoleID(role.ID()).
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_01", "articles.publish", true)
+
+	// removing the role from the entity invalidates the entity
+	err = store.EntityRoleDeleteByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_01", "articles.publish", false)
+}
+
+func TestStoreCache_Membership(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		EntityMembershipTableName: "permissions_entity_membership_table",
+		CacheSize:                 10,
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	membership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	cacheTestHas(t, store, "USER_01", "articles.read", false)
+
+	// granting to the team invalidates its members
+	checkTestGrant(t, store, "TEAM", "TEAM_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+
+	// deleting the membership invalidates the member
+	err = store.EntityMembershipDelete(context.Background(), membership)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cacheTestHas(t, store, "USER_01", "articles.read", false)
+}
+
+func TestStoreCache_CheckBatch(t *testing.T) {
+	store := initStoreWithCache(t, ":memory:")
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	// USER_01 is cached, USER_02 is loaded by the batch
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+
+	requests := []CheckRequest{
+		{EntityType: "USER", EntityID: "USER_01", Handle: "articles.read"},
+		{EntityType: "USER", EntityID: "USER_02", Handle: "articles.read"},
+	}
+
+	results, err := store.CheckBatch(context.Background(), requests)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !results[0].Allowed || results[1].Allowed {
+		t.Fatal("unexpected results", results)
+	}
+
+	stats := store.CacheStats()
+
+	if stats.Hits != 1 || stats.Size != 2 {
+		t.Fatal("expected 1 hit and 2 entries but got", stats)
+	}
+
+	// the batch results are cached and invalidated like the single checks
+	checkTestGrant(t, store, "USER", "USER_02", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	results, err = store.CheckBatch(context.Background(), requests)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !results[0].Allowed || !results[1].Allowed {
+		t.Fatal("unexpected results", results)
+	}
+}
+
+func TestStoreCacheDisabled(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+
+	if (store.CacheStats() != CacheStats{}) {
+		t.Fatal("disabled cache MUST have zero stats")
+	}
+}
+
+func TestStoreCache_StaleFill(t *testing.T) {
+	s := initStoreWithCache(t, ":memory:")
+
+	defer func() {
+		if err := s.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	_, entityPermission := checkTestGrant(t, s, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	// a reader misses and loads the grants, as in entityGrantsCached
+	generation := s.(*store).cache.generation()
+
+	grants, err := s.(*store).entityGrants(ctx, "USER", "USER_01", nil)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// a writer revokes the grant before the reader fills the cache
+	if err := s.EntityPermissionDelete(ctx, entityPermission); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	s.(*store).cache.set("USER", "USER_01", grants, generation)
+
+	cacheTestHas(t, s, "USER_01", "articles.read", false)
+}
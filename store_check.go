@@ -0,0 +1,399 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// EntityHasPermission checks whether the entity is granted the permission with the given handle,
+// either directly or via one of its roles. Grants on wildcard handles, i.e. "billing.*",
+// satisfy the checks for all descendant handles, i.e. "billing.invoice.read".
+//
+// An explicit deny grant overrides allow grants of equal or lower specificity,
+// i.e. a deny on "reports.payroll" overrides an allow on "reports.*", while
+// an allow on "reports.payroll" overrides a deny on "reports.*".
+//
+// Soft deleted grants, grants outside their validity window, soft deleted permissions
+// and permissions which are not active do not grant or deny anything. Same applies to soft deleted and inactive roles.
+func (store *store) EntityHasPermission(ctx context.Context, entityType string, entityID string, handle string) (bool, error) {
+	return entityHasPermission(ctx, store.entityGrantsCached, entityType, entityID, handle)
+}
+
+// EntityHasAnyPermission checks whether the entity is granted at least one of the permissions with the given handles.
+// Deny grants are applied to each handle separately, as in EntityHasPermission.
+func (store *store) EntityHasAnyPermission(ctx context.Context, entityType string, entityID string, handles []string) (bool, error) {
+	return entityHasAnyPermission(ctx, store.entityGrantsCached, entityType, entityID, handles)
+}
+
+// EntityHasAllPermissions checks whether the entity is granted every one of the permissions with the given handles
+func (store *store) EntityHasAllPermissions(ctx context.Context, entityType string, entityID string, handles []string) (bool, error) {
+	return entityHasAllPermissions(ctx, store.entityGrantsCached, entityType, entityID, handles)
+}
+
+// EntityHasPermissionOnResource checks whether the entity is granted the permission
+// with the given handle on the given resource.
+//
+// A global grant applies to every resource, while a resource scoped grant
+// applies only to its own resource. Grants via roles are always global.
+// On equal handle specificity a resource scoped grant overrides a global grant,
+// so a deny on a single resource overrides a global allow.
+func (store *store) EntityHasPermissionOnResource(ctx context.Context, entityType string, entityID string, handle string, resourceType string, resourceID string) (bool, error) {
+	return entityHasPermissionOnResource(ctx, store.entityGrantsCached, entityType, entityID, handle, resourceType, resourceID)
+}
+
+// grantLoader loads the grants of the entity matching the filter, all grants when the filter is nil
+type grantLoader func(ctx context.Context, entityType string, entityID string, filter *grantFilter) ([]grant, error)
+
+// entityHasPermission implements EntityHasPermission for the grants loaded by load
+func entityHasPermission(ctx context.Context, load grantLoader, entityType string, entityID string, handle string) (bool, error) {
+	if handle == "" {
+		return false, errors.New("permissionstore > EntityHasPermission. handle is empty")
+	}
+
+	granted, err := entityGrantedHandles(ctx, load, entityType, entityID, []string{handle}, "", "")
+
+	if err != nil {
+		return false, err
+	}
+
+	return lo.Contains(granted, handle), nil
+}
+
+// entityHasAnyPermission implements EntityHasAnyPermission for the grants loaded by load
+func entityHasAnyPermission(ctx context.Context, load grantLoader, entityType string, entityID string, handles []string) (bool, error) {
+	if len(handles) < 1 {
+		return false, errors.New("permissionstore > EntityHasAnyPermission. handles " + ERROR_EMPTY_ARRAY)
+	}
+
+	granted, err := entityGrantedHandles(ctx, load, entityType, entityID, handles, "", "")
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(granted) > 0, nil
+}
+
+// entityHasAllPermissions implements EntityHasAllPermissions for the grants loaded by load
+func entityHasAllPermissions(ctx context.Context, load grantLoader, entityType string, entityID string, handles []string) (bool, error) {
+	if len(handles) < 1 {
+		return false, errors.New("permissionstore > EntityHasAllPermissions. handles " + ERROR_EMPTY_ARRAY)
+	}
+
+	granted, err := entityGrantedHandles(ctx, load, entityType, entityID, handles, "", "")
+
+	if err != nil {
+		return false, err
+	}
+
+	return lo.Every(granted, handles), nil
+}
+
+// entityHasPermissionOnResource implements EntityHasPermissionOnResource for the grants loaded by load
+func entityHasPermissionOnResource(ctx context.Context, load grantLoader, entityType string, entityID string, handle string, resourceType string, resourceID string) (bool, error) {
+	if handle == "" {
+		return false, errors.New("permissionstore > EntityHasPermissionOnResource. handle is empty")
+	}
+
+	if resourceType == "" {
+		return false, errors.New("permissionstore > EntityHasPermissionOnResource. resourceType is empty")
+	}
+
+	if resourceID == "" {
+		return false, errors.New("permissionstore > EntityHasPermissionOnResource. resourceID is empty")
+	}
+
+	granted, err := entityGrantedHandles(ctx, load, entityType, entityID, []string{handle}, resourceType, resourceID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return lo.Contains(granted, handle), nil
+}
+
+// entityGrantedHandles returns the subset of the given handles, which are granted to the entity.
+//
+// A handle is granted when the handle itself or a wildcard handle of one of
+// its ancestors is granted, i.e. "billing.invoice.read" is granted by
+// a grant on "billing.invoice.read", "billing.invoice.*", "billing.*" or "*".
+// Explicit deny grants take precedence over allow grants of equal or lower
+// specificity, see grantsAllow for the precedence rule.
+//
+// When the resource is empty only the global grants are considered, otherwise
+// the global grants and the grants scoped to the given resource.
+func entityGrantedHandles(ctx context.Context, load grantLoader, entityType string, entityID string, handles []string, resourceType string, resourceID string) ([]string, error) {
+	if entityType == "" {
+		return nil, errors.New("permissionstore > entityGrantedHandles. entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("permissionstore > entityGrantedHandles. entityID is empty")
+	}
+
+	for _, handle := range handles {
+		if err := PermissionHandleValidate(handle); err != nil {
+			return nil, errors.New("permissionstore > entityGrantedHandles. " + err.Error())
+		}
+	}
+
+	patterns := lo.Uniq(lo.FlatMap(handles, func(handle string, _ int) []string {
+		return permissionHandlePatterns(handle)
+	}))
+
+	grants, err := load(ctx, entityType, entityID, &grantFilter{
+		handles:      patterns,
+		resourceType: resourceType,
+		resourceID:   resourceID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	granted := lo.Filter(lo.Uniq(handles), func(handle string, _ int) bool {
+		return grantsAllow(grants, handle, resourceType, resourceID)
+	})
+
+	return granted, nil
+}
+
+// grantFilter narrows down the grants loaded for an entity
+type grantFilter struct {
+	// handles are the handles of the granted permissions, including wildcard handles
+	handles []string
+
+	// resourceType and resourceID select the global grants and the grants scoped
+	// to the resource, when empty only the global grants are selected
+	resourceType string
+	resourceID   string
+}
+
+// entityGrantsCached returns the grants of the entity matching the filter.
+//
+// When the cache is enabled all grants of the entity are loaded and cached,
+// the filter is then left to the evaluation. The cache is bypassed within
+// transactions, as these may see uncommitted changes.
+func (store *store) entityGrantsCached(ctx context.Context, entityType string, entityID string, filter *grantFilter) ([]grant, error) {
+	if store.cache == nil || store.inTransaction(ctx) {
+		return store.entityGrants(ctx, entityType, entityID, filter)
+	}
+
+	if grants, found := store.cache.get(entityType, entityID); found {
+		return grants, nil
+	}
+
+	generation := store.cache.generation()
+
+	grants, err := store.entityGrants(ctx, entityType, entityID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	store.cache.set(entityType, entityID, grants, generation)
+
+	return grants, nil
+}
+
+// entityGrants returns the grants of the entity matching the filter, all grants when the filter is nil.
+//
+// The direct grants are resolved in a single query joining the entity permission table
+// to the permission table. When memberships are enabled, the grants of the ancestors
+// of the entity are inherited and resolved in the same query, after one query per level
+// of memberships walked. When roles are enabled, the grants via the roles of the entity
+// are resolved in one more query. Grants via roles always allow and are always global.
+//
+// Expired grants are left out, pending grants are loaded and left to the evaluation.
+func (store *store) entityGrants(ctx context.Context, entityType string, entityID string, filter *grantFilter) ([]grant, error) {
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	grants := []grant{}
+	entities := []EntityRef{{EntityType: entityType, EntityID: entityID}}
+
+	if store.membershipsEnabled() {
+		ancestors, err := entityAncestors(ctx, store.entityMembershipParents, entities[0], store.membershipMaxDepth)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entities = append(entities, ancestors...)
+	}
+
+	sqlStr, params, errSql := store.entityDirectGrantsQuery(entities, filter).
+		Prepared(true).
+		SelectDistinct(
+			goqu.I("p."+COLUMN_HANDLE).As(COLUMN_HANDLE),
+			goqu.I("ep."+COLUMN_EFFECT).As(COLUMN_EFFECT),
+			goqu.I("ep."+COLUMN_RESOURCE_TYPE).As(COLUMN_RESOURCE_TYPE),
+			goqu.I("ep."+COLUMN_RESOURCE_ID).As(COLUMN_RESOURCE_ID),
+			goqu.I("ep."+COLUMN_VALID_FROM).As(COLUMN_VALID_FROM),
+			goqu.I("ep."+COLUMN_EXPIRES_AT).As(COLUMN_EXPIRES_AT),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, modelMap := range modelMaps {
+		grants = append(grants, grant{
+			handle:       modelMap[COLUMN_HANDLE],
+			effect:       modelMap[COLUMN_EFFECT],
+			resourceType: modelMap[COLUMN_RESOURCE_TYPE],
+			resourceID:   modelMap[COLUMN_RESOURCE_ID],
+			validFrom:    modelMap[COLUMN_VALID_FROM],
+			expiresAt:    modelMap[COLUMN_EXPIRES_AT],
+		})
+	}
+
+	if !store.rolesEnabled() {
+		return grants, nil
+	}
+
+	sqlStr, params, errSql = store.entityRoleGrantsQuery(entityType, entityID, filter).
+		Prepared(true).
+		SelectDistinct(goqu.I("p." + COLUMN_HANDLE).As(COLUMN_HANDLE)).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	modelMaps, err = database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, modelMap := range modelMaps {
+		grants = append(grants, grant{
+			handle: modelMap[COLUMN_HANDLE],
+			effect: ENTITY_PERMISSION_EFFECT_ALLOW,
+		})
+	}
+
+	return grants, nil
+}
+
+// entityDirectGrantsQuery returns the query selecting the active permissions
+// granted directly to any of the entities and matching the filter
+func (store *store) entityDirectGrantsQuery(entities []EntityRef, filter *grantFilter) *goqu.SelectDataset {
+	q := store.directGrantsQuery().
+		Where(
+			goqu.Or(lo.Map(entities, func(entity EntityRef, _ int) exp.Expression {
+				return goqu.And(
+					goqu.I("ep."+COLUMN_ENTITY_TYPE).Eq(entity.EntityType),
+					goqu.I("ep."+COLUMN_ENTITY_ID).Eq(entity.EntityID),
+				)
+			})...),
+		)
+
+	if filter == nil {
+		return q
+	}
+
+	resource := goqu.And(
+		goqu.I("ep."+COLUMN_RESOURCE_TYPE).Eq(""),
+		goqu.I("ep."+COLUMN_RESOURCE_ID).Eq(""),
+	)
+
+	if filter.resourceType != "" || filter.resourceID != "" {
+		resource = goqu.Or(
+			resource,
+			goqu.And(
+				goqu.I("ep."+COLUMN_RESOURCE_TYPE).Eq(filter.resourceType),
+				goqu.I("ep."+COLUMN_RESOURCE_ID).Eq(filter.resourceID),
+			),
+		)
+	}
+
+	return q.Where(
+		resource,
+		goqu.I("p."+COLUMN_HANDLE).In(lo.Uniq(filter.handles)),
+	)
+}
+
+// entityRoleGrantsQuery returns the query selecting the active permissions
+// granted to the entity via its active roles and matching the filter
+func (store *store) entityRoleGrantsQuery(entityType string, entityID string, filter *grantFilter) *goqu.SelectDataset {
+	q := store.roleGrantsQuery().
+		Where(
+			goqu.I("er."+COLUMN_ENTITY_TYPE).Eq(entityType),
+			goqu.I("er."+COLUMN_ENTITY_ID).Eq(entityID),
+		)
+
+	if filter == nil {
+		return q
+	}
+
+	return q.Where(goqu.I("p." + COLUMN_HANDLE).In(lo.Uniq(filter.handles)))
+}
+
+// directGrantsQuery returns the query joining the entity permissions, which are
+// neither soft deleted nor expired, to their active permissions, aliased "ep" and "p"
+func (store *store) directGrantsQuery() *goqu.SelectDataset {
+	now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+	return goqu.Dialect(store.dbDriverName).
+		From(goqu.T(store.entityPermissionTableName).As("ep")).
+		InnerJoin(
+			goqu.T(store.permissionTableName).As("p"),
+			goqu.On(goqu.I("p."+COLUMN_ID).Eq(goqu.I("ep."+COLUMN_PERMISSION_ID))),
+		).
+		Where(
+			goqu.I("ep."+COLUMN_SOFT_DELETED_AT).Gt(now),
+			goqu.I("ep."+COLUMN_EXPIRES_AT).Gt(now),
+			goqu.I("p."+COLUMN_STATUS).Eq(PERMISSION_STATUS_ACTIVE),
+			goqu.I("p."+COLUMN_SOFT_DELETED_AT).Gt(now),
+		)
+}
+
+// roleGrantsQuery returns the query joining the entity roles to their active roles,
+// the role permissions and the active permissions, none of them soft deleted,
+// aliased "er", "r", "rp" and "p"
+func (store *store) roleGrantsQuery() *goqu.SelectDataset {
+	now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+	return goqu.Dialect(store.dbDriverName).
+		From(goqu.T(store.entityRoleTableName).As("er")).
+		InnerJoin(
+			goqu.T(store.roleTableName).As("r"),
+			goqu.On(goqu.I("r."+COLUMN_ID).Eq(goqu.I("er."+COLUMN_ROLE_ID))),
+		).
+		InnerJoin(
+			goqu.T(store.rolePermissionTableName).As("rp"),
+			goqu.On(goqu.I("rp."+COLUMN_ROLE_ID).Eq(goqu.I("r."+COLUMN_ID))),
+		).
+		InnerJoin(
+			goqu.T(store.permissionTableName).As("p"),
+			goqu.On(goqu.I("p."+COLUMN_ID).Eq(goqu.I("rp."+COLUMN_PERMISSION_ID))),
+		).
+		Where(
+			goqu.I("er."+COLUMN_SOFT_DELETED_AT).Gt(now),
+			goqu.I("r."+COLUMN_STATUS).Eq(ROLE_STATUS_ACTIVE),
+			goqu.I("r."+COLUMN_SOFT_DELETED_AT).Gt(now),
+			goqu.I("rp."+COLUMN_SOFT_DELETED_AT).Gt(now),
+			goqu.I("p."+COLUMN_STATUS).Eq(PERMISSION_STATUS_ACTIVE),
+			goqu.I("p."+COLUMN_SOFT_DELETED_AT).Gt(now),
+		)
+}
@@ -0,0 +1,367 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// checkBatchChunkSize is the maximum number of entity IDs of a single IN condition of CheckBatch
+const checkBatchChunkSize = 500
+
+// CheckRequest is a single check of CheckBatch, a global check when the resource is empty
+type CheckRequest struct {
+	EntityType   string
+	EntityID     string
+	Handle       string
+	ResourceType string
+	ResourceID   string
+}
+
+// CheckResult is the result of a single check of CheckBatch
+type CheckResult struct {
+	EntityType   string
+	EntityID     string
+	Handle       string
+	ResourceType string
+	ResourceID   string
+
+	// Allowed is the verdict, the same as the one of EntityHasPermission
+	// or EntityHasPermissionOnResource for the request
+	Allowed bool
+}
+
+// CheckBatch evaluates many checks at once, i.e. "can edit" for every row of a table.
+// A request without resource is evaluated like EntityHasPermission, a request with
+// resource like EntityHasPermissionOnResource.
+//
+// The grants of all the entities of the requests are loaded together, using one IN query
+// per entity type and chunk of entity IDs for the direct grants and one more for the grants
+// via roles, when enabled. When memberships are enabled, the ancestors of the entities are
+// walked together as well, one query per level of memberships and chunk. So the number of
+// queries depends on the number of distinct entities, not on the number of requests.
+//
+// Returns a result for each request in the given order.
+func (store *store) CheckBatch(ctx context.Context, requests []CheckRequest) ([]CheckResult, error) {
+	return checkBatch(ctx, store.entityGrantsBatchCached, requests)
+}
+
+// batchGrantLoader loads the grants of the entities on the permissions with the given handles,
+// all grants when handles is nil, keyed by entity. Every entity has an entry, possibly empty
+type batchGrantLoader func(ctx context.Context, entities []EntityRef, handles []string) (map[EntityRef][]grant, error)
+
+// checkBatch implements CheckBatch for the grants loaded by load
+func checkBatch(ctx context.Context, load batchGrantLoader, requests []CheckRequest) ([]CheckResult, error) {
+	for index, request := range requests {
+		if err := checkRequestValidate(request); err != nil {
+			return nil, errors.New("permissionstore > CheckBatch. request " + strconv.Itoa(index) + ": " + err.Error())
+		}
+	}
+
+	if len(requests) < 1 {
+		return []CheckResult{}, nil
+	}
+
+	entities := lo.Uniq(lo.Map(requests, func(request CheckRequest, _ int) EntityRef {
+		return EntityRef{EntityType: request.EntityType, EntityID: request.EntityID}
+	}))
+
+	handles := lo.Uniq(lo.FlatMap(requests, func(request CheckRequest, _ int) []string {
+		return permissionHandlePatterns(request.Handle)
+	}))
+
+	grants, err := load(ctx, entities, handles)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(requests, func(request CheckRequest, _ int) CheckResult {
+		entity := EntityRef{EntityType: request.EntityType, EntityID: request.EntityID}
+
+		return CheckResult{
+			EntityType:   request.EntityType,
+			EntityID:     request.EntityID,
+			Handle:       request.Handle,
+			ResourceType: request.ResourceType,
+			ResourceID:   request.ResourceID,
+			Allowed:      grantsAllow(grants[entity], request.Handle, request.ResourceType, request.ResourceID),
+		}
+	}), nil
+}
+
+// checkRequestValidate returns an error if the entity or the handle of the request
+// is not set, or if only one of the resource type and the resource ID is set
+func checkRequestValidate(request CheckRequest) error {
+	if request.EntityType == "" {
+		return errors.New("entityType is empty")
+	}
+
+	if request.EntityID == "" {
+		return errors.New("entityID is empty")
+	}
+
+	if err := PermissionHandleValidate(request.Handle); err != nil {
+		return err
+	}
+
+	if (request.ResourceType == "") != (request.ResourceID == "") {
+		return errors.New("resourceType and resourceID must be both set or both empty")
+	}
+
+	return nil
+}
+
+// entityGrantsBatchCached returns the grants of the entities, see batchGrantLoader.
+//
+// When the cache is enabled, the grants of the cached entities are taken from the cache,
+// while all grants of the other entities are loaded together and cached, the same
+// way as in entityGrantsCached. The cache is bypassed within transactions.
+func (store *store) entityGrantsBatchCached(ctx context.Context, entities []EntityRef, handles []string) (map[EntityRef][]grant, error) {
+	if store.cache == nil || store.inTransaction(ctx) {
+		return store.entityGrantsBatch(ctx, entities, handles)
+	}
+
+	grants := map[EntityRef][]grant{}
+	missing := []EntityRef{}
+
+	for _, entity := range entities {
+		if cached, found := store.cache.get(entity.EntityType, entity.EntityID); found {
+			grants[entity] = cached
+		} else {
+			missing = append(missing, entity)
+		}
+	}
+
+	if len(missing) < 1 {
+		return grants, nil
+	}
+
+	generation := store.cache.generation()
+
+	loaded, err := store.entityGrantsBatch(ctx, missing, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for entity, entityGrants := range loaded {
+		store.cache.set(entity.EntityType, entity.EntityID, entityGrants, generation)
+		grants[entity] = entityGrants
+	}
+
+	return grants, nil
+}
+
+// entityGrantsBatch returns the grants of the entities, selecting the same rows
+// as entityGrants does for each of them, see batchGrantLoader
+func (store *store) entityGrantsBatch(ctx context.Context, entities []EntityRef, handles []string) (map[EntityRef][]grant, error) {
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	ancestors := map[EntityRef][]EntityRef{}
+	holders := entities
+
+	if store.membershipsEnabled() {
+		var err error
+
+		ancestors, err = store.entityAncestorsBatch(ctx, entities)
+
+		if err != nil {
+			return nil, err
+		}
+
+		holders = lo.Uniq(append(holders, lo.Flatten(lo.Values(ancestors))...))
+	}
+
+	handleFilter := func(q *goqu.SelectDataset) *goqu.SelectDataset {
+		if handles == nil {
+			return q
+		}
+
+		return q.Where(goqu.I("p." + COLUMN_HANDLE).In(handles))
+	}
+
+	direct := map[EntityRef][]grant{}
+
+	for _, condition := range entityRefConditions("ep."+COLUMN_ENTITY_TYPE, "ep."+COLUMN_ENTITY_ID, holders) {
+		q := handleFilter(store.directGrantsQuery().Where(condition)).
+			SelectDistinct(
+				goqu.I("ep."+COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE),
+				goqu.I("ep."+COLUMN_ENTITY_ID).As(COLUMN_ENTITY_ID),
+				goqu.I("p."+COLUMN_HANDLE).As(COLUMN_HANDLE),
+				goqu.I("ep."+COLUMN_EFFECT).As(COLUMN_EFFECT),
+				goqu.I("ep."+COLUMN_RESOURCE_TYPE).As(COLUMN_RESOURCE_TYPE),
+				goqu.I("ep."+COLUMN_RESOURCE_ID).As(COLUMN_RESOURCE_ID),
+				goqu.I("ep."+COLUMN_VALID_FROM).As(COLUMN_VALID_FROM),
+				goqu.I("ep."+COLUMN_EXPIRES_AT).As(COLUMN_EXPIRES_AT),
+			)
+
+		rows, err := store.checkBatchSelect(ctx, q)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			holder := EntityRef{EntityType: row[COLUMN_ENTITY_TYPE], EntityID: row[COLUMN_ENTITY_ID]}
+
+			direct[holder] = append(direct[holder], grant{
+				handle:       row[COLUMN_HANDLE],
+				effect:       row[COLUMN_EFFECT],
+				resourceType: row[COLUMN_RESOURCE_TYPE],
+				resourceID:   row[COLUMN_RESOURCE_ID],
+				validFrom:    row[COLUMN_VALID_FROM],
+				expiresAt:    row[COLUMN_EXPIRES_AT],
+			})
+		}
+	}
+
+	grants := map[EntityRef][]grant{}
+
+	for _, entity := range entities {
+		grants[entity] = append([]grant{}, direct[entity]...)
+
+		for _, ancestor := range ancestors[entity] {
+			grants[entity] = append(grants[entity], direct[ancestor]...)
+		}
+	}
+
+	if !store.rolesEnabled() {
+		return grants, nil
+	}
+
+	for _, condition := range entityRefConditions("er."+COLUMN_ENTITY_TYPE, "er."+COLUMN_ENTITY_ID, entities) {
+		q := handleFilter(store.roleGrantsQuery().Where(condition)).
+			SelectDistinct(
+				goqu.I("er."+COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE),
+				goqu.I("er."+COLUMN_ENTITY_ID).As(COLUMN_ENTITY_ID),
+				goqu.I("p."+COLUMN_HANDLE).As(COLUMN_HANDLE),
+			)
+
+		rows, err := store.checkBatchSelect(ctx, q)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			entity := EntityRef{EntityType: row[COLUMN_ENTITY_TYPE], EntityID: row[COLUMN_ENTITY_ID]}
+
+			grants[entity] = append(grants[entity], grant{
+				handle: row[COLUMN_HANDLE],
+				effect: ENTITY_PERMISSION_EFFECT_ALLOW,
+			})
+		}
+	}
+
+	return grants, nil
+}
+
+// entityAncestorsBatch returns the ancestors of each of the entities, the same as
+// entityAncestors does. The parents of all entities of a level are loaded together,
+// then the ancestors of each entity are walked over the loaded parents
+func (store *store) entityAncestorsBatch(ctx context.Context, entities []EntityRef) (map[EntityRef][]EntityRef, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString()
+	parents := map[EntityRef][]EntityRef{}
+	loaded := map[EntityRef]bool{}
+	level := entities
+
+	for depth := 0; depth < store.membershipMaxDepth && len(level) > 0; depth++ {
+		for _, entity := range level {
+			loaded[entity] = true
+		}
+
+		for _, condition := range entityRefConditions(COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID, level) {
+			q := goqu.Dialect(store.dbDriverName).
+				From(store.entityMembershipTableName).
+				Where(condition, goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)).
+				SelectDistinct(COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID)
+
+			rows, err := store.checkBatchSelect(ctx, q)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, row := range rows {
+				member := EntityRef{EntityType: row[COLUMN_MEMBER_TYPE], EntityID: row[COLUMN_MEMBER_ID]}
+				parents[member] = append(parents[member], EntityRef{EntityType: row[COLUMN_PARENT_TYPE], EntityID: row[COLUMN_PARENT_ID]})
+			}
+		}
+
+		level = lo.Uniq(lo.Filter(lo.Flatten(lo.Map(level, func(entity EntityRef, _ int) []EntityRef {
+			return parents[entity]
+		})), func(parent EntityRef, _ int) bool {
+			return !loaded[parent]
+		}))
+	}
+
+	load := func(ctx context.Context, level []EntityRef) ([]EntityRef, error) {
+		return lo.Flatten(lo.Map(level, func(entity EntityRef, _ int) []EntityRef {
+			return parents[entity]
+		})), nil
+	}
+
+	ancestors := map[EntityRef][]EntityRef{}
+
+	for _, entity := range entities {
+		walked, err := entityAncestors(ctx, load, entity, store.membershipMaxDepth)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ancestors[entity] = walked
+	}
+
+	return ancestors, nil
+}
+
+// entityRefConditions returns the conditions selecting the entities by the columns
+// typeColumn and idColumn, one per entity type and chunk of entity IDs, each to be
+// run as a separate query
+func entityRefConditions(typeColumn string, idColumn string, entities []EntityRef) []exp.Expression {
+	conditions := []exp.Expression{}
+
+	byType := lo.GroupBy(entities, func(entity EntityRef) string {
+		return entity.EntityType
+	})
+
+	for _, entityType := range lo.Uniq(lo.Map(entities, func(entity EntityRef, _ int) string {
+		return entity.EntityType
+	})) {
+		ids := lo.Uniq(lo.Map(byType[entityType], func(entity EntityRef, _ int) string {
+			return entity.EntityID
+		}))
+
+		for _, chunk := range lo.Chunk(ids, checkBatchChunkSize) {
+			conditions = append(conditions, goqu.And(
+				goqu.I(typeColumn).Eq(entityType),
+				goqu.I(idColumn).In(chunk),
+			))
+		}
+	}
+
+	return conditions
+}
+
+// checkBatchSelect runs the query and returns the rows
+func (store *store) checkBatchSelect(ctx context.Context, q *goqu.SelectDataset) ([]map[string]string, error) {
+	sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	return database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+}
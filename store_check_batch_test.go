@@ -0,0 +1,217 @@
+package permissionstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// checkBatchTestSeed grants "documents.edit" on some of the documents DOCUMENT_001 to
+// DOCUMENT_200 and returns the requests checking it for every document and user
+func checkBatchTestSeed(t *testing.T, store StoreInterface) []CheckRequest {
+	t.Helper()
+
+	ctx := context.Background()
+
+	// USER_01 may edit the documents 1 to 3 only
+	permission, _ := checkTestGrant(t, store, "USER", "USER_02", "documents.*", PERMISSION_STATUS_ACTIVE)
+
+	edit, _ := checkTestGrant(t, store, "TEAM", "TEAM_01", "documents.edit", PERMISSION_STATUS_ACTIVE)
+
+	for _, documentID := range []string{"DOCUMENT_001", "DOCUMENT_002", "DOCUMENT_003"} {
+		err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(edit.ID()).
+			SetResourceType("DOCUMENT").
+			SetResourceID(documentID))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// USER_02 may edit all documents, except document 100
+	err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetPermissionID(permission.ID()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY).
+		SetResourceType("DOCUMENT").
+		SetResourceID("DOCUMENT_100"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// USER_03 may edit all documents via TEAM_01, except document 200
+	membershipTestCreate(t, store, "USER", "USER_03", "TEAM", "TEAM_01")
+
+	err = store.EntityPermissionCreate(ctx, NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_03").
+		SetPermissionID(edit.ID()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY).
+		SetResourceType("DOCUMENT").
+		SetResourceID("DOCUMENT_200"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	requests := []CheckRequest{}
+
+	for i := 1; i <= 200; i++ {
+		for _, entityID := range []string{"USER_01", "USER_02", "USER_03", "USER_04"} {
+			requests = append(requests, CheckRequest{
+				EntityType:   "USER",
+				EntityID:     entityID,
+				Handle:       "documents.edit",
+				ResourceType: "DOCUMENT",
+				ResourceID:   fmt.Sprintf("DOCUMENT_%03d", i),
+			})
+		}
+	}
+
+	return append(requests,
+		CheckRequest{EntityType: "USER", EntityID: "USER_01", Handle: "documents.edit"},
+		CheckRequest{EntityType: "USER", EntityID: "USER_02", Handle: "documents.edit"},
+		CheckRequest{EntityType: "USER", EntityID: "USER_03", Handle: "documents.edit"},
+	)
+}
+
+// checkBatchTestExpected checks the request on its own
+func checkBatchTestExpected(t *testing.T, store StoreInterface, request CheckRequest) bool {
+	t.Helper()
+
+	var has bool
+	var err error
+
+	if request.ResourceType == "" {
+		has, err = store.EntityHasPermission(context.Background(), request.EntityType, request.EntityID, request.Handle)
+	} else {
+		has, err = store.EntityHasPermissionOnResource(context.Background(), request.EntityType, request.EntityID, request.Handle, request.ResourceType, request.ResourceID)
+	}
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return has
+}
+
+// checkBatchTestLogTo makes the database store log its queries to the buffer
+func checkBatchTestLogTo(s StoreInterface, log *bytes.Buffer) {
+	s.(*store).sqlLogger = slog.New(slog.NewTextHandler(log, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestStoreCheckBatch(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	requests := checkBatchTestSeed(t, store)
+
+	var log bytes.Buffer
+
+	checkBatchTestLogTo(store, &log)
+
+	results, err := store.CheckBatch(context.Background(), requests)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the parents of the users and of TEAM_01, the direct grants of the users
+	// and of TEAM_01, one query per entity type, and the grants via roles
+	if queries := strings.Count(log.String(), "sql: select"); queries != 5 {
+		t.Fatal("expected 5 queries for", len(requests), "requests but got", queries)
+	}
+
+	if len(results) != len(requests) {
+		t.Fatal("expected", len(requests), "results but got", len(results))
+	}
+
+	allowed := map[string]int{}
+
+	for i, result := range results {
+		request := requests[i]
+
+		if result.EntityID != request.EntityID || result.ResourceID != request.ResourceID || result.Handle != request.Handle {
+			t.Fatal("result", i, "is not in the order of the requests", result)
+		}
+
+		if result.Allowed != checkBatchTestExpected(t, store, request) {
+			t.Fatal("result", i, "differs from the single check", result)
+		}
+
+		if result.Allowed {
+			allowed[result.EntityID]++
+		}
+	}
+
+	expected := map[string]int{"USER_01": 3, "USER_02": 199 + 1, "USER_03": 199 + 1}
+
+	for entityID, count := range expected {
+		if allowed[entityID] != count {
+			t.Fatal(entityID, "expected", count, "allowed checks but got", allowed[entityID])
+		}
+	}
+
+	if allowed["USER_04"] != 0 {
+		t.Fatal("USER_04 MUST NOT be allowed anything")
+	}
+}
+
+func TestStoreCheckBatch_Invalid(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	results, err := store.CheckBatch(context.Background(), []CheckRequest{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(results) != 0 {
+		t.Fatal("expected no results but got", results)
+	}
+
+	invalid := []CheckRequest{
+		{EntityType: "", EntityID: "USER_01", Handle: "documents.edit"},
+		{EntityType: "USER", EntityID: "", Handle: "documents.edit"},
+		{EntityType: "USER", EntityID: "USER_01", Handle: ""},
+		{EntityType: "USER", EntityID: "USER_01", Handle: "documents.edit", ResourceType: "DOCUMENT"},
+	}
+
+	for _, request := range invalid {
+		_, err := store.CheckBatch(context.Background(), []CheckRequest{
+			{EntityType: "USER", EntityID: "USER_01", Handle: "documents.edit"},
+			request,
+		})
+
+		if err == nil || !strings.Contains(err.Error(), "request 1") {
+			t.Fatal("expected error for request 1 but got", err)
+		}
+	}
+}
@@ -0,0 +1,805 @@
+package permissionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+// checkTestGrant creates a permission with the given handle and status,
+// and grants it to the given entity
+func checkTestGrant(t *testing.T, store StoreInterface, entityType string, entityID string, handle string, status string) (PermissionInterface, EntityPermissionInterface) {
+	t.Helper()
+
+	return checkTestGrantWithEffect(t, store, entityType, entityID, handle, status, ENTITY_PERMISSION_EFFECT_ALLOW)
+}
+
+func checkTestGrantWithEffect(t *testing.T, store StoreInterface, entityType string, entityID string, handle string, status string, effect string) (PermissionInterface, EntityPermissionInterface) {
+	t.Helper()
+
+	permission, err := store.PermissionFindByHandle(context.Background(), handle)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if permission == nil {
+		permission = NewPermission().
+			SetStatus(status).
+			SetHandle(handle).
+			SetTitle(handle)
+
+		err = store.PermissionCreate(context.Background(), permission)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	entityPermission := NewEntityPermission().
+		SetEntityType(entityType).
+		SetEntityID(entityID).
+		SetPermissionID(permission.ID()).
+		SetEffect(effect)
+
+	err = store.EntityPermissionCreate(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return permission, entityPermission
+}
+
+func TestStoreEntityHasPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have permission articles.read")
+	}
+
+	has, err = store.EntityHasPermission(context.Background(), "USER", "USER_02", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_02 MUST NOT have permission articles.read")
+	}
+
+	has, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.write")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT have permission articles.write")
+	}
+
+	_, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "")
+
+	if err == nil {
+		t.Fatal("must return error as handle is empty")
+	}
+}
+
+func TestStoreEntityHasPermission_InactivePermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_INACTIVE)
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("inactive permission MUST NOT grant anything")
+	}
+}
+
+func TestStoreEntityHasPermission_SoftDeleted(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, entityPermission := checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+	permission, _ := checkTestGrant(t, store, "USER", "USER_01", "articles.write", PERMISSION_STATUS_ACTIVE)
+
+	err = store.EntityPermissionSoftDelete(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionSoftDelete(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("soft deleted grant MUST NOT grant anything")
+	}
+
+	has, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.write")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("soft deleted permission MUST NOT grant anything")
+	}
+}
+
+func TestStoreEntityHasAnyPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	has, err := store.EntityHasAnyPermission(context.Background(), "USER", "USER_01", []string{"articles.write", "articles.read"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have any of the permissions")
+	}
+
+	has, err = store.EntityHasAnyPermission(context.Background(), "USER", "USER_01", []string{"articles.write", "articles.delete"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT have any of the permissions")
+	}
+
+	_, err = store.EntityHasAnyPermission(context.Background(), "USER", "USER_01", []string{})
+
+	if err == nil {
+		t.Fatal("must return error as handles are empty")
+	}
+}
+
+func TestStoreEntityHasAllPermissions(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_01", "articles.write", PERMISSION_STATUS_ACTIVE)
+
+	has, err := store.EntityHasAllPermissions(context.Background(), "USER", "USER_01", []string{"articles.read", "articles.write"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have all of the permissions")
+	}
+
+	has, err = store.EntityHasAllPermissions(context.Background(), "USER", "USER_01", []string{"articles.read", "articles.delete"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT have all of the permissions")
+	}
+}
+
+func TestStoreEntityHasPermission_ViaRole(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.publish").
+		SetTitle("Publish articles")
+
+	err = store.PermissionCreate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("editor").
+		SetTitle("Editor")
+
+	err = store.RoleCreate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionCreate(context.Background(), NewRolePermission().
+		SetRoleID(role.ID()).
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID())
+
+	err = store.EntityRoleCreate(context.Background(), entityRole)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	has, err := store.EntityHasAllPermissions(context.Background(), "USER", "USER_01", []string{"articles.publish", "articles.read"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have the direct and the role permissions")
+	}
+
+	role.SetStatus(ROLE_STATUS_INACTIVE)
+
+	err = store.RoleUpdate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	has, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.publish")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("inactive role MUST NOT grant anything")
+	}
+
+	role.SetStatus(ROLE_STATUS_ACTIVE)
+
+	err = store.RoleUpdate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleSoftDelete(context.Background(), entityRole)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	has, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.publish")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("soft deleted entity role MUST NOT grant anything")
+	}
+}
+
+func TestStoreEntityHasPermission_Wildcard(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkTestGrant(t, store, "USER", "USER_01", "billing.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_02", "billing.invoice.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_03", "*", PERMISSION_STATUS_ACTIVE)
+
+	testCases := []struct {
+		entityID string
+		handle   string
+		expected bool
+	}{
+		{"USER_01", "billing.invoice.read", true},
+		{"USER_01", "billing.payment", true},
+		{"USER_01", "billing", false},
+		{"USER_01", "reports.read", false},
+		{"USER_02", "billing.invoice.read", true},
+		{"USER_02", "billing.invoice.*", true},
+		{"USER_02", "billing.payment.read", false},
+		{"USER_02", "billing.*", false},
+		{"USER_03", "reports.read", true},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermission(context.Background(), "USER", testCase.entityID, testCase.handle)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.entityID, testCase.handle, "expected", testCase.expected, "got", has)
+		}
+	}
+
+	has, err := store.EntityHasAllPermissions(context.Background(), "USER", "USER_01", []string{"billing.invoice.read", "billing.payment.read"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have all billing permissions")
+	}
+
+	_, err = store.EntityHasPermission(context.Background(), "USER", "USER_01", "billing..read")
+
+	if err == nil {
+		t.Fatal("must return error as handle is invalid")
+	}
+}
+
+func TestStoreEntityHasPermissionOnResource(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("projects.edit").
+		SetTitle("Edit projects")
+
+	err = store.PermissionCreate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// USER_01 may edit project 7 only
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID(permission.ID()).
+		SetResourceType("PROJECT").
+		SetResourceID("PROJECT_07"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// USER_02 may edit all projects
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		entityID   string
+		resourceID string
+		expected   bool
+	}{
+		{"USER_01", "PROJECT_07", true},
+		{"USER_01", "PROJECT_08", false},
+		{"USER_02", "PROJECT_07", true},
+		{"USER_02", "PROJECT_08", true},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermissionOnResource(context.Background(), "USER", testCase.entityID, "projects.edit", "PROJECT", testCase.resourceID)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.entityID, testCase.resourceID, "expected", testCase.expected, "got", has)
+		}
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "projects.edit")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("resource scoped grant MUST NOT grant the permission globally")
+	}
+
+	_, err = store.EntityHasPermissionOnResource(context.Background(), "USER", "USER_01", "projects.edit", "PROJECT", "")
+
+	if err == nil {
+		t.Fatal("must return error as resource ID is empty")
+	}
+}
+
+func TestStoreEntityHasPermission_Deny(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// USER_01: allow on the branch, deny on a leaf
+	checkTestGrant(t, store, "USER", "USER_01", "reports.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrantWithEffect(t, store, "USER", "USER_01", "reports.payroll", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+	// USER_02: deny on the branch, allow on a leaf
+	checkTestGrantWithEffect(t, store, "USER", "USER_02", "reports.*", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	checkTestGrant(t, store, "USER", "USER_02", "reports.payroll", PERMISSION_STATUS_ACTIVE)
+
+	// USER_03: deny only
+	checkTestGrantWithEffect(t, store, "USER", "USER_03", "reports.sales", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+	testCases := []struct {
+		entityID string
+		handle   string
+		expected bool
+	}{
+		{"USER_01", "reports.payroll", false},
+		{"USER_01", "reports.sales", true},
+		{"USER_02", "reports.payroll", true},
+		{"USER_02", "reports.sales", false},
+		{"USER_03", "reports.sales", false},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermission(context.Background(), "USER", testCase.entityID, testCase.handle)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.entityID, testCase.handle, "expected", testCase.expected, "got", has)
+		}
+	}
+
+	has, err := store.EntityHasAnyPermission(context.Background(), "USER", "USER_01", []string{"reports.payroll", "reports.sales"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have reports.sales")
+	}
+
+	has, err = store.EntityHasAllPermissions(context.Background(), "USER", "USER_01", []string{"reports.payroll", "reports.sales"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT have reports.payroll")
+	}
+}
+
+func TestStoreEntityHasPermission_DenyOverridesRole(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("analyst").
+		SetTitle("Analyst")
+
+	err = store.RoleCreate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, handle := range []string{"reports.*", "exports.csv"} {
+		permission := NewPermission().
+			SetStatus(PERMISSION_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle)
+
+		err = store.PermissionCreate(context.Background(), permission)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		err = store.RolePermissionCreate(context.Background(), NewRolePermission().
+			SetRoleID(role.ID()).
+			SetPermissionID(permission.ID()))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	checkTestGrantWithEffect(t, store, "USER", "USER_01", "reports.payroll", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	checkTestGrantWithEffect(t, store, "USER", "USER_01", "exports.csv", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+	testCases := []struct {
+		handle   string
+		expected bool
+	}{
+		{"reports.payroll", false},
+		{"reports.sales", true},
+		{"exports.csv", false},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", testCase.handle)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.handle, "expected", testCase.expected, "got", has)
+		}
+	}
+}
+
+func TestStoreEntityHasPermissionOnResource_Deny(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// USER_01 may edit all documents, except document 7
+	permission, _ := checkTestGrant(t, store, "USER", "USER_01", "documents.edit", PERMISSION_STATUS_ACTIVE)
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID(permission.ID()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY).
+		SetResourceType("DOCUMENT").
+		SetResourceID("DOCUMENT_07"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		resourceID string
+		expected   bool
+	}{
+		{"DOCUMENT_07", false},
+		{"DOCUMENT_08", true},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermissionOnResource(context.Background(), "USER", "USER_01", "documents.edit", "DOCUMENT", testCase.resourceID)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.resourceID, "expected", testCase.expected, "got", has)
+		}
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "documents.edit")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("resource scoped deny MUST NOT deny the permission globally")
+	}
+}
+
+func TestStoreEntityHasPermission_Window(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	yesterday := carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC)
+	tomorrow := carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC)
+
+	permission, contractor := checkTestGrant(t, store, "USER", "USER_CONTRACTOR", "deploy.run", PERMISSION_STATUS_ACTIVE)
+
+	contractor.SetValidFrom(yesterday).SetExpiresAt(tomorrow)
+
+	err = store.EntityPermissionUpdate(context.Background(), contractor)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	windows := map[string][2]string{
+		"USER_EXPIRED": {carbon.Now(carbon.UTC).SubDays(2).ToDateTimeString(carbon.UTC), yesterday},
+		"USER_PENDING": {tomorrow, sb.MAX_DATETIME},
+	}
+
+	for entityID, window := range windows {
+		err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetPermissionID(permission.ID()).
+			SetValidFrom(window[0]).
+			SetExpiresAt(window[1]))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// an expired deny must not override the allow
+	checkTestGrant(t, store, "USER", "USER_ONCALL", "deploy.*", PERMISSION_STATUS_ACTIVE)
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_ONCALL").
+		SetPermissionID(permission.ID()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY).
+		SetExpiresAt(yesterday))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		entityID string
+		expected bool
+	}{
+		{"USER_CONTRACTOR", true},
+		{"USER_EXPIRED", false},
+		{"USER_PENDING", false},
+		{"USER_ONCALL", true},
+	}
+
+	for _, testCase := range testCases {
+		has, err := store.EntityHasPermission(context.Background(), "USER", testCase.entityID, "deploy.run")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != testCase.expected {
+			t.Fatal(testCase.entityID, "expected", testCase.expected, "got", has)
+		}
+	}
+}
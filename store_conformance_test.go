@@ -0,0 +1,732 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// conformanceRun runs the test against every implementation of StoreInterface,
+// so that the in-memory store is held to the behaviour of the database store
+func conformanceRun(t *testing.T, test func(t *testing.T, store StoreInterface)) {
+	t.Helper()
+
+	t.Run("sql", func(t *testing.T) {
+		store, err := initStore(":memory:")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		defer func() {
+			if err := store.DB().Close(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		test(t, store)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		store, err := NewMemoryStore(NewMemoryStoreOptions{})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		test(t, store)
+	})
+}
+
+// conformanceHandles returns the handles of the permissions matching the query
+func conformanceHandles(t *testing.T, store StoreInterface, query PermissionQueryInterface) []string {
+	t.Helper()
+
+	permissions, err := store.PermissionList(context.Background(), query)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return lo.Map(permissions, func(permission PermissionInterface, _ int) string {
+		return permission.Handle()
+	})
+}
+
+// conformancePermissions creates the permissions used by the query tests
+func conformancePermissions(t *testing.T, store StoreInterface) map[string]PermissionInterface {
+	t.Helper()
+
+	permissions := map[string]PermissionInterface{}
+
+	for _, definition := range [][3]string{
+		{"articles.create", "Create Articles", PERMISSION_STATUS_ACTIVE},
+		{"articles.delete", "Delete Articles", PERMISSION_STATUS_INACTIVE},
+		{"articles.read", "Read Articles", PERMISSION_STATUS_ACTIVE},
+		{"users.manage", "Manage Users", PERMISSION_STATUS_ACTIVE},
+		{"users.read", "Read Users", PERMISSION_STATUS_INACTIVE},
+	} {
+		permission := NewPermission().
+			SetHandle(definition[0]).
+			SetTitle(definition[1]).
+			SetStatus(definition[2])
+
+		if err := store.PermissionCreate(context.Background(), permission); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		permissions[definition[0]] = permission
+	}
+
+	return permissions
+}
+
+func TestConformancePermissionQuery(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		permissions := conformancePermissions(t, store)
+
+		ordered := func() PermissionQueryInterface {
+			return NewPermissionQuery().SetOrderBy(COLUMN_HANDLE).SetSortDirection(sb.ASC)
+		}
+
+		cases := []struct {
+			name     string
+			query    PermissionQueryInterface
+			expected []string
+		}{
+			{
+				name:     "order ascending",
+				query:    ordered(),
+				expected: []string{"articles.create", "articles.delete", "articles.read", "users.manage", "users.read"},
+			},
+			{
+				name:     "order descending",
+				query:    NewPermissionQuery().SetOrderBy(COLUMN_HANDLE).SetSortDirection(sb.DESC),
+				expected: []string{"users.read", "users.manage", "articles.read", "articles.delete", "articles.create"},
+			},
+			{
+				name:     "order by default descending",
+				query:    NewPermissionQuery().SetOrderBy(COLUMN_HANDLE),
+				expected: []string{"users.read", "users.manage", "articles.read", "articles.delete", "articles.create"},
+			},
+			{
+				name:     "limit and offset",
+				query:    ordered().SetLimit(2).SetOffset(1),
+				expected: []string{"articles.delete", "articles.read"},
+			},
+			{
+				name:     "id in",
+				query:    ordered().SetIDIn([]string{permissions["users.read"].ID(), permissions["articles.create"].ID()}),
+				expected: []string{"articles.create", "users.read"},
+			},
+			{
+				name:     "status",
+				query:    ordered().SetStatus(PERMISSION_STATUS_INACTIVE),
+				expected: []string{"articles.delete", "users.read"},
+			},
+			{
+				name:     "status in",
+				query:    ordered().SetStatusIn([]string{PERMISSION_STATUS_ACTIVE, PERMISSION_STATUS_INACTIVE}),
+				expected: []string{"articles.create", "articles.delete", "articles.read", "users.manage", "users.read"},
+			},
+			{
+				name:     "title like",
+				query:    ordered().SetTitleLike("read"),
+				expected: []string{"articles.read", "users.read"},
+			},
+			{
+				name:     "handle",
+				query:    ordered().SetHandle("users.manage"),
+				expected: []string{"users.manage"},
+			},
+			{
+				name:     "handle in",
+				query:    ordered().SetHandleIn([]string{"users.read", "articles.read", "billing.read"}),
+				expected: []string{"articles.read", "users.read"},
+			},
+		}
+
+		for _, c := range cases {
+			handles := conformanceHandles(t, store, c.query)
+
+			if !reflect.DeepEqual(handles, c.expected) {
+				t.Fatal(c.name+": expected", c.expected, "but got", handles)
+			}
+		}
+
+		count, err := store.PermissionCount(context.Background(), ordered().SetStatus(PERMISSION_STATUS_ACTIVE).SetLimit(1).SetOffset(1))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 3 {
+			t.Fatal("count MUST ignore limit and offset, expected 3 but got", count)
+		}
+	})
+}
+
+func TestConformancePermissionQueryCreatedAt(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		permissions := conformancePermissions(t, store)
+
+		for handle, createdAt := range map[string]string{
+			"articles.create": "2020-01-01 00:00:00",
+			"articles.read":   "2021-06-15 12:00:00",
+			"users.manage":    "2022-12-31 23:59:59",
+		} {
+			permission := permissions[handle]
+			permission.SetCreatedAt(createdAt)
+
+			if err := store.PermissionUpdate(context.Background(), permission); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+		}
+
+		ordered := func() PermissionQueryInterface {
+			return NewPermissionQuery().SetOrderBy(COLUMN_HANDLE).SetSortDirection(sb.ASC)
+		}
+
+		handles := conformanceHandles(t, store, ordered().SetCreatedAtLte("2021-06-15 12:00:00"))
+
+		if !reflect.DeepEqual(handles, []string{"articles.create", "articles.read"}) {
+			t.Fatal("created_at_lte: unexpected handles", handles)
+		}
+
+		handles = conformanceHandles(t, store, ordered().SetCreatedAtGte("2021-01-01 00:00:00").SetCreatedAtLte("2023-01-01 00:00:00"))
+
+		if !reflect.DeepEqual(handles, []string{"articles.read", "users.manage"}) {
+			t.Fatal("created_at_gte: unexpected handles", handles)
+		}
+
+		handles = conformanceHandles(t, store, NewPermissionQuery().SetOrderBy(COLUMN_CREATED_AT).SetSortDirection(sb.ASC).SetLimit(3))
+
+		if !reflect.DeepEqual(handles, []string{"articles.create", "articles.read", "users.manage"}) {
+			t.Fatal("order by created_at: unexpected handles", handles)
+		}
+	})
+}
+
+func TestConformanceSoftDeleteVisibility(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		permissions := conformancePermissions(t, store)
+
+		if err := store.PermissionSoftDeleteByID(context.Background(), permissions["users.read"].ID()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		found, err := store.PermissionFindByID(context.Background(), permissions["users.read"].ID())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if found != nil {
+			t.Fatal("soft deleted permission MUST NOT be found")
+		}
+
+		count, err := store.PermissionCount(context.Background(), NewPermissionQuery())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 4 {
+			t.Fatal("expected 4 visible permissions but got", count)
+		}
+
+		handles := conformanceHandles(t, store, NewPermissionQuery().
+			SetHandle("users.read").
+			SetSoftDeletedIncluded(true))
+
+		if !reflect.DeepEqual(handles, []string{"users.read"}) {
+			t.Fatal("soft deleted permission MUST be listed when included, got", handles)
+		}
+
+		if err := store.PermissionDeleteByID(context.Background(), permissions["users.read"].ID()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		count, err = store.PermissionCount(context.Background(), NewPermissionQuery().SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 4 {
+			t.Fatal("expected 4 permissions after the hard delete but got", count)
+		}
+	})
+}
+
+func TestConformanceDuplicates(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+		permissions := conformancePermissions(t, store)
+
+		err := store.PermissionCreate(ctx, NewPermission().SetHandle("articles.read").SetTitle("Read Articles").SetStatus(PERMISSION_STATUS_ACTIVE))
+
+		if !errors.Is(err, ErrDuplicateHandle) {
+			t.Fatal("expected ErrDuplicateHandle but got", err)
+		}
+
+		users := permissions["users.manage"]
+		users.SetHandle("articles.read")
+
+		if err := store.PermissionUpdate(ctx, users); !errors.Is(err, ErrDuplicateHandle) {
+			t.Fatal("expected ErrDuplicateHandle on update but got", err)
+		}
+
+		if err := store.PermissionSoftDeleteByID(ctx, permissions["articles.read"].ID()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.PermissionCreate(ctx, NewPermission().SetHandle("articles.read").SetTitle("Read Articles").SetStatus(PERMISSION_STATUS_ACTIVE)); err != nil {
+			t.Fatal("the handle of a soft deleted permission MUST be reusable, got", err)
+		}
+
+		grant := func() EntityPermissionInterface {
+			return NewEntityPermission().
+				SetEntityType("USER").
+				SetEntityID("USER_01").
+				SetPermissionID(permissions["articles.create"].ID()).
+				SetResourceType("ARTICLE").
+				SetResourceID("ARTICLE_01")
+		}
+
+		first := grant()
+
+		if err := store.EntityPermissionCreate(ctx, first); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.EntityPermissionCreate(ctx, grant()); !errors.Is(err, ErrDuplicateGrant) {
+			t.Fatal("expected ErrDuplicateGrant but got", err)
+		}
+
+		if err := store.EntityPermissionCreate(ctx, grant().SetResourceID("ARTICLE_02")); err != nil {
+			t.Fatal("a grant on another resource MUST NOT be a duplicate, got", err)
+		}
+
+		if err := store.EntityPermissionSoftDeleteByID(ctx, first.ID()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.EntityPermissionCreate(ctx, grant()); err != nil {
+			t.Fatal("a soft deleted grant MUST be grantable again, got", err)
+		}
+
+		role := NewRole().SetHandle("editor").SetTitle("Editor").SetStatus(ROLE_STATUS_ACTIVE)
+
+		if err := store.RoleCreate(ctx, role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		rolePermission := func() RolePermissionInterface {
+			return NewRolePermission().SetRoleID(role.ID()).SetPermissionID(permissions["articles.create"].ID())
+		}
+
+		if err := store.RolePermissionCreate(ctx, rolePermission()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.RolePermissionCreate(ctx, rolePermission()); !errors.Is(err, ErrDuplicateGrant) {
+			t.Fatal("expected ErrDuplicateGrant for the role permission but got", err)
+		}
+
+		entityRole := func() EntityRoleInterface {
+			return NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(role.ID())
+		}
+
+		if err := store.EntityRoleCreate(ctx, entityRole()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.EntityRoleCreate(ctx, entityRole()); !errors.Is(err, ErrDuplicateGrant) {
+			t.Fatal("expected ErrDuplicateGrant for the entity role but got", err)
+		}
+	})
+}
+
+func TestConformanceRevokeFromEntitiesRepeated(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+		permissions := conformancePermissions(t, store)
+		permissionID := permissions["articles.read"].ID()
+
+		granted, err := store.GrantToEntities(ctx, permissionID, []EntityRef{{EntityType: "USER", EntityID: "USER_01"}})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		// USER_01 repeats within the first chunk and in the next one
+		entities := []EntityRef{{EntityType: "USER", EntityID: "USER_01"}, {EntityType: "USER", EntityID: "USER_01"}}
+
+		for len(entities) < bulkChunkSize+1 {
+			entities = append(entities, EntityRef{EntityType: "USER", EntityID: "USER_02"})
+		}
+
+		entities = append(entities, EntityRef{EntityType: "USER", EntityID: "USER_01"})
+
+		results, err := store.RevokeFromEntities(ctx, permissionID, entities)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		for index, result := range results {
+			expected := BULK_STATUS_NOT_FOUND
+			expectedID := ""
+
+			if result.EntityID == "USER_01" {
+				expected = lo.Ternary(index == 0, BULK_STATUS_REVOKED, BULK_STATUS_DUPLICATE)
+				expectedID = granted[0].EntityPermissionID
+			}
+
+			if result.Status != expected || result.EntityPermissionID != expectedID {
+				t.Fatalf("expected %s with ID %q at %d, found: %+v", expected, expectedID, index, result)
+			}
+		}
+	})
+}
+
+func TestConformanceChecks(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+
+		checkTestGrant(t, store, "USER", "USER_01", "reports.*", PERMISSION_STATUS_ACTIVE)
+		checkTestGrantWithEffect(t, store, "USER", "USER_01", "reports.payroll", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+		checkTestGrant(t, store, "USER", "USER_01", "articles.archive", PERMISSION_STATUS_INACTIVE)
+
+		edit, _ := checkTestGrant(t, store, "USER", "USER_02", "articles.edit", PERMISSION_STATUS_ACTIVE)
+
+		err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(edit.ID()).
+			SetResourceType("ARTICLE").
+			SetResourceID("ARTICLE_01"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		role := NewRole().SetHandle("auditor").SetTitle("Auditor").SetStatus(ROLE_STATUS_ACTIVE)
+
+		if err := store.RoleCreate(ctx, role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		audit := NewPermission().SetHandle("audit.read").SetTitle("Read Audit").SetStatus(PERMISSION_STATUS_ACTIVE)
+
+		if err := store.PermissionCreate(ctx, audit); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.RolePermissionCreate(ctx, NewRolePermission().SetRoleID(role.ID()).SetPermissionID(audit.ID())); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.EntityRoleCreate(ctx, NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(role.ID())); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		cases := []struct {
+			handle       string
+			resourceType string
+			resourceID   string
+			expected     bool
+		}{
+			{handle: "reports.sales", expected: true},
+			{handle: "reports.payroll", expected: false},
+			{handle: "articles.archive", expected: false},
+			{handle: "articles.edit", expected: false},
+			{handle: "articles.edit", resourceType: "ARTICLE", resourceID: "ARTICLE_01", expected: true},
+			{handle: "articles.edit", resourceType: "ARTICLE", resourceID: "ARTICLE_02", expected: false},
+			{handle: "audit.read", expected: true},
+		}
+
+		for _, c := range cases {
+			var has bool
+
+			if c.resourceType == "" {
+				has, err = store.EntityHasPermission(ctx, "USER", "USER_01", c.handle)
+			} else {
+				has, err = store.EntityHasPermissionOnResource(ctx, "USER", "USER_01", c.handle, c.resourceType, c.resourceID)
+			}
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if has != c.expected {
+				t.Fatal(c.handle, c.resourceID, "expected", c.expected, "but got", has)
+			}
+		}
+
+		all, err := store.EntityHasAllPermissions(ctx, "USER", "USER_01", []string{"reports.sales", "audit.read"})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !all {
+			t.Fatal("USER_01 MUST have all of reports.sales and audit.read")
+		}
+
+		role.SetStatus(ROLE_STATUS_INACTIVE)
+
+		if err := store.RoleUpdate(ctx, role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		any, err := store.EntityHasAnyPermission(ctx, "USER", "USER_01", []string{"audit.read", "reports.payroll"})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if any {
+			t.Fatal("USER_01 MUST NOT have audit.read via an inactive role")
+		}
+	})
+}
+
+func TestConformanceTransactionRollback(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+		errRollback := errors.New("rollback")
+
+		err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+			if err := store.PermissionCreate(txCtx, NewPermission().SetHandle("articles.read").SetTitle("Read Articles").SetStatus(PERMISSION_STATUS_ACTIVE)); err != nil {
+				return err
+			}
+
+			return errRollback
+		})
+
+		if !errors.Is(err, errRollback) {
+			t.Fatal("expected the rollback error but got", err)
+		}
+
+		count, err := store.PermissionCount(ctx, NewPermissionQuery().SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 0 {
+			t.Fatal("the rolled back permission MUST NOT exist, got count", count)
+		}
+	})
+}
+
+func TestConformanceTransactionRollbackConcurrentWrite(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		if db := store.DB(); db != nil {
+			db.SetMaxOpenConns(1) // a single in-memory database, the write waits for the transaction
+		}
+
+		ctx := context.Background()
+		errRollback := errors.New("rollback")
+		done := make(chan error, 1)
+
+		err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+			if err := store.PermissionCreate(txCtx, NewPermission().SetHandle("articles.read").SetTitle("Read Articles").SetStatus(PERMISSION_STATUS_ACTIVE)); err != nil {
+				return err
+			}
+
+			go func() {
+				done <- store.PermissionCreate(ctx, NewPermission().SetHandle("users.read").SetTitle("Read Users").SetStatus(PERMISSION_STATUS_ACTIVE))
+			}()
+
+			// the memory store commits the write during the transaction
+			select {
+			case err := <-done:
+				done <- err
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			return errRollback
+		})
+
+		if !errors.Is(err, errRollback) {
+			t.Fatal("expected the rollback error but got", err)
+		}
+
+		if err := <-done; err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		handles := conformanceHandles(t, store, NewPermissionQuery().SetSoftDeletedIncluded(true))
+
+		if !reflect.DeepEqual(handles, []string{"users.read"}) {
+			t.Fatal("the concurrent write MUST be kept and the rolled back one reverted, got", handles)
+		}
+	})
+}
+
+func TestConformanceInvalidQuery(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+
+		if _, err := store.PermissionList(ctx, NewPermissionQuery().SetLimit(0)); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatal("expected ErrInvalidQuery for the permission query but got", err)
+		}
+
+		if _, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery().SetIDIn([]string{})); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatal("expected ErrInvalidQuery for the entity permission query but got", err)
+		}
+
+		if _, err := store.RoleCount(ctx, NewRoleQuery().SetOffset(-1)); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatal("expected ErrInvalidQuery for the role query but got", err)
+		}
+	})
+}
+
+func TestConformanceMemberships(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+
+		membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_02")
+		membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+		membershipTestCreate(t, store, "TEAM", "TEAM_01", "ORGANISATION", "ORGANISATION_01")
+		membershipTestCreate(t, store, "ORGANISATION", "ORGANISATION_01", "TEAM", "TEAM_01")
+
+		err := store.EntityMembershipCreate(ctx, NewEntityMembership().
+			SetMemberType("USER").
+			SetMemberID("USER_01").
+			SetParentType("TEAM").
+			SetParentID("TEAM_01"))
+
+		if !errors.Is(err, ErrDuplicateGrant) {
+			t.Fatal("expected ErrDuplicateGrant for the membership but got", err)
+		}
+
+		ancestors, err := store.EntityAncestors(ctx, "USER", "USER_01")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		expected := []EntityRef{
+			{EntityType: "TEAM", EntityID: "TEAM_01"},
+			{EntityType: "TEAM", EntityID: "TEAM_02"},
+			{EntityType: "ORGANISATION", EntityID: "ORGANISATION_01"},
+		}
+
+		if !reflect.DeepEqual(ancestors, expected) {
+			t.Fatal("unexpected ancestors", ancestors)
+		}
+
+		checkTestGrant(t, store, "ORGANISATION", "ORGANISATION_01", "articles.*", PERMISSION_STATUS_ACTIVE)
+		checkTestGrantWithEffect(t, store, "TEAM", "TEAM_02", "articles.delete", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+		for handle, expected := range map[string]bool{"articles.read": true, "articles.delete": false} {
+			has, err := store.EntityHasPermission(ctx, "USER", "USER_01", handle)
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if has != expected {
+				t.Fatal(handle, "expected", expected, "but got", has)
+			}
+		}
+	})
+}
+
+func TestConformanceExplain(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+
+		membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+		_, allow := checkTestGrant(t, store, "TEAM", "TEAM_01", "articles.*", PERMISSION_STATUS_ACTIVE)
+		_, deny := checkTestGrantWithEffect(t, store, "USER", "USER_01", "articles.delete", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+		for handle, expected := range map[string]string{"articles.read": allow.ID(), "articles.delete": deny.ID()} {
+			explanation, err := store.EntityPermissionExplain(ctx, "USER", "USER_01", handle)
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			has, err := store.EntityHasPermission(ctx, "USER", "USER_01", handle)
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if explanation.Allowed != has {
+				t.Fatal(handle, "explanation verdict", explanation.Allowed, "differs from the check", has)
+			}
+
+			deciding, found := lo.Find(explanation.Steps, func(step ExplainStep) bool {
+				return step.Deciding
+			})
+
+			if !found || deciding.EntityPermissionID != expected {
+				t.Fatal(handle, "unexpected deciding step", explanation.Steps)
+			}
+		}
+	})
+}
+
+func TestConformanceEntitiesWithPermission(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		expected := entitiesTestSeed(t, store)
+
+		entities, err := store.EntitiesWithPermission(context.Background(), "refunds.approve", EntitiesQuery{})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !reflect.DeepEqual(entities, expected) {
+			t.Fatal("expected", expected, "but got", entities)
+		}
+	})
+}
+
+func TestConformanceEntityPermissionHandles(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		entityHandlesTestSeed(t, store)
+
+		handles, err := store.EntityPermissionHandles(context.Background(), "USER", "USER_01")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		expected := []string{"articles.publish", "billing.*", "reports.read"}
+
+		if !reflect.DeepEqual(handles, expected) {
+			t.Fatal("expected", expected, "but got", handles)
+		}
+	})
+}
+
+func TestConformanceCheckBatch(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		requests := checkBatchTestSeed(t, store)
+
+		results, err := store.CheckBatch(context.Background(), requests)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		for i, result := range results {
+			if result.Allowed != checkBatchTestExpected(t, store, requests[i]) {
+				t.Fatal("result", i, "differs from the single check", result)
+			}
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/dromara/carbon/v2"
@@ -19,6 +20,10 @@ func (store *store) EntityPermissionCount(ctx context.Context, options EntityPer
 
 	q, _, err := store.entityPermissionSelectQuery(options)
 
+	if err != nil {
+		return -1, err
+	}
+
 	sqlStr, params, errSql := q.Prepared(true).
 		Limit(1).
 		Select(goqu.COUNT(goqu.Star()).As("count")).
@@ -56,31 +61,8 @@ func (store *store) EntityPermissionCreate(ctx context.Context, entityPermission
 		return errors.New("permissionstore > EntityPermissionCreate. entityPermission is nil")
 	}
 
-	if entityPermission.PermissionID() == "" {
-		return errors.New("permissionstore > EntityPermissionCreate. entityPermission permissionID is empty")
-	}
-
-	if entityPermission.EntityID() == "" {
-		return errors.New("permissionstore > EntityPermissionCreate. entityPermission entityID is empty")
-	}
-
-	if entityPermission.EntityType() == "" {
-		return errors.New("permissionstore > EntityPermissionCreate. entityPermission entityType is empty")
-	}
-
-	entityPermissionExists, err := store.EntityPermissionFindByEntityAndPermission(
-		ctx,
-		entityPermission.EntityType(),
-		entityPermission.EntityID(),
-		entityPermission.PermissionID(),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	if entityPermissionExists != nil {
-		return errors.New("permissionstore > EntityPermissionCreate. entityPermission with the same entityType-entityID-permissionID combination already exists")
+	if err := entityPermissionPrepareCreate(entityPermission); err != nil {
+		return errors.New("permissionstore > EntityPermissionCreate. " + err.Error())
 	}
 
 	entityPermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
@@ -101,10 +83,20 @@ func (store *store) EntityPermissionCreate(ctx context.Context, entityPermission
 	store.logSql("insert", sqlStr, params...)
 
 	if store.db == nil {
-		return errors.New("entityPermissionstore: database is nil")
+		return ErrNilDatabase
 	}
 
-	_, err = database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	err := store.executeAudited(ctx, AUDIT_OPERATION_CREATE, store.entityPermissionTableName, entityPermission.ID(), data, sqlStr, params...)
+
+	if isUniqueViolation(err) {
+		return newError("EntityPermissionCreate", ErrDuplicateGrant,
+			"entity_type", entityPermission.EntityType(),
+			"entity_id", entityPermission.EntityID(),
+			"permission_id", entityPermission.PermissionID(),
+			"resource_type", entityPermission.ResourceType(),
+			"resource_id", entityPermission.ResourceID(),
+		)
+	}
 
 	if err != nil {
 		return err
@@ -112,6 +104,8 @@ func (store *store) EntityPermissionCreate(ctx context.Context, entityPermission
 
 	entityPermission.MarkAsNotDirty()
 
+	store.cacheInvalidate(ctx, entityPermission.EntityType(), entityPermission.EntityID())
+
 	return nil
 }
 
@@ -140,33 +134,72 @@ func (store *store) EntityPermissionDeleteByID(ctx context.Context, id string) e
 
 	store.logSql("delete", sqlStr, params...)
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	var entityPermission EntityPermissionInterface
+
+	if store.cache != nil {
+		// the entity is needed to invalidate its cached permissions
+		found, err := store.EntityPermissionFindByID(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		entityPermission = found
+	}
+
+	err := store.executeAudited(ctx, AUDIT_OPERATION_DELETE, store.entityPermissionTableName, id, nil, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	if entityPermission != nil {
+		store.cacheInvalidate(ctx, entityPermission.EntityType(), entityPermission.EntityID())
+	}
 
-	return err
+	return nil
 }
 
+// EntityPermissionFindByEntityAndPermission returns the global (not resource scoped)
+// permission entity mapping by its entity type, entity ID and permission ID
 func (store *store) EntityPermissionFindByEntityAndPermission(
 	ctx context.Context,
 	entityType string,
 	entityID string,
 	permissionID string,
+) (entityPermission EntityPermissionInterface, err error) {
+	return store.EntityPermissionFindByEntityPermissionAndResource(ctx, entityType, entityID, permissionID, "", "")
+}
+
+// EntityPermissionFindByEntityPermissionAndResource returns the permission entity mapping
+// by its entity type, entity ID, permission ID and resource. Empty resource type and
+// resource ID find the global mapping
+func (store *store) EntityPermissionFindByEntityPermissionAndResource(
+	ctx context.Context,
+	entityType string,
+	entityID string,
+	permissionID string,
+	resourceType string,
+	resourceID string,
 ) (entityPermission EntityPermissionInterface, err error) {
 	if entityType == "" {
-		return nil, errors.New("EntityPermissionFindByEntityAndPermission entityType is empty")
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource entityType is empty")
 	}
 
 	if entityID == "" {
-		return nil, errors.New("EntityPermissionFindByEntityAndPermission entityID is empty")
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource entityID is empty")
 	}
 
 	if permissionID == "" {
-		return nil, errors.New("EntityPermissionFindByEntityAndPermission permissionID is empty")
+		return nil, errors.New("EntityPermissionFindByEntityPermissionAndResource permissionID is empty")
 	}
 
 	query := NewEntityPermissionQuery().
 		SetEntityType(entityType).
 		SetEntityID(entityID).
 		SetPermissionID(permissionID).
+		SetResourceType(resourceType).
+		SetResourceID(resourceID).
 		SetLimit(1)
 
 	list, err := store.EntityPermissionList(ctx, query)
@@ -202,6 +235,21 @@ func (store *store) EntityPermissionFindByID(ctx context.Context, id string) (en
 	return nil, nil
 }
 
+// EntityPermissionGetByID returns a permission entity mapping by its ID, ErrNotFound if it does not exist
+func (store *store) EntityPermissionGetByID(ctx context.Context, id string) (EntityPermissionInterface, error) {
+	entityPermission, err := store.EntityPermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entityPermission == nil {
+		return nil, newError("EntityPermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return entityPermission, nil
+}
+
 func (store *store) EntityPermissionList(ctx context.Context, query EntityPermissionQueryInterface) ([]EntityPermissionInterface, error) {
 	if query == nil {
 		return []EntityPermissionInterface{}, errors.New("at entityPermission list > entityPermission query is nil")
@@ -209,6 +257,10 @@ func (store *store) EntityPermissionList(ctx context.Context, query EntityPermis
 
 	q, columns, err := store.entityPermissionSelectQuery(query)
 
+	if err != nil {
+		return []EntityPermissionInterface{}, err
+	}
+
 	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
 
 	if errSql != nil {
@@ -218,7 +270,7 @@ func (store *store) EntityPermissionList(ctx context.Context, query EntityPermis
 	store.logSql("select", sqlStr, sqlParams...)
 
 	if store.db == nil {
-		return []EntityPermissionInterface{}, errors.New("entityPermissionstore: database is nil")
+		return []EntityPermissionInterface{}, ErrNilDatabase
 	}
 
 	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
@@ -237,6 +289,93 @@ func (store *store) EntityPermissionList(ctx context.Context, query EntityPermis
 	return list, nil
 }
 
+// EntityPermissionPurgeExpired permanently deletes the entity permissions,
+// which expired more than olderThan ago, and returns the number of deleted rows.
+// Use zero to delete all expired entity permissions.
+func (store *store) EntityPermissionPurgeExpired(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan < 0 {
+		return 0, errors.New("permissionstore > EntityPermissionPurgeExpired. olderThan " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	expiredBefore := carbon.CreateFromStdTime(time.Now().UTC().Add(-olderThan), carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.entityPermissionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_EXPIRES_AT).Lte(expiredBefore)).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	if store.db == nil {
+		return 0, ErrNilDatabase
+	}
+
+	if !store.auditEnabled() {
+		result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return result.RowsAffected()
+	}
+
+	var purged int64
+
+	err := store.transaction(ctx, func(txCtx database.QueryableContext) error {
+		selectSqlStr, selectParams, errSql := goqu.Dialect(store.dbDriverName).
+			From(store.entityPermissionTableName).
+			Prepared(true).
+			Where(goqu.C(COLUMN_EXPIRES_AT).Lte(expiredBefore)).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		store.logSql("select", selectSqlStr, selectParams...)
+
+		rows, err := database.SelectToMapString(txCtx, selectSqlStr, selectParams...)
+
+		if err != nil {
+			return err
+		}
+
+		result, err := database.Execute(txCtx, sqlStr, params...)
+
+		if err != nil {
+			return err
+		}
+
+		purged, err = result.RowsAffected()
+
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err = store.auditRecordCreate(txCtx, AUDIT_OPERATION_PURGE, store.entityPermissionTableName, row[COLUMN_ID], row, nil)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
 func (store *store) EntityPermissionSoftDelete(ctx context.Context, entityPermission EntityPermissionInterface) error {
 	if entityPermission == nil {
 		return errors.New("at entityPermission soft delete > entityPermission is nil")
@@ -248,7 +387,7 @@ func (store *store) EntityPermissionSoftDelete(ctx context.Context, entityPermis
 }
 
 func (store *store) EntityPermissionSoftDeleteByID(ctx context.Context, id string) error {
-	entityPermission, err := store.EntityPermissionFindByID(ctx, id)
+	entityPermission, err := store.EntityPermissionGetByID(ctx, id)
 
 	if err != nil {
 		return err
@@ -262,6 +401,18 @@ func (store *store) EntityPermissionUpdate(ctx context.Context, entityPermission
 		return errors.New("at entityPermission update > entityPermission is nil")
 	}
 
+	if !lo.Contains([]string{ENTITY_PERMISSION_EFFECT_ALLOW, ENTITY_PERMISSION_EFFECT_DENY}, entityPermission.Effect()) {
+		return errors.New("at entityPermission update > entityPermission effect must be allow or deny")
+	}
+
+	if (entityPermission.ResourceType() == "") != (entityPermission.ResourceID() == "") {
+		return errors.New("at entityPermission update > entityPermission resourceType and resourceID must be both set or both empty")
+	}
+
+	if err := entityPermissionValidateWindow(entityPermission); err != nil {
+		return errors.New("at entityPermission update > " + err.Error())
+	}
+
 	entityPermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
 
 	dataChanged := entityPermission.DataChanged()
@@ -286,14 +437,38 @@ func (store *store) EntityPermissionUpdate(ctx context.Context, entityPermission
 	store.logSql("update", sqlStr, params...)
 
 	if store.db == nil {
-		return errors.New("entityPermissionstore: database is nil")
+		return ErrNilDatabase
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	err := store.executeAudited(ctx, auditUpdateOperation(dataChanged), store.entityPermissionTableName, entityPermission.ID(), dataChanged, sqlStr, params...)
 
 	entityPermission.MarkAsNotDirty()
 
-	return err
+	if isUniqueViolation(err) {
+		return newError("EntityPermissionUpdate", ErrDuplicateGrant,
+			"id", entityPermission.ID(),
+			"entity_type", entityPermission.EntityType(),
+			"entity_id", entityPermission.EntityID(),
+			"permission_id", entityPermission.PermissionID(),
+			"resource_type", entityPermission.ResourceType(),
+			"resource_id", entityPermission.ResourceID(),
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, entityTypeChanged := dataChanged[COLUMN_ENTITY_TYPE]
+	_, entityIDChanged := dataChanged[COLUMN_ENTITY_ID]
+
+	if entityTypeChanged || entityIDChanged {
+		store.cacheFlush(ctx) // the previous entity is not known
+	} else {
+		store.cacheInvalidate(ctx, entityPermission.EntityType(), entityPermission.EntityID())
+	}
+
+	return nil
 }
 
 func (store *store) entityPermissionSelectQuery(options EntityPermissionQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
@@ -302,11 +477,15 @@ func (store *store) entityPermissionSelectQuery(options EntityPermissionQueryInt
 	}
 
 	if err := options.Validate(); err != nil {
-		return nil, nil, err
+		return nil, nil, newInvalidQueryError("EntityPermissionQuery", err)
 	}
 
 	q := goqu.Dialect(store.dbDriverName).From(store.entityPermissionTableName)
 
+	if options.HasEffect() {
+		q = q.Where(goqu.C(COLUMN_EFFECT).Eq(options.Effect()))
+	}
+
 	if options.HasEntityID() {
 		q = q.Where(goqu.C(COLUMN_ENTITY_ID).Eq(options.EntityID()))
 	}
@@ -327,6 +506,30 @@ func (store *store) entityPermissionSelectQuery(options EntityPermissionQueryInt
 		q = q.Where(goqu.C(COLUMN_PERMISSION_ID).Eq(options.PermissionID()))
 	}
 
+	if options.HasResourceType() {
+		q = q.Where(goqu.C(COLUMN_RESOURCE_TYPE).Eq(options.ResourceType()))
+	}
+
+	if options.HasResourceID() {
+		q = q.Where(goqu.C(COLUMN_RESOURCE_ID).Eq(options.ResourceID()))
+	}
+
+	if options.HasValidity() {
+		now := carbon.Now(carbon.UTC).ToDateTimeString()
+
+		switch options.Validity() {
+		case ENTITY_PERMISSION_VALIDITY_ACTIVE:
+			q = q.Where(
+				goqu.C(COLUMN_VALID_FROM).Lte(now),
+				goqu.C(COLUMN_EXPIRES_AT).Gt(now),
+			)
+		case ENTITY_PERMISSION_VALIDITY_EXPIRED:
+			q = q.Where(goqu.C(COLUMN_EXPIRES_AT).Lte(now))
+		case ENTITY_PERMISSION_VALIDITY_PENDING:
+			q = q.Where(goqu.C(COLUMN_VALID_FROM).Gt(now))
+		}
+	}
+
 	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
 		q = q.Where(
 			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
@@ -372,3 +575,63 @@ func (store *store) entityPermissionSelectQuery(options EntityPermissionQueryInt
 
 	return q.Where(softDeleted), columns, nil
 }
+
+// entityPermissionPrepareCreate validates the new entity permission
+// and sets the defaults of the effect and the validity window
+func entityPermissionPrepareCreate(entityPermission EntityPermissionInterface) error {
+	if entityPermission.PermissionID() == "" {
+		return errors.New("entityPermission permissionID is empty")
+	}
+
+	if entityPermission.EntityID() == "" {
+		return errors.New("entityPermission entityID is empty")
+	}
+
+	if entityPermission.EntityType() == "" {
+		return errors.New("entityPermission entityType is empty")
+	}
+
+	if entityPermission.Effect() == "" {
+		entityPermission.SetEffect(ENTITY_PERMISSION_EFFECT_ALLOW)
+	}
+
+	if !lo.Contains([]string{ENTITY_PERMISSION_EFFECT_ALLOW, ENTITY_PERMISSION_EFFECT_DENY}, entityPermission.Effect()) {
+		return errors.New("entityPermission effect must be allow or deny")
+	}
+
+	if (entityPermission.ResourceType() == "") != (entityPermission.ResourceID() == "") {
+		return errors.New("entityPermission resourceType and resourceID must be both set or both empty")
+	}
+
+	if entityPermission.ValidFrom() == "" {
+		entityPermission.SetValidFrom(sb.NULL_DATETIME)
+	}
+
+	if entityPermission.ExpiresAt() == "" {
+		entityPermission.SetExpiresAt(sb.MAX_DATETIME)
+	}
+
+	return entityPermissionValidateWindow(entityPermission)
+}
+
+// entityPermissionValidateWindow checks the validity window of the entity permission,
+// both ends must be valid datetimes and the window must end after it starts
+func entityPermissionValidateWindow(entityPermission EntityPermissionInterface) error {
+	validFrom := entityPermission.ValidFromCarbon()
+
+	if validFrom.Error != nil {
+		return errors.New("entityPermission validFrom is not a valid datetime")
+	}
+
+	expiresAt := entityPermission.ExpiresAtCarbon()
+
+	if expiresAt.Error != nil {
+		return errors.New("entityPermission expiresAt is not a valid datetime")
+	}
+
+	if expiresAt.Lte(validFrom) {
+		return errors.New("entityPermission expiresAt must be after validFrom")
+	}
+
+	return nil
+}
@@ -0,0 +1,395 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// bulkChunkSize is the maximum number of rows of a multi-row statement,
+// kept low enough for the bound parameter limits of all supported dialects
+const bulkChunkSize = 50
+
+// EntityRef identifies an entity, i.e. a user or a group
+type EntityRef struct {
+	EntityType string
+	EntityID   string
+}
+
+// BulkResult is the result of a single row of a bulk grant or revoke
+type BulkResult struct {
+	EntityType   string
+	EntityID     string
+	PermissionID string
+	ResourceType string
+	ResourceID   string
+
+	// EntityPermissionID is the ID of the created, revoked or already existing entity permission
+	EntityPermissionID string
+
+	// Status is BULK_STATUS_CREATED, BULK_STATUS_DUPLICATE, BULK_STATUS_REVOKED or BULK_STATUS_NOT_FOUND
+	Status string
+}
+
+// EntityPermissionCreateMany creates the entity permissions in one transaction
+// using multi-row inserts. Entity permissions, which already exist or repeat
+// an earlier one, are skipped and reported as duplicates.
+//
+// Returns a result for each entity permission in the given order.
+func (store *store) EntityPermissionCreateMany(ctx context.Context, entityPermissions []EntityPermissionInterface) ([]BulkResult, error) {
+	for index, entityPermission := range entityPermissions {
+		if entityPermission == nil {
+			return nil, errors.New("permissionstore > EntityPermissionCreateMany. entityPermission " + strconv.Itoa(index) + " is nil")
+		}
+
+		if err := entityPermissionPrepareCreate(entityPermission); err != nil {
+			return nil, errors.New("permissionstore > EntityPermissionCreateMany. entityPermission " + strconv.Itoa(index) + ": " + err.Error())
+		}
+	}
+
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	results := make([]BulkResult, len(entityPermissions))
+
+	err := store.transaction(ctx, func(txCtx database.QueryableContext) error {
+		seen := map[string]string{}
+
+		for _, chunk := range lo.Chunk(lo.Range(len(entityPermissions)), bulkChunkSize) {
+			existing, err := store.bulkExistingGrants(txCtx, lo.Map(chunk, func(index int, _ int) EntityPermissionInterface {
+				return entityPermissions[index]
+			}))
+
+			if err != nil {
+				return err
+			}
+
+			rows := []any{}
+			created := []EntityPermissionInterface{}
+
+			for _, index := range chunk {
+				entityPermission := entityPermissions[index]
+				key := bulkGrantKey(entityPermission)
+
+				results[index] = BulkResult{
+					EntityType:   entityPermission.EntityType(),
+					EntityID:     entityPermission.EntityID(),
+					PermissionID: entityPermission.PermissionID(),
+					ResourceType: entityPermission.ResourceType(),
+					ResourceID:   entityPermission.ResourceID(),
+				}
+
+				existingID, exists := existing[key]
+
+				if !exists {
+					existingID, exists = seen[key]
+				}
+
+				if exists {
+					results[index].EntityPermissionID = existingID
+					results[index].Status = BULK_STATUS_DUPLICATE
+					continue
+				}
+
+				entityPermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+				entityPermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+				seen[key] = entityPermission.ID()
+				rows = append(rows, entityPermission.Data())
+				created = append(created, entityPermission)
+
+				results[index].EntityPermissionID = entityPermission.ID()
+				results[index].Status = BULK_STATUS_CREATED
+			}
+
+			if len(rows) < 1 {
+				continue
+			}
+
+			sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+				Insert(store.entityPermissionTableName).
+				Prepared(true).
+				Rows(rows...).
+				ToSQL()
+
+			if errSql != nil {
+				return errSql
+			}
+
+			store.logSql("insert", sqlStr, params...)
+
+			_, err = database.Execute(txCtx, sqlStr, params...)
+
+			if isUniqueViolation(err) {
+				return newError("EntityPermissionCreateMany", ErrDuplicateGrant)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			for _, entityPermission := range created {
+				if !store.auditEnabled() {
+					continue
+				}
+
+				err := store.auditRecordCreate(txCtx, AUDIT_OPERATION_CREATE, store.entityPermissionTableName, entityPermission.ID(), nil, entityPermission.Data())
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entityPermission := range entityPermissions {
+		entityPermission.MarkAsNotDirty()
+	}
+
+	for _, result := range results {
+		if result.Status == BULK_STATUS_CREATED {
+			store.cacheInvalidate(ctx, result.EntityType, result.EntityID)
+		}
+	}
+
+	return results, nil
+}
+
+// GrantToEntities grants the permission to the entities in one transaction,
+// see EntityPermissionCreateMany. The permission must exist.
+func (store *store) GrantToEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error) {
+	return grantToEntities(ctx, store, permissionID, entities)
+}
+
+// grantToEntities implements GrantToEntities on top of the methods of the store
+func grantToEntities(ctx context.Context, store StoreInterface, permissionID string, entities []EntityRef) ([]BulkResult, error) {
+	if permissionID == "" {
+		return nil, errors.New("permissionstore > GrantToEntities. permissionID is empty")
+	}
+
+	entityPermissions := lo.Map(entities, func(entity EntityRef, _ int) EntityPermissionInterface {
+		return NewEntityPermission().
+			SetEntityType(entity.EntityType).
+			SetEntityID(entity.EntityID).
+			SetPermissionID(permissionID)
+	})
+
+	var results []BulkResult
+
+	err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := store.PermissionGetByID(txCtx, permissionID); err != nil {
+			return err
+		}
+
+		var err error
+		results, err = store.EntityPermissionCreateMany(txCtx, entityPermissions)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// RevokeFromEntities deletes the global allow grants of the permission to the
+// entities in one transaction using multi-row deletes. Entities without such
+// a grant are reported as not found, entities repeating an earlier revoked one
+// as duplicates.
+//
+// Returns a result for each entity in the given order.
+func (store *store) RevokeFromEntities(ctx context.Context, permissionID string, entities []EntityRef) ([]BulkResult, error) {
+	if permissionID == "" {
+		return nil, errors.New("permissionstore > RevokeFromEntities. permissionID is empty")
+	}
+
+	for index, entity := range entities {
+		if entity.EntityType == "" || entity.EntityID == "" {
+			return nil, errors.New("permissionstore > RevokeFromEntities. entity " + strconv.Itoa(index) + " entityType or entityID is empty")
+		}
+	}
+
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	results := make([]BulkResult, len(entities))
+
+	err := store.transaction(ctx, func(txCtx database.QueryableContext) error {
+		revoked := map[string]string{}
+
+		for _, chunk := range lo.Chunk(lo.Range(len(entities)), bulkChunkSize) {
+			entityPermissions := lo.Map(chunk, func(index int, _ int) EntityPermissionInterface {
+				return NewEntityPermission().
+					SetEntityType(entities[index].EntityType).
+					SetEntityID(entities[index].EntityID).
+					SetPermissionID(permissionID)
+			})
+
+			rows, err := store.bulkGrantRows(txCtx, entityPermissions)
+
+			if err != nil {
+				return err
+			}
+
+			rowsByKey := map[string]map[string]string{}
+
+			for _, row := range rows {
+				if row[COLUMN_EFFECT] == ENTITY_PERMISSION_EFFECT_ALLOW {
+					rowsByKey[bulkGrantKey(NewEntityPermissionFromExistingData(row))] = row
+				}
+			}
+
+			deleted := []map[string]string{}
+
+			for position, index := range chunk {
+				key := bulkGrantKey(entityPermissions[position])
+
+				results[index] = BulkResult{
+					EntityType:   entities[index].EntityType,
+					EntityID:     entities[index].EntityID,
+					PermissionID: permissionID,
+					Status:       BULK_STATUS_NOT_FOUND,
+				}
+
+				if revokedID, exists := revoked[key]; exists {
+					results[index].EntityPermissionID = revokedID
+					results[index].Status = BULK_STATUS_DUPLICATE
+					continue
+				}
+
+				row, exists := rowsByKey[key]
+
+				if !exists {
+					continue
+				}
+
+				revoked[key] = row[COLUMN_ID]
+				deleted = append(deleted, row)
+				results[index].EntityPermissionID = row[COLUMN_ID]
+				results[index].Status = BULK_STATUS_REVOKED
+			}
+
+			if len(deleted) < 1 {
+				continue
+			}
+
+			sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+				Delete(store.entityPermissionTableName).
+				Prepared(true).
+				Where(goqu.C(COLUMN_ID).In(lo.Map(deleted, func(row map[string]string, _ int) string {
+					return row[COLUMN_ID]
+				}))).
+				ToSQL()
+
+			if errSql != nil {
+				return errSql
+			}
+
+			store.logSql("delete", sqlStr, params...)
+
+			if _, err := database.Execute(txCtx, sqlStr, params...); err != nil {
+				return err
+			}
+
+			for _, row := range deleted {
+				if !store.auditEnabled() {
+					continue
+				}
+
+				err := store.auditRecordCreate(txCtx, AUDIT_OPERATION_DELETE, store.entityPermissionTableName, row[COLUMN_ID], row, nil)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Status == BULK_STATUS_REVOKED {
+			store.cacheInvalidate(ctx, result.EntityType, result.EntityID)
+		}
+	}
+
+	return results, nil
+}
+
+// bulkExistingGrants returns the IDs of the existing entity permissions,
+// which have the same entity, permission and resource as the given ones,
+// keyed by bulkGrantKey
+func (store *store) bulkExistingGrants(ctx database.QueryableContext, entityPermissions []EntityPermissionInterface) (map[string]string, error) {
+	rows, err := store.bulkGrantRows(ctx, entityPermissions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]string{}
+
+	for _, row := range rows {
+		existing[bulkGrantKey(NewEntityPermissionFromExistingData(row))] = row[COLUMN_ID]
+	}
+
+	return existing, nil
+}
+
+// bulkGrantRows returns the entity permissions, which are not soft deleted,
+// of the entities and permissions of the given ones. The rows may include
+// other combinations of these, so must be matched by bulkGrantKey
+func (store *store) bulkGrantRows(ctx database.QueryableContext, entityPermissions []EntityPermissionInterface) ([]map[string]string, error) {
+	entityIDs := lo.Uniq(lo.Map(entityPermissions, func(entityPermission EntityPermissionInterface, _ int) string {
+		return entityPermission.EntityID()
+	}))
+
+	permissionIDs := lo.Uniq(lo.Map(entityPermissions, func(entityPermission EntityPermissionInterface, _ int) string {
+		return entityPermission.PermissionID()
+	}))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.entityPermissionTableName).
+		Prepared(true).
+		Where(
+			goqu.C(COLUMN_ENTITY_ID).In(entityIDs),
+			goqu.C(COLUMN_PERMISSION_ID).In(permissionIDs),
+			goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	return database.SelectToMapString(ctx, sqlStr, params...)
+}
+
+// bulkGrantKey returns the key of the unique entity, permission and resource combination
+func bulkGrantKey(entityPermission EntityPermissionInterface) string {
+	return entityPermission.EntityType() + "\x00" +
+		entityPermission.EntityID() + "\x00" +
+		entityPermission.PermissionID() + "\x00" +
+		entityPermission.ResourceType() + "\x00" +
+		entityPermission.ResourceID()
+}
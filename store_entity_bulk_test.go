@@ -0,0 +1,243 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestStoreEntityPermissionCreateMany(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	existing := NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01")
+
+	if err := store.EntityPermissionCreate(context.Background(), existing); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityPermissions := []EntityPermissionInterface{
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_01").SetPermissionID("PERMISSION_01"),
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_02").SetPermissionID("PERMISSION_01"),
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_02").SetPermissionID("PERMISSION_01"),
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_02").SetPermissionID("PERMISSION_01").SetResourceType("article").SetResourceID("ARTICLE_01"),
+	}
+
+	// enough rows to need more than one chunk
+	for i := 0; i < bulkChunkSize+10; i++ {
+		entityPermissions = append(entityPermissions, NewEntityPermission().
+			SetEntityType("GROUP").
+			SetEntityID("GROUP_"+strconv.Itoa(i)).
+			SetPermissionID("PERMISSION_02"))
+	}
+
+	results, err := store.EntityPermissionCreateMany(context.Background(), entityPermissions)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(results) != len(entityPermissions) {
+		t.Fatal("expected a result per entity permission, found:", len(results))
+	}
+
+	if results[0].Status != BULK_STATUS_DUPLICATE || results[0].EntityPermissionID != existing.ID() {
+		t.Fatalf("existing grant MUST be reported as duplicate: %+v", results[0])
+	}
+
+	if results[1].Status != BULK_STATUS_CREATED || results[1].EntityPermissionID != entityPermissions[1].ID() {
+		t.Fatalf("unexpected result: %+v", results[1])
+	}
+
+	if results[2].Status != BULK_STATUS_DUPLICATE || results[2].EntityPermissionID != entityPermissions[1].ID() {
+		t.Fatalf("repeated grant MUST be reported as duplicate: %+v", results[2])
+	}
+
+	if results[3].Status != BULK_STATUS_CREATED {
+		t.Fatalf("resource scoped grant MUST be created: %+v", results[3])
+	}
+
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != int64(3+bulkChunkSize+10) {
+		t.Fatal("unexpected count:", count)
+	}
+
+	auditCount, err := store.AuditCount(context.Background(), NewAuditQuery().SetOperation(AUDIT_OPERATION_CREATE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if auditCount != count {
+		t.Fatal("each created grant MUST be audited, found:", auditCount)
+	}
+
+	_, err = store.EntityPermissionCreateMany(context.Background(), []EntityPermissionInterface{
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_03").SetPermissionID("PERMISSION_01"),
+		NewEntityPermission().SetEntityType("USER").SetPermissionID("PERMISSION_01"),
+	})
+
+	if err == nil {
+		t.Fatal("must return error as the entity ID is empty")
+	}
+}
+
+func TestStoreGrantToEntities(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles")
+
+	if err := store.PermissionCreate(context.Background(), permission); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entities := []EntityRef{
+		{EntityType: "USER", EntityID: "USER_01"},
+		{EntityType: "USER", EntityID: "USER_02"},
+		{EntityType: "GROUP", EntityID: "USER_01"},
+	}
+
+	results, err := store.GrantToEntities(context.Background(), permission.ID(), entities)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, result := range results {
+		if result.Status != BULK_STATUS_CREATED {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+
+	for _, entity := range entities {
+		has, err := store.EntityHasPermission(context.Background(), entity.EntityType, entity.EntityID, "articles.read")
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !has {
+			t.Fatal(entity.EntityType, entity.EntityID, "MUST have the permission")
+		}
+	}
+
+	_, err = store.GrantToEntities(context.Background(), "UNKNOWN_ID", entities)
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+}
+
+func TestStoreRevokeFromEntities(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles")
+
+	if err := store.PermissionCreate(context.Background(), permission); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.GrantToEntities(context.Background(), permission.ID(), []EntityRef{
+		{EntityType: "USER", EntityID: "USER_01"},
+		{EntityType: "USER", EntityID: "USER_02"},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// resource scoped grants are not revoked
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetPermissionID(permission.ID()).
+		SetResourceType("article").
+		SetResourceID("ARTICLE_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	results, err := store.RevokeFromEntities(context.Background(), permission.ID(), []EntityRef{
+		{EntityType: "USER", EntityID: "USER_01"},
+		{EntityType: "USER", EntityID: "USER_02"},
+		{EntityType: "USER", EntityID: "USER_02"},
+		{EntityType: "GROUP", EntityID: "USER_01"},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{BULK_STATUS_REVOKED, BULK_STATUS_REVOKED, BULK_STATUS_DUPLICATE, BULK_STATUS_NOT_FOUND}
+
+	for index, result := range results {
+		if result.Status != expected[index] {
+			t.Fatalf("expected %s at %d, found: %+v", expected[index], index, result)
+		}
+	}
+
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery().SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("only the resource scoped grant MUST remain, found:", count)
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("revoked grant MUST NOT allow")
+	}
+}
@@ -0,0 +1,81 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// EntityPermissionHandles returns the sorted handles of the active permissions, which are
+// granted to the entity, i.e. to put into a session instead of the permission IDs of
+// the entity permission mappings.
+//
+// The handles are resolved like in EntityHasPermission, with the direct grants in one
+// query joining the entity permission table to the permission table, plus the grants via
+// roles and memberships, when enabled. Only the global grants within their validity
+// window count and a handle denied by a deny grant is left out. Wildcard handles are
+// returned as granted, i.e. "billing.*", even if some of their descendants are denied,
+// match these with PermissionHandleMatch or check them with EntityHasPermission.
+func (store *store) EntityPermissionHandles(ctx context.Context, entityType string, entityID string) ([]string, error) {
+	return entityPermissionHandles(ctx, store.entityGrantsCached, entityType, entityID)
+}
+
+// EntityEffectivePermissions returns the active permissions with the handles
+// returned by EntityPermissionHandles, ordered by handle
+func (store *store) EntityEffectivePermissions(ctx context.Context, entityType string, entityID string) ([]PermissionInterface, error) {
+	return entityEffectivePermissions(ctx, store, entityType, entityID)
+}
+
+// entityPermissionHandles implements EntityPermissionHandles for the grants loaded by load
+func entityPermissionHandles(ctx context.Context, load grantLoader, entityType string, entityID string) ([]string, error) {
+	if entityType == "" {
+		return nil, errors.New("permissionstore > EntityPermissionHandles. entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("permissionstore > EntityPermissionHandles. entityID is empty")
+	}
+
+	grants, err := load(ctx, entityType, entityID, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := carbon.Now(carbon.UTC)
+
+	handles := lo.Uniq(lo.FilterMap(grants, func(g grant, _ int) (string, bool) {
+		return g.handle, !g.isDeny() && !g.isResourceScoped() && g.isValidAt(now)
+	}))
+
+	handles = lo.Filter(handles, func(handle string, _ int) bool {
+		return grantsAllow(grants, handle, "", "")
+	})
+
+	slices.Sort(handles)
+
+	return handles, nil
+}
+
+// entityEffectivePermissions implements EntityEffectivePermissions on top of the methods of the store
+func entityEffectivePermissions(ctx context.Context, store StoreInterface, entityType string, entityID string) ([]PermissionInterface, error) {
+	handles, err := store.EntityPermissionHandles(ctx, entityType, entityID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(handles) < 1 {
+		return []PermissionInterface{}, nil
+	}
+
+	return store.PermissionList(ctx, NewPermissionQuery().
+		SetHandleIn(handles).
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetOrderBy(COLUMN_HANDLE).
+		SetSortDirection(sb.ASC))
+}
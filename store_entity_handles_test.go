@@ -0,0 +1,176 @@
+package permissionstore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// entityHandlesTestSeed grants USER_01 the permissions "billing.*" and "reports.read"
+// directly, "articles.publish" via a role and "reports.read" also via a membership,
+// next to grants which do not count for the effective handles, i.e. "users.read"
+// inherited via the membership, but denied directly
+func entityHandlesTestSeed(t *testing.T, store StoreInterface) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	checkTestGrant(t, store, "USER", "USER_01", "billing.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_01", "reports.read", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_01", "users.manage", PERMISSION_STATUS_INACTIVE)
+	checkTestGrant(t, store, "TEAM", "TEAM_01", "users.read", PERMISSION_STATUS_ACTIVE)
+	checkTestGrantWithEffect(t, store, "USER", "USER_01", "users.read", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	checkTestGrantWithEffect(t, store, "USER", "USER_01", "billing.refund", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	checkTestGrant(t, store, "TEAM", "TEAM_01", "reports.read", PERMISSION_STATUS_ACTIVE)
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	_, softDeleted := checkTestGrant(t, store, "USER", "USER_01", "audit.read", PERMISSION_STATUS_ACTIVE)
+
+	if err := store.EntityPermissionSoftDelete(ctx, softDeleted); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, pending := checkTestGrant(t, store, "USER", "USER_01", "deploy.run", PERMISSION_STATUS_ACTIVE)
+
+	pending.SetValidFrom(carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC))
+
+	if err := store.EntityPermissionUpdate(ctx, pending); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, resourceScoped := checkTestGrant(t, store, "USER", "USER_01", "documents.edit", PERMISSION_STATUS_ACTIVE)
+
+	resourceScoped.SetResourceType("DOCUMENT").SetResourceID("DOCUMENT_01")
+
+	if err := store.EntityPermissionUpdate(ctx, resourceScoped); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.publish").
+		SetTitle("Publish articles")
+
+	if err := store.PermissionCreate(ctx, permission); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("editor").
+		SetTitle("Editor")
+
+	if err := store.RoleCreate(ctx, role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.RolePermissionCreate(ctx, NewRolePermission().
+		SetRoleID(role.ID()).
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(ctx, NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreEntityPermissionHandles(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityHandlesTestSeed(t, store)
+
+	handles, err := store.EntityPermissionHandles(context.Background(), "USER", "USER_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{"articles.publish", "billing.*", "reports.read"}
+
+	if !reflect.DeepEqual(handles, expected) {
+		t.Fatal("expected", expected, "but got", handles)
+	}
+
+	handles, err = store.EntityPermissionHandles(context.Background(), "USER", "USER_02")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(handles) != 0 {
+		t.Fatal("expected no handles but got", handles)
+	}
+
+	_, err = store.EntityPermissionHandles(context.Background(), "USER", "")
+
+	if err == nil {
+		t.Fatal("expected error for the empty entity ID")
+	}
+}
+
+func TestStoreEntityEffectivePermissions(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityHandlesTestSeed(t, store)
+
+	permissions, err := store.EntityEffectivePermissions(context.Background(), "USER", "USER_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	handles := lo.Map(permissions, func(permission PermissionInterface, _ int) string {
+		return permission.Handle()
+	})
+
+	expected := []string{"articles.publish", "billing.*", "reports.read"}
+
+	if !reflect.DeepEqual(handles, expected) {
+		t.Fatal("expected", expected, "but got", handles)
+	}
+
+	if permissions[0].Title() != "Publish articles" {
+		t.Fatal("expected the full permission but got", permissions[0].Data())
+	}
+
+	permissions, err = store.EntityEffectivePermissions(context.Background(), "USER", "USER_02")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(permissions) != 0 {
+		t.Fatal("expected no permissions but got", len(permissions))
+	}
+}
@@ -0,0 +1,216 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// EntitiesQuery are the options of EntitiesWithPermission
+type EntitiesQuery struct {
+	// EntityType selects the entities of the type only, all entities when empty
+	EntityType string
+
+	// Offset is the number of entities skipped
+	Offset int
+
+	// Limit is the maximum number of entities returned, no limit when 0
+	Limit int
+}
+
+// Validate returns an error if the offset or the limit is negative
+func (query EntitiesQuery) Validate() error {
+	if query.Offset < 0 {
+		return errors.New("entities query. offset cannot be negative")
+	}
+
+	if query.Limit < 0 {
+		return errors.New("entities query. limit cannot be negative")
+	}
+
+	return nil
+}
+
+// EntitiesWithPermission returns the distinct entities, which are granted the permission
+// with the given handle, ordered by entity type and entity ID.
+//
+// The entities are granted the permission the same way as in EntityHasPermission,
+// either directly, via wildcard handles, via their roles or, when memberships are
+// enabled, inherited from their ancestors. Deny grants are applied as well, so the
+// entities returned are exactly the ones for which EntityHasPermission returns true.
+//
+// The candidate entities holding an allow grant are found in one query per source of
+// grants, the members of these are walked one query per level of memberships, then
+// the grants of all candidates of the entity type are loaded together, the same way
+// as in CheckBatch. Offset and Limit apply to the entities granted the permission,
+// so the pages are stable as long as the grants do not change.
+func (store *store) EntitiesWithPermission(ctx context.Context, handle string, query EntitiesQuery) ([]EntityRef, error) {
+	entities, _, err := store.entitiesWithPermission(ctx, handle, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// EntitiesWithPermissionCount returns the number of distinct entities, which are granted
+// the permission with the given handle, see EntitiesWithPermission. Offset and Limit are ignored
+func (store *store) EntitiesWithPermissionCount(ctx context.Context, handle string, query EntitiesQuery) (int64, error) {
+	_, total, err := store.entitiesWithPermission(ctx, handle, query)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return total, nil
+}
+
+// entitiesWithPermission returns the page of the entities matching the query, which are
+// granted the permission, and the total number of these
+func (store *store) entitiesWithPermission(ctx context.Context, handle string, query EntitiesQuery) ([]EntityRef, int64, error) {
+	var members membershipLoader
+
+	if store.membershipsEnabled() {
+		members = store.entityMembershipMembers
+	}
+
+	return entitiesWithPermission(ctx, store.entityGrantsBatchCached, store.grantHolders, members, store.membershipMaxDepth, handle, query)
+}
+
+// grantHoldersLoader returns the entities holding a global allow grant on any of
+// the permissions with the given handles, either directly or via their roles,
+// in any order. The grants may be pending, these are left to the checks
+type grantHoldersLoader func(ctx context.Context, handles []string) ([]EntityRef, error)
+
+// entitiesWithPermission implements EntitiesWithPermission and EntitiesWithPermissionCount,
+// returning the page selected by the offset and the limit of the query and the total number
+// of the entities granted the permission from a single evaluation of the candidates.
+// The members of the grant holders are walked with members, unless it is nil
+func entitiesWithPermission(ctx context.Context, load batchGrantLoader, holders grantHoldersLoader, members membershipLoader, maxDepth int, handle string, query EntitiesQuery) ([]EntityRef, int64, error) {
+	if handle == "" {
+		return nil, -1, errors.New("permissionstore > EntitiesWithPermission. handle is empty")
+	}
+
+	if err := PermissionHandleValidate(handle); err != nil {
+		return nil, -1, errors.New("permissionstore > EntitiesWithPermission. " + err.Error())
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, -1, newInvalidQueryError("EntitiesQuery", err)
+	}
+
+	handles := permissionHandlePatterns(handle)
+
+	candidates, err := holders(ctx, handles)
+
+	if err != nil {
+		return nil, -1, err
+	}
+
+	candidates = lo.Uniq(candidates)
+
+	if members != nil {
+		descendants, err := membershipWalk(ctx, members, candidates, maxDepth)
+
+		if err != nil {
+			return nil, -1, err
+		}
+
+		candidates = append(candidates, descendants...)
+	}
+
+	if query.EntityType != "" {
+		candidates = lo.Filter(candidates, func(candidate EntityRef, _ int) bool {
+			return candidate.EntityType == query.EntityType
+		})
+	}
+
+	if len(candidates) < 1 {
+		return []EntityRef{}, 0, nil
+	}
+
+	slices.SortFunc(candidates, entityRefCompare)
+
+	grants, err := load(ctx, candidates, handles)
+
+	if err != nil {
+		return nil, -1, err
+	}
+
+	entities := lo.Filter(candidates, func(candidate EntityRef, _ int) bool {
+		return grantsAllow(grants[candidate], handle, "", "")
+	})
+
+	return entitiesPage(entities, query), int64(len(entities)), nil
+}
+
+// entitiesPage returns the page of the entities selected by the offset and the limit of the query
+func entitiesPage(entities []EntityRef, query EntitiesQuery) []EntityRef {
+	entities = entities[min(query.Offset, len(entities)):]
+
+	if query.Limit > 0 {
+		entities = entities[:min(query.Limit, len(entities))]
+	}
+
+	return entities
+}
+
+// grantHolders returns the entities holding a global allow grant on any of the
+// permissions with the given handles, see grantHoldersLoader
+func (store *store) grantHolders(ctx context.Context, handles []string) ([]EntityRef, error) {
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	queries := []*goqu.SelectDataset{
+		store.directGrantsQuery().
+			Where(
+				goqu.I("ep."+COLUMN_EFFECT).Neq(ENTITY_PERMISSION_EFFECT_DENY),
+				goqu.I("ep."+COLUMN_RESOURCE_TYPE).Eq(""),
+				goqu.I("ep."+COLUMN_RESOURCE_ID).Eq(""),
+				goqu.I("p."+COLUMN_HANDLE).In(handles),
+			).
+			SelectDistinct(
+				goqu.I("ep."+COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE),
+				goqu.I("ep."+COLUMN_ENTITY_ID).As(COLUMN_ENTITY_ID),
+			),
+	}
+
+	if store.rolesEnabled() {
+		queries = append(queries, store.roleGrantsQuery().
+			Where(goqu.I("p."+COLUMN_HANDLE).In(handles)).
+			SelectDistinct(
+				goqu.I("er."+COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE),
+				goqu.I("er."+COLUMN_ENTITY_ID).As(COLUMN_ENTITY_ID),
+			))
+	}
+
+	holders := []EntityRef{}
+
+	for _, q := range queries {
+		sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+		if errSql != nil {
+			return nil, errSql
+		}
+
+		store.logSql("select", sqlStr, params...)
+
+		rows, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			holders = append(holders, EntityRef{EntityType: row[COLUMN_ENTITY_TYPE], EntityID: row[COLUMN_ENTITY_ID]})
+		}
+	}
+
+	return holders, nil
+}
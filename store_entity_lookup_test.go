@@ -0,0 +1,246 @@
+package permissionstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// entitiesTestSeed grants "refunds.approve" to some of the entities in every
+// supported way and returns the entities, which are granted the permission
+func entitiesTestSeed(t *testing.T, store StoreInterface) []EntityRef {
+	t.Helper()
+
+	ctx := context.Background()
+
+	permission, _ := checkTestGrant(t, store, "USER", "USER_01", "refunds.approve", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_02", "refunds.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_03", "refunds.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrantWithEffect(t, store, "USER", "USER_03", "refunds.approve", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	checkTestGrant(t, store, "USER", "USER_09", "refunds.read", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "TEAM", "TEAM_01", "refunds.approve", PERMISSION_STATUS_ACTIVE)
+
+	_, softDeleted := checkTestGrant(t, store, "USER", "USER_04", "refunds.approve", PERMISSION_STATUS_ACTIVE)
+
+	if err := store.EntityPermissionSoftDelete(ctx, softDeleted); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_07").
+		SetPermissionID(permission.ID()).
+		SetResourceType("REFUND").
+		SetResourceID("REFUND_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("finance").
+		SetTitle("Finance")
+
+	if err := store.RoleCreate(ctx, role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionCreate(ctx, NewRolePermission().
+		SetRoleID(role.ID()).
+		SetPermissionID(permission.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(ctx, NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_05").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	membershipTestCreate(t, store, "USER", "USER_06", "TEAM", "TEAM_01")
+
+	return []EntityRef{
+		{EntityType: "TEAM", EntityID: "TEAM_01"},
+		{EntityType: "USER", EntityID: "USER_01"},
+		{EntityType: "USER", EntityID: "USER_02"},
+		{EntityType: "USER", EntityID: "USER_05"},
+		{EntityType: "USER", EntityID: "USER_06"},
+	}
+}
+
+func TestStoreEntitiesWithPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	expected := entitiesTestSeed(t, store)
+
+	entities, err := store.EntitiesWithPermission(ctx, "refunds.approve", EntitiesQuery{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !reflect.DeepEqual(entities, expected) {
+		t.Fatal("expected", expected, "but got", entities)
+	}
+
+	entities, err = store.EntitiesWithPermission(ctx, "refunds.approve", EntitiesQuery{EntityType: "USER", Offset: 1, Limit: 2})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !reflect.DeepEqual(entities, expected[2:4]) {
+		t.Fatal("expected", expected[2:4], "but got", entities)
+	}
+
+	count, err := store.EntitiesWithPermissionCount(ctx, "refunds.approve", EntitiesQuery{EntityType: "USER", Limit: 1})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 4 {
+		t.Fatal("expected 4 users but got", count)
+	}
+
+	entities, err = store.EntitiesWithPermission(ctx, "refunds.approve", EntitiesQuery{Offset: 10})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(entities) != 0 {
+		t.Fatal("expected no entities past the end but got", entities)
+	}
+}
+
+func TestStoreEntitiesWithPermission_Invalid(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = store.EntitiesWithPermission(context.Background(), "", EntitiesQuery{})
+
+	if err == nil {
+		t.Fatal("expected error for the empty handle")
+	}
+
+	_, err = store.EntitiesWithPermission(context.Background(), "refunds.approve", EntitiesQuery{Limit: -1})
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("expected ErrInvalidQuery but got", err)
+	}
+
+	_, err = store.EntitiesWithPermissionCount(context.Background(), "refunds.approve", EntitiesQuery{Offset: -1})
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("expected ErrInvalidQuery but got", err)
+	}
+}
+
+func TestStoreEntitiesWithPermission_Queries(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	expected := entitiesTestSeed(t, store)
+
+	permission, err := store.PermissionFindByHandle(ctx, "refunds.approve")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// many more users holding the permission directly and via TEAM_01
+	holders := []EntityRef{}
+
+	for i := 1; i <= 300; i++ {
+		entity := EntityRef{EntityType: "USER", EntityID: fmt.Sprintf("USER_1%03d", i)}
+
+		if i%2 == 0 {
+			membershipTestCreate(t, store, entity.EntityType, entity.EntityID, "TEAM", "TEAM_01")
+		} else {
+			holders = append(holders, entity)
+		}
+
+		expected = append(expected, entity)
+	}
+
+	if _, err := store.GrantToEntities(ctx, permission.ID(), holders); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	slices.SortFunc(expected, entityRefCompare)
+
+	var log bytes.Buffer
+
+	checkBatchTestLogTo(store, &log)
+
+	entities, err := store.EntitiesWithPermission(ctx, "refunds.approve", EntitiesQuery{Offset: 10, Limit: 20})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the direct and the role grant holders, then one query per level and entity type
+	// for the members of the holders, the parents of the candidates, their direct
+	// grants and their grants via roles
+	if queries := strings.Count(log.String(), "sql: select"); queries != 11 {
+		t.Fatal("expected 11 queries for", len(expected), "entities but got", queries)
+	}
+
+	if !reflect.DeepEqual(entities, expected[10:30]) {
+		t.Fatal("expected", expected[10:30], "but got", entities)
+	}
+
+	count, err := store.EntitiesWithPermissionCount(ctx, "refunds.approve", EntitiesQuery{Offset: 10, Limit: 20})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != int64(len(expected)) {
+		t.Fatal("expected", len(expected), "entities but got", count)
+	}
+}
@@ -0,0 +1,496 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// EntityAncestors returns the entities the entity is a member of, directly or
+// transitively via the memberships of its parents, up to MembershipMaxDepth
+// memberships away. The ancestors are ordered by depth, cycles are ignored
+func (store *store) EntityAncestors(ctx context.Context, entityType string, entityID string) ([]EntityRef, error) {
+	if entityType == "" {
+		return nil, errors.New("permissionstore > EntityAncestors. entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, errors.New("permissionstore > EntityAncestors. entityID is empty")
+	}
+
+	if !store.membershipsEnabled() {
+		return nil, errors.New("permissionstore: memberships are not enabled")
+	}
+
+	return entityAncestors(ctx, store.entityMembershipParents, EntityRef{EntityType: entityType, EntityID: entityID}, store.membershipMaxDepth)
+}
+
+func (store *store) EntityMembershipCount(ctx context.Context, options EntityMembershipQueryInterface) (int64, error) {
+	options.SetCountOnly(true)
+
+	q, _, err := store.entityMembershipSelectQuery(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		Limit(1).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, nil
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return -1, nil
+	}
+
+	countStr := mapped[0]["count"]
+
+	i, err := strconv.ParseInt(countStr, 10, 64)
+
+	if err != nil {
+		return -1, err
+
+	}
+
+	return i, nil
+}
+
+func (store *store) EntityMembershipCreate(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("permissionstore > EntityMembershipCreate. entityMembership is nil")
+	}
+
+	if !store.membershipsEnabled() {
+		return errors.New("permissionstore: memberships are not enabled")
+	}
+
+	if err := entityMembershipValidate(entityMembership); err != nil {
+		return errors.New("permissionstore > EntityMembershipCreate. " + err.Error())
+	}
+
+	entityMembershipExists, err := store.EntityMembershipFindByMemberAndParent(
+		ctx,
+		entityMembership.MemberType(),
+		entityMembership.MemberID(),
+		entityMembership.ParentType(),
+		entityMembership.ParentID(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if entityMembershipExists != nil {
+		return newError("EntityMembershipCreate", ErrDuplicateGrant, "member_type", entityMembership.MemberType(), "member_id", entityMembership.MemberID(), "parent_type", entityMembership.ParentType(), "parent_id", entityMembership.ParentID())
+	}
+
+	entityMembership.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	entityMembership.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	data := entityMembership.Data()
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.entityMembershipTableName).
+		Prepared(true).
+		Rows(data).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err = store.executeAudited(ctx, AUDIT_OPERATION_CREATE, store.entityMembershipTableName, entityMembership.ID(), data, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	entityMembership.MarkAsNotDirty()
+
+	store.cacheFlush(ctx) // the members of the member inherit the permissions too
+
+	return nil
+}
+
+func (store *store) EntityMembershipDelete(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("entityMembership is nil")
+	}
+
+	return store.EntityMembershipDeleteByID(ctx, entityMembership.ID())
+}
+
+func (store *store) EntityMembershipDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("entityMembership id is empty")
+	}
+
+	if !store.membershipsEnabled() {
+		return errors.New("permissionstore: memberships are not enabled")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.entityMembershipTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	err := store.executeAudited(ctx, AUDIT_OPERATION_DELETE, store.entityMembershipTableName, id, nil, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) EntityMembershipFindByMemberAndParent(
+	ctx context.Context,
+	memberType string,
+	memberID string,
+	parentType string,
+	parentID string,
+) (entityMembership EntityMembershipInterface, err error) {
+	if memberType == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent memberType is empty")
+	}
+
+	if memberID == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent memberID is empty")
+	}
+
+	if parentType == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent parentType is empty")
+	}
+
+	if parentID == "" {
+		return nil, errors.New("EntityMembershipFindByMemberAndParent parentID is empty")
+	}
+
+	query := NewEntityMembershipQuery().
+		SetMemberType(memberType).
+		SetMemberID(memberID).
+		SetParentType(parentType).
+		SetParentID(parentID).
+		SetLimit(1)
+
+	list, err := store.EntityMembershipList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *store) EntityMembershipFindByID(ctx context.Context, id string) (entityMembership EntityMembershipInterface, err error) {
+	if id == "" {
+		return nil, errors.New("entityMembership id is empty")
+	}
+
+	query := NewEntityMembershipQuery().SetID(id).SetLimit(1)
+
+	list, err := store.EntityMembershipList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// EntityMembershipGetByID returns an entity role mapping by its ID, ErrNotFound if it does not exist
+func (store *store) EntityMembershipGetByID(ctx context.Context, id string) (EntityMembershipInterface, error) {
+	entityMembership, err := store.EntityMembershipFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entityMembership == nil {
+		return nil, newError("EntityMembershipGetByID", ErrNotFound, "id", id)
+	}
+
+	return entityMembership, nil
+}
+
+func (store *store) EntityMembershipList(ctx context.Context, query EntityMembershipQueryInterface) ([]EntityMembershipInterface, error) {
+	if query == nil {
+		return []EntityMembershipInterface{}, errors.New("at entityMembership list > entityMembership query is nil")
+	}
+
+	q, columns, err := store.entityMembershipSelectQuery(query)
+
+	if err != nil {
+		return []EntityMembershipInterface{}, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+	if errSql != nil {
+		return []EntityMembershipInterface{}, nil
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return []EntityMembershipInterface{}, ErrNilDatabase
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []EntityMembershipInterface{}, err
+	}
+
+	list := []EntityMembershipInterface{}
+
+	lo.ForEach(modelMaps, func(modelMap map[string]string, index int) {
+		model := NewEntityMembershipFromExistingData(modelMap)
+		list = append(list, model)
+	})
+
+	return list, nil
+}
+
+func (store *store) EntityMembershipSoftDelete(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("at entityMembership soft delete > entityMembership is nil")
+	}
+
+	entityMembership.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.EntityMembershipUpdate(ctx, entityMembership)
+}
+
+func (store *store) EntityMembershipSoftDeleteByID(ctx context.Context, id string) error {
+	entityMembership, err := store.EntityMembershipGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.EntityMembershipSoftDelete(ctx, entityMembership)
+}
+
+func (store *store) EntityMembershipUpdate(ctx context.Context, entityMembership EntityMembershipInterface) error {
+	if entityMembership == nil {
+		return errors.New("at entityMembership update > entityMembership is nil")
+	}
+
+	if !store.membershipsEnabled() {
+		return errors.New("permissionstore: memberships are not enabled")
+	}
+
+	if err := entityMembershipValidate(entityMembership); err != nil {
+		return errors.New("permissionstore > EntityMembershipUpdate. " + err.Error())
+	}
+
+	entityMembership.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := entityMembership.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.entityMembershipTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(entityMembership.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err := store.executeAudited(ctx, auditUpdateOperation(dataChanged), store.entityMembershipTableName, entityMembership.ID(), dataChanged, sqlStr, params...)
+
+	entityMembership.MarkAsNotDirty()
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) entityMembershipSelectQuery(options EntityMembershipQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
+	if options == nil {
+		return nil, nil, errors.New("entityMembership options is nil")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, nil, newInvalidQueryError("EntityMembershipQuery", err)
+	}
+
+	if !store.membershipsEnabled() {
+		return nil, nil, errors.New("permissionstore: memberships are not enabled")
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.entityMembershipTableName)
+
+	if options.HasMemberID() {
+		q = q.Where(goqu.C(COLUMN_MEMBER_ID).Eq(options.MemberID()))
+	}
+
+	if options.HasMemberType() {
+		q = q.Where(goqu.C(COLUMN_MEMBER_TYPE).Eq(options.MemberType()))
+	}
+
+	if options.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(options.ID()))
+	}
+
+	if options.HasIDIn() {
+		q = q.Where(goqu.C(COLUMN_ID).In(options.IDIn()))
+	}
+
+	if options.HasParentID() {
+		q = q.Where(goqu.C(COLUMN_PARENT_ID).Eq(options.ParentID()))
+	}
+
+	if options.HasParentType() {
+		q = q.Where(goqu.C(COLUMN_PARENT_TYPE).Eq(options.ParentType()))
+	}
+
+	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
+		q = q.Where(
+			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
+			goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
+		)
+	} else if options.HasCreatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
+	} else if options.HasCreatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+	}
+
+	if !options.IsCountOnly() {
+		if options.HasLimit() {
+			q = q.Limit(cast.ToUint(options.Limit()))
+		}
+
+		if options.HasOffset() {
+			q = q.Offset(cast.ToUint(options.Offset()))
+		}
+	}
+
+	if options.HasOrderBy() {
+		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
+		if strings.EqualFold(sort, sb.ASC) {
+			q = q.Order(goqu.I(options.OrderBy()).Asc())
+		} else {
+			q = q.Order(goqu.I(options.OrderBy()).Desc())
+		}
+	}
+
+	columns = []any{}
+
+	for _, column := range options.Columns() {
+		columns = append(columns, column)
+	}
+
+	if options.SoftDeletedIncluded() {
+		return q, columns, nil // soft deleted entityMemberships requested specifically
+	}
+
+	softDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).
+		Gt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	return q.Where(softDeleted), columns, nil
+}
+
+// entityMembershipParents returns the parents of the entities via the memberships,
+// which are not soft deleted, see membershipLoader
+func (store *store) entityMembershipParents(ctx context.Context, entities []EntityRef) ([]EntityRef, error) {
+	return store.entityMembershipLinked(ctx, entities, COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID)
+}
+
+// entityMembershipMembers returns the members of the entities via the memberships,
+// which are not soft deleted, see membershipLoader
+func (store *store) entityMembershipMembers(ctx context.Context, entities []EntityRef) ([]EntityRef, error) {
+	return store.entityMembershipLinked(ctx, entities, COLUMN_PARENT_TYPE, COLUMN_PARENT_ID, COLUMN_MEMBER_TYPE, COLUMN_MEMBER_ID)
+}
+
+// entityMembershipLinked returns the distinct entities in the columns toType and toID
+// of the memberships, which are not soft deleted, having one of the entities in
+// the columns fromType and fromID. The entities are queried one entity type and
+// chunk of entity IDs at a time, see entityRefConditions
+func (store *store) entityMembershipLinked(ctx context.Context, entities []EntityRef, fromType string, fromID string, toType string, toID string) ([]EntityRef, error) {
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	linked := []EntityRef{}
+
+	for _, condition := range entityRefConditions(fromType, fromID, entities) {
+		q := goqu.Dialect(store.dbDriverName).
+			From(store.entityMembershipTableName).
+			Where(condition, goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+			SelectDistinct(toType, toID)
+
+		rows, err := store.checkBatchSelect(ctx, q)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			linked = append(linked, EntityRef{EntityType: row[toType], EntityID: row[toID]})
+		}
+	}
+
+	return linked, nil
+}
@@ -0,0 +1,490 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// membershipTestCreate makes the member entity a member of the parent entity
+func membershipTestCreate(t *testing.T, store StoreInterface, memberType string, memberID string, parentType string, parentID string) EntityMembershipInterface {
+	t.Helper()
+
+	entityMembership := NewEntityMembership().
+		SetMemberType(memberType).
+		SetMemberID(memberID).
+		SetParentType(parentType).
+		SetParentID(parentID)
+
+	err := store.EntityMembershipCreate(context.Background(), entityMembership)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return entityMembership
+}
+
+func TestStoreEntityMembershipCount(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	count, err := store.EntityMembershipCount(context.Background(), NewEntityMembershipQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+	membershipTestCreate(t, store, "USER", "USER_02", "TEAM", "TEAM_01")
+	membershipTestCreate(t, store, "TEAM", "TEAM_01", "ORGANISATION", "ORGANISATION_01")
+
+	count, err = store.EntityMembershipCount(context.Background(), NewEntityMembershipQuery().
+		SetParentType("TEAM").
+		SetParentID("TEAM_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected count:", count)
+	}
+}
+
+func TestStoreEntityMembershipCreate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityMembership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	found, err := store.EntityMembershipGetByID(context.Background(), entityMembership.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found.MemberType() != "USER" || found.MemberID() != "USER_01" || found.ParentType() != "TEAM" || found.ParentID() != "TEAM_01" {
+		t.Fatal("unexpected membership:", found.Data())
+	}
+
+	err = store.EntityMembershipCreate(context.Background(), NewEntityMembership().
+		SetMemberType("TEAM").
+		SetMemberID("TEAM_01").
+		SetParentType("TEAM").
+		SetParentID("TEAM_01"))
+
+	if err == nil || !strings.Contains(err.Error(), "same entity") {
+		t.Fatal("an entity MUST NOT be a member of itself, got:", err)
+	}
+
+	err = store.EntityMembershipCreate(context.Background(), NewEntityMembership().
+		SetMemberType("USER").
+		SetMemberID("USER_01").
+		SetParentType("TEAM"))
+
+	if err == nil || !strings.Contains(err.Error(), "parentID is empty") {
+		t.Fatal("expected parentID is empty error, got:", err)
+	}
+}
+
+func TestStoreEntityMembershipCreate_Duplicate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityMembership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	err = store.EntityMembershipCreate(context.Background(), NewEntityMembership().
+		SetMemberType("USER").
+		SetMemberID("USER_01").
+		SetParentType("TEAM").
+		SetParentID("TEAM_01"))
+
+	if !errors.Is(err, ErrDuplicateGrant) {
+		t.Fatal("expected ErrDuplicateGrant, got:", err)
+	}
+
+	err = store.EntityMembershipSoftDelete(context.Background(), entityMembership)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+}
+
+func TestStoreEntityMembershipDeleteByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityMembership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	err = store.EntityMembershipDeleteByID(context.Background(), entityMembership.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.EntityMembershipFindByID(context.Background(), entityMembership.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found != nil {
+		t.Fatal("deleted membership MUST NOT be found")
+	}
+
+	count, err := store.EntityMembershipCount(context.Background(), NewEntityMembershipQuery().SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("unexpected count:", count)
+	}
+}
+
+func TestStoreEntityMembershipFindByMemberAndParent(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityMembership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_02")
+
+	found, err := store.EntityMembershipFindByMemberAndParent(context.Background(), "USER", "USER_01", "TEAM", "TEAM_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found == nil || found.ID() != entityMembership.ID() {
+		t.Fatal("membership MUST be found")
+	}
+
+	found, err = store.EntityMembershipFindByMemberAndParent(context.Background(), "USER", "USER_02", "TEAM", "TEAM_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found != nil {
+		t.Fatal("membership MUST NOT be found")
+	}
+}
+
+func TestStoreEntityMembershipList(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_02")
+	membershipTestCreate(t, store, "USER", "USER_02", "TEAM", "TEAM_02")
+
+	list, err := store.EntityMembershipList(context.Background(), NewEntityMembershipQuery().
+		SetMemberType("USER").
+		SetMemberID("USER_01").
+		SetOrderBy(COLUMN_PARENT_ID))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 || list[0].ParentID() != "TEAM_02" || list[1].ParentID() != "TEAM_01" {
+		t.Fatal("unexpected memberships:", len(list))
+	}
+
+	_, err = store.EntityMembershipList(context.Background(), NewEntityMembershipQuery().SetParentID(""))
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("expected ErrInvalidQuery, got:", err)
+	}
+}
+
+func TestStoreEntityMembershipSoftDeleteByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityMembership := membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	err = store.EntityMembershipSoftDeleteByID(context.Background(), entityMembership.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.EntityMembershipFindByID(context.Background(), entityMembership.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found != nil {
+		t.Fatal("soft deleted membership MUST NOT be found")
+	}
+
+	list, err := store.EntityMembershipList(context.Background(), NewEntityMembershipQuery().
+		SetID(entityMembership.ID()).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 || !list[0].IsSoftDeleted() {
+		t.Fatal("soft deleted membership MUST be listed when included")
+	}
+}
+
+func TestStoreEntityMembership_NotEnabled(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.EntityAncestors(context.Background(), "USER", "USER_01")
+
+	if err == nil || !strings.Contains(err.Error(), "memberships are not enabled") {
+		t.Fatal("expected memberships are not enabled error, got:", err)
+	}
+
+	checkTestGrant(t, store, "USER", "USER_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST have permission articles.read without memberships")
+	}
+}
+
+func TestStoreEntityAncestors(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		EntityMembershipTableName: "permissions_entity_membership_table",
+		MembershipMaxDepth:        3,
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_02")
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+	membershipTestCreate(t, store, "TEAM", "TEAM_01", "DEPARTMENT", "DEPARTMENT_01")
+	membershipTestCreate(t, store, "TEAM", "TEAM_02", "DEPARTMENT", "DEPARTMENT_01")
+	membershipTestCreate(t, store, "DEPARTMENT", "DEPARTMENT_01", "ORGANISATION", "ORGANISATION_01")
+	membershipTestCreate(t, store, "ORGANISATION", "ORGANISATION_01", "HOLDING", "HOLDING_01")
+
+	// a cycle back to the first team
+	membershipTestCreate(t, store, "DEPARTMENT", "DEPARTMENT_01", "TEAM", "TEAM_01")
+
+	ancestors, err := store.EntityAncestors(context.Background(), "USER", "USER_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []EntityRef{
+		{EntityType: "TEAM", EntityID: "TEAM_01"},
+		{EntityType: "TEAM", EntityID: "TEAM_02"},
+		{EntityType: "DEPARTMENT", EntityID: "DEPARTMENT_01"},
+		{EntityType: "ORGANISATION", EntityID: "ORGANISATION_01"},
+	}
+
+	if !reflect.DeepEqual(ancestors, expected) {
+		t.Fatal("unexpected ancestors, the holding is beyond the maximum depth:", ancestors)
+	}
+
+	ancestors, err = store.EntityAncestors(context.Background(), "DEPARTMENT", "DEPARTMENT_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.EntityID == "DEPARTMENT_01" {
+			t.Fatal("an entity MUST NOT be its own ancestor via a cycle")
+		}
+	}
+}
+
+func TestStoreEntityHasPermission_Membership(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+	organisation := membershipTestCreate(t, store, "TEAM", "TEAM_01", "ORGANISATION", "ORGANISATION_01")
+
+	// a cycle MUST NOT break the resolution
+	membershipTestCreate(t, store, "ORGANISATION", "ORGANISATION_01", "USER", "USER_01")
+
+	checkTestGrant(t, store, "TEAM", "TEAM_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "ORGANISATION", "ORGANISATION_01", "reports.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrantWithEffect(t, store, "ORGANISATION", "ORGANISATION_01", "reports.payroll", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+	cases := []struct {
+		handle   string
+		expected bool
+	}{
+		{handle: "articles.read", expected: true},
+		{handle: "reports.sales", expected: true},
+		{handle: "reports.payroll", expected: false},
+		{handle: "articles.write", expected: false},
+	}
+
+	for _, c := range cases {
+		has, err := store.EntityHasPermission(ctx, "USER", "USER_01", c.handle)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if has != c.expected {
+			t.Fatal(c.handle, "expected", c.expected, "got", has)
+		}
+	}
+
+	has, err := store.EntityHasPermission(ctx, "USER", "USER_02", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_02 is not a member and MUST NOT have permission articles.read")
+	}
+
+	err = store.EntityMembershipSoftDelete(ctx, organisation)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	has, err = store.EntityHasPermission(ctx, "USER", "USER_01", "reports.sales")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT inherit from the organisation after the membership is soft deleted")
+	}
+}
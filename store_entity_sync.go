@@ -0,0 +1,160 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/samber/lo"
+)
+
+// EntityPermissionSyncOptions are the options of EntityPermissionSync
+type EntityPermissionSyncOptions struct {
+	// SoftDelete soft deletes the grants, which are not in the set,
+	// instead of deleting them
+	SoftDelete bool
+}
+
+// EntityPermissionSync makes the global allow grants of the entity match the
+// set of permission IDs in one transaction. Missing grants are created, grants
+// not in the set are deleted, or soft deleted as set by the options, and
+// the grants in the set are left untouched.
+//
+// Grants in the set, which are not valid yet, are left untouched as well, while
+// expired ones are replaced with grants valid now and reported as added. Deny grants and resource scoped grants are not
+// part of the set and are kept. A global deny grant of a permission in the set
+// fails the sync with ErrConflictingGrant, naming the permission ID.
+//
+// Returns the IDs of the permissions added and removed.
+func (store *store) EntityPermissionSync(ctx context.Context, entityType string, entityID string, permissionIDs []string, options EntityPermissionSyncOptions) (added []string, removed []string, err error) {
+	return entityPermissionSync(ctx, store, entityType, entityID, permissionIDs, options)
+}
+
+// entityPermissionSync implements EntityPermissionSync on top of the methods of the store
+func entityPermissionSync(ctx context.Context, store StoreInterface, entityType string, entityID string, permissionIDs []string, options EntityPermissionSyncOptions) (added []string, removed []string, err error) {
+	if entityType == "" {
+		return nil, nil, errors.New("permissionstore > EntityPermissionSync. entityType is empty")
+	}
+
+	if entityID == "" {
+		return nil, nil, errors.New("permissionstore > EntityPermissionSync. entityID is empty")
+	}
+
+	if lo.Contains(permissionIDs, "") {
+		return nil, nil, errors.New("permissionstore > EntityPermissionSync. permissionIDs contains an empty ID")
+	}
+
+	permissionIDs = lo.Uniq(permissionIDs)
+
+	added = []string{}
+	removed = []string{}
+
+	err = store.WithTransaction(ctx, func(txCtx context.Context) error {
+		if len(permissionIDs) > 0 {
+			permissions, err := store.PermissionList(txCtx, NewPermissionQuery().SetIDIn(permissionIDs))
+
+			if err != nil {
+				return err
+			}
+
+			found := lo.Map(permissions, func(permission PermissionInterface, _ int) string {
+				return permission.ID()
+			})
+
+			for _, permissionID := range permissionIDs {
+				if !lo.Contains(found, permissionID) {
+					return newError("EntityPermissionSync", ErrNotFound, "permission_id", permissionID)
+				}
+			}
+		}
+
+		grants, err := store.EntityPermissionList(txCtx, NewEntityPermissionQuery().
+			SetEntityType(entityType).
+			SetEntityID(entityID))
+
+		if err != nil {
+			return err
+		}
+
+		grants = lo.Filter(grants, func(grant EntityPermissionInterface, _ int) bool {
+			return !grant.IsResourceScoped()
+		})
+
+		denied := lo.Filter(grants, func(grant EntityPermissionInterface, _ int) bool {
+			return grant.IsDeny()
+		})
+
+		for _, grant := range denied {
+			if lo.Contains(permissionIDs, grant.PermissionID()) {
+				return newError("EntityPermissionSync", ErrConflictingGrant, "permission_id", grant.PermissionID())
+			}
+		}
+
+		grants = lo.Filter(grants, func(grant EntityPermissionInterface, _ int) bool {
+			return grant.IsAllow()
+		})
+
+		remove := func(grant EntityPermissionInterface) error {
+			if options.SoftDelete {
+				return store.EntityPermissionSoftDelete(txCtx, grant)
+			}
+
+			return store.EntityPermissionDelete(txCtx, grant)
+		}
+
+		granted := []string{}
+
+		for _, grant := range grants {
+			if !lo.Contains(permissionIDs, grant.PermissionID()) {
+				continue
+			}
+
+			// a pending grant is left untouched, so it keeps its scheduled start
+			if !grant.IsExpired() {
+				granted = append(granted, grant.PermissionID())
+				continue
+			}
+
+			// an expired grant is replaced with one, which is valid now
+			if err := remove(grant); err != nil {
+				return err
+			}
+		}
+
+		for _, permissionID := range permissionIDs {
+			if lo.Contains(granted, permissionID) {
+				continue
+			}
+
+			err := store.EntityPermissionCreate(txCtx, NewEntityPermission().
+				SetEntityType(entityType).
+				SetEntityID(entityID).
+				SetPermissionID(permissionID))
+
+			if err != nil {
+				return err
+			}
+
+			added = append(added, permissionID)
+		}
+
+		for _, grant := range grants {
+			if lo.Contains(permissionIDs, grant.PermissionID()) {
+				continue
+			}
+
+			if err := remove(grant); err != nil {
+				return err
+			}
+
+			removed = append(removed, grant.PermissionID())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return added, removed, nil
+}
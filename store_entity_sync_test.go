@@ -0,0 +1,343 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func entitySyncTestPermissions(t *testing.T, store StoreInterface, handles ...string) []string {
+	t.Helper()
+
+	ids := []string{}
+
+	for _, handle := range handles {
+		permission := NewPermission().
+			SetStatus(PERMISSION_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle)
+
+		if err := store.PermissionCreate(context.Background(), permission); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		ids = append(ids, permission.ID())
+	}
+
+	return ids
+}
+
+func TestStoreEntityPermissionSync(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ids := entitySyncTestPermissions(t, store, "articles.read", "articles.write", "comments.read")
+
+	added, removed, err := store.EntityPermissionSync(context.Background(), "USER", "USER_01", []string{ids[0], ids[1]}, EntityPermissionSyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(added, []string{ids[0], ids[1]}) || len(removed) != 0 {
+		t.Fatal("unexpected added/removed:", added, removed)
+	}
+
+	unchanged, err := store.EntityPermissionFindByEntityAndPermission(context.Background(), "USER", "USER_01", ids[1])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	added, removed, err = store.EntityPermissionSync(context.Background(), "USER", "USER_01", []string{ids[1], ids[2]}, EntityPermissionSyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(added, []string{ids[2]}) || !slices.Equal(removed, []string{ids[0]}) {
+		t.Fatal("unexpected added/removed:", added, removed)
+	}
+
+	// the grants in the set are left untouched
+	found, err := store.EntityPermissionFindByEntityAndPermission(context.Background(), "USER", "USER_01", ids[1])
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found == nil || found.ID() != unchanged.ID() || found.UpdatedAt() != unchanged.UpdatedAt() {
+		t.Fatal("grant in the set MUST be left untouched")
+	}
+
+	// the removed grant is deleted, not soft deleted
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("expected 2 grants, found:", count)
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("removed grant MUST NOT allow")
+	}
+}
+
+func TestStoreEntityPermissionSync_SoftDelete(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ids := entitySyncTestPermissions(t, store, "articles.read", "articles.write")
+
+	_, _, err = store.EntityPermissionSync(context.Background(), "USER", "USER_01", ids, EntityPermissionSyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// deny and resource scoped grants are not part of the set
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID(ids[0]).
+		SetResourceType("article").
+		SetResourceID("ARTICLE_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	added, removed, err := store.EntityPermissionSync(context.Background(), "USER", "USER_01", []string{}, EntityPermissionSyncOptions{SoftDelete: true})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(added) != 0 || !slices.Equal(removed, ids) {
+		t.Fatal("unexpected added/removed:", added, removed)
+	}
+
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Fatal("the removed grants MUST be soft deleted, found:", count)
+	}
+
+	has, err := store.EntityHasPermissionOnResource(context.Background(), "USER", "USER_01", "articles.read", "article", "ARTICLE_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("resource scoped grant MUST be kept")
+	}
+}
+
+func TestStoreEntityPermissionSync_UnknownPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ids := entitySyncTestPermissions(t, store, "articles.read")
+
+	_, _, err = store.EntityPermissionSync(context.Background(), "USER", "USER_01", []string{ids[0], "UNKNOWN_ID"}, EntityPermissionSyncOptions{})
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("a failed sync MUST NOT change the grants, found:", count)
+	}
+
+	_, _, err = store.EntityPermissionSync(context.Background(), "", "USER_01", ids, EntityPermissionSyncOptions{})
+
+	if err == nil {
+		t.Fatal("must return error as the entity type is empty")
+	}
+}
+
+func TestStoreEntityPermissionSync_OutOfWindow(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+		ids := entitySyncTestPermissions(t, store, "articles.read", "articles.write")
+
+		expired := NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(ids[0]).
+			SetExpiresAt(carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC))
+
+		pending := NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(ids[1]).
+			SetValidFrom(carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC))
+
+		for _, grant := range []EntityPermissionInterface{expired, pending} {
+			if err := store.EntityPermissionCreate(ctx, grant); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+		}
+
+		added, removed, err := store.EntityPermissionSync(ctx, "USER", "USER_01", ids, EntityPermissionSyncOptions{SoftDelete: true})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		// the expired grant is replaced, the pending grant keeps its scheduled start
+		if !slices.Equal(added, ids[:1]) || len(removed) != 0 {
+			t.Fatal("unexpected added/removed:", added, removed)
+		}
+
+		for handle, expected := range map[string]bool{"articles.read": true, "articles.write": false} {
+			has, err := store.EntityHasPermission(ctx, "USER", "USER_01", handle)
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if has != expected {
+				t.Fatal("USER_01", handle, "expected", expected, "after the sync, got", has)
+			}
+		}
+
+		found, err := store.EntityPermissionFindByID(ctx, pending.ID())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if found == nil || !found.IsPending() {
+			t.Fatal("the pending grant MUST be left untouched")
+		}
+
+		count, err := store.EntityPermissionCount(ctx, NewEntityPermissionQuery().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 3 {
+			t.Fatal("the replaced grant MUST be soft deleted, found:", count)
+		}
+
+		added, removed, err = store.EntityPermissionSync(ctx, "USER", "USER_01", ids, EntityPermissionSyncOptions{})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if len(added) != 0 || len(removed) != 0 {
+			t.Fatal("a repeated sync MUST NOT change the grants:", added, removed)
+		}
+	})
+}
+
+func TestStoreEntityPermissionSync_Deny(t *testing.T) {
+	conformanceRun(t, func(t *testing.T, store StoreInterface) {
+		ctx := context.Background()
+		ids := entitySyncTestPermissions(t, store, "articles.read", "articles.write")
+
+		err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(ids[1]).
+			SetEffect(ENTITY_PERMISSION_EFFECT_DENY))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		_, _, err = store.EntityPermissionSync(ctx, "USER", "USER_01", ids, EntityPermissionSyncOptions{})
+
+		if !errors.Is(err, ErrConflictingGrant) {
+			t.Fatal("must return ErrConflictingGrant, found:", err)
+		}
+
+		if !strings.Contains(err.Error(), ids[1]) {
+			t.Fatal("the error MUST name the denied permission, found:", err)
+		}
+
+		count, err := store.EntityPermissionCount(ctx, NewEntityPermissionQuery())
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if count != 1 {
+			t.Fatal("a failed sync MUST NOT change the grants, found:", count)
+		}
+
+		// a deny of a permission outside of the set is kept
+		added, removed, err := store.EntityPermissionSync(ctx, "USER", "USER_01", ids[:1], EntityPermissionSyncOptions{})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !slices.Equal(added, ids[:1]) || len(removed) != 0 {
+			t.Fatal("unexpected added/removed:", added, removed)
+		}
+	})
+}
@@ -2,9 +2,12 @@ package permissionstore
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/base/database"
 	"github.com/gouniverse/sb"
 )
@@ -129,6 +132,31 @@ func TestStoreEntityPermissionCreate_Duplicate(t *testing.T) {
 	if err == nil {
 		t.Fatal("must return error as duplicated entity to permission relationship")
 	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01"))
+
+	if !errors.Is(err, ErrDuplicateGrant) {
+		t.Fatal("must return ErrDuplicateGrant, found:", err)
+	}
+
+	// soft deleted entity permissions do not count as duplicates
+	err = store.EntityPermissionSoftDelete(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
 }
 
 func TestStoreEntityPermissionDelete(t *testing.T) {
@@ -570,3 +598,355 @@ func TestStoreEntityPermissionSoftDeleteByID(t *testing.T) {
 		t.Fatal("EntityPermission MUST be soft deleted")
 	}
 }
+
+func TestStoreEntityPermissionCreate_Resource(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityPermissions := []EntityPermissionInterface{
+		NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID("PERMISSION_01"),
+		NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID("PERMISSION_01").
+			SetResourceType("PROJECT").
+			SetResourceID("PROJECT_07"),
+		NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID("PERMISSION_01").
+			SetResourceType("PROJECT").
+			SetResourceID("PROJECT_08"),
+	}
+
+	for _, entityPermission := range entityPermissions {
+		err = store.EntityPermissionCreate(context.Background(), entityPermission)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01").
+		SetResourceType("PROJECT").
+		SetResourceID("PROJECT_07"))
+
+	if err == nil {
+		t.Fatal("must return error as duplicated entity to permission on resource relationship")
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01").
+		SetResourceType("PROJECT"))
+
+	if err == nil {
+		t.Fatal("must return error as resource ID is missing")
+	}
+
+	global, err := store.EntityPermissionFindByEntityAndPermission(context.Background(), "USER", "USER_01", "PERMISSION_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if global == nil || global.ID() != entityPermissions[0].ID() {
+		t.Fatal("global EntityPermission MUST be found")
+	}
+
+	if global.IsResourceScoped() {
+		t.Fatal("global EntityPermission MUST NOT be resource scoped")
+	}
+
+	scoped, err := store.EntityPermissionFindByEntityPermissionAndResource(context.Background(), "USER", "USER_01", "PERMISSION_01", "PROJECT", "PROJECT_08")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if scoped == nil || scoped.ID() != entityPermissions[2].ID() {
+		t.Fatal("scoped EntityPermission MUST be found")
+	}
+
+	if !scoped.IsResourceScoped() {
+		t.Fatal("scoped EntityPermission MUST be resource scoped")
+	}
+
+	list, err := store.EntityPermissionList(context.Background(), NewEntityPermissionQuery().
+		SetResourceType("PROJECT"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("unexpected list length:", len(list))
+	}
+}
+
+func TestStoreEntityPermissionCreate_Effect(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityPermission := NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01").
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY)
+
+	err = store.EntityPermissionCreate(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.EntityPermissionFindByID(context.Background(), entityPermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found == nil {
+		t.Fatal("entity permission MUST be found")
+	}
+
+	if !found.IsDeny() {
+		t.Fatal("entity permission MUST deny, found effect:", found.Effect())
+	}
+
+	list, err := store.EntityPermissionList(context.Background(), NewEntityPermissionQuery().
+		SetEffect(ENTITY_PERMISSION_EFFECT_ALLOW))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatal("unexpected allow entity permissions:", len(list))
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetPermissionID("PERMISSION_01").
+		SetEffect("block"))
+
+	if err == nil {
+		t.Fatal("must return error as effect is invalid")
+	}
+}
+
+func TestStoreEntityPermissionCreate_Window(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityPermission := NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID("PERMISSION_01").
+		SetValidFrom("").
+		SetExpiresAt("")
+
+	err = store.EntityPermissionCreate(context.Background(), entityPermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityPermission.ValidFrom() != sb.NULL_DATETIME {
+		t.Fatal("valid from MUST default to NULL_DATETIME, found:", entityPermission.ValidFrom())
+	}
+
+	if entityPermission.ExpiresAt() != sb.MAX_DATETIME {
+		t.Fatal("expires at MUST default to MAX_DATETIME, found:", entityPermission.ExpiresAt())
+	}
+
+	if !entityPermission.IsValid() {
+		t.Fatal("entity permission MUST be valid")
+	}
+
+	err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetPermissionID("PERMISSION_01").
+		SetValidFrom("2030-01-02 00:00:00").
+		SetExpiresAt("2030-01-01 00:00:00"))
+
+	if err == nil {
+		t.Fatal("must return error as expires at is before valid from")
+	}
+}
+
+func TestStoreEntityPermissionList_Validity(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	past := carbon.Now(carbon.UTC).SubDays(2).ToDateTimeString(carbon.UTC)
+	yesterday := carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC)
+	tomorrow := carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC)
+
+	windows := map[string][2]string{
+		"USER_ACTIVE":  {yesterday, tomorrow},
+		"USER_EXPIRED": {past, yesterday},
+		"USER_PENDING": {tomorrow, sb.MAX_DATETIME},
+	}
+
+	for entityID, window := range windows {
+		err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetPermissionID("PERMISSION_01").
+			SetValidFrom(window[0]).
+			SetExpiresAt(window[1]))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	testCases := []struct {
+		validity string
+		entityID string
+	}{
+		{ENTITY_PERMISSION_VALIDITY_ACTIVE, "USER_ACTIVE"},
+		{ENTITY_PERMISSION_VALIDITY_EXPIRED, "USER_EXPIRED"},
+		{ENTITY_PERMISSION_VALIDITY_PENDING, "USER_PENDING"},
+	}
+
+	for _, testCase := range testCases {
+		list, err := store.EntityPermissionList(context.Background(), NewEntityPermissionQuery().
+			SetValidity(testCase.validity))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if len(list) != 1 {
+			t.Fatal(testCase.validity, "expected 1 entity permission, found:", len(list))
+		}
+
+		if list[0].EntityID() != testCase.entityID {
+			t.Fatal(testCase.validity, "expected", testCase.entityID, "found:", list[0].EntityID())
+		}
+	}
+
+	_, err = store.EntityPermissionList(context.Background(), NewEntityPermissionQuery().
+		SetValidity("unknown"))
+
+	if err == nil {
+		t.Fatal("must return error as validity is unknown")
+	}
+}
+
+func TestStoreEntityPermissionPurgeExpired(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	expiresAt := map[string]string{
+		"USER_01": carbon.Now(carbon.UTC).SubDays(10).ToDateTimeString(carbon.UTC),
+		"USER_02": carbon.Now(carbon.UTC).SubHour().ToDateTimeString(carbon.UTC),
+		"USER_03": sb.MAX_DATETIME,
+	}
+
+	for entityID, expires := range expiresAt {
+		err = store.EntityPermissionCreate(context.Background(), NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetPermissionID("PERMISSION_01").
+			SetValidFrom(carbon.Now(carbon.UTC).SubDays(20).ToDateTimeString(carbon.UTC)).
+			SetExpiresAt(expires))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	purged, err := store.EntityPermissionPurgeExpired(context.Background(), 24*time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if purged != 1 {
+		t.Fatal("expected 1 purged entity permission, found:", purged)
+	}
+
+	purged, err = store.EntityPermissionPurgeExpired(context.Background(), 0)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if purged != 1 {
+		t.Fatal("expected 1 purged entity permission, found:", purged)
+	}
+
+	count, err := store.EntityPermissionCount(context.Background(), NewEntityPermissionQuery().
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("expected 1 remaining entity permission, found:", count)
+	}
+
+	_, err = store.EntityPermissionPurgeExpired(context.Background(), -time.Hour)
+
+	if err == nil {
+		t.Fatal("must return error as olderThan is negative")
+	}
+}
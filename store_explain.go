@@ -0,0 +1,330 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/samber/lo"
+)
+
+// EntityPermissionExplain explains the verdict of EntityHasPermission for the entity and the handle.
+//
+// Every grant row of the entity is listed as a step, including the soft deleted ones,
+// together with the grants inherited via memberships and the grants via roles, when enabled.
+// Each step either matched the handle or was skipped for one of the EXPLAIN_REASON_* reasons,
+// i.e. a soft deleted grant, an inactive permission or a handle mismatch. The entity
+// permission rows of other entity types with the same entity ID are listed as wrong entity.
+//
+// The verdict is evaluated over the matched steps using the precedence rule of
+// EntityHasPermission. The explanation is meant for troubleshooting, it takes
+// several queries and bypasses the cache.
+func (store *store) EntityPermissionExplain(ctx context.Context, entityType string, entityID string, handle string) (Explanation, error) {
+	sources := explainSources{
+		roles:       store.rolesEnabled(),
+		memberships: store.membershipsEnabled(),
+	}
+
+	return entityPermissionExplain(ctx, store, sources, entityType, entityID, handle)
+}
+
+// explainSources are the optional sources of grants of the store
+type explainSources struct {
+	roles       bool
+	memberships bool
+}
+
+// entityPermissionExplain implements EntityPermissionExplain on top of the methods of the store
+func entityPermissionExplain(ctx context.Context, store StoreInterface, sources explainSources, entityType string, entityID string, handle string) (Explanation, error) {
+	if entityType == "" {
+		return Explanation{}, errors.New("permissionstore > EntityPermissionExplain. entityType is empty")
+	}
+
+	if entityID == "" {
+		return Explanation{}, errors.New("permissionstore > EntityPermissionExplain. entityID is empty")
+	}
+
+	if err := PermissionHandleValidate(handle); err != nil {
+		return Explanation{}, errors.New("permissionstore > EntityPermissionExplain. " + err.Error())
+	}
+
+	entityPermissions, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery().
+		SetEntityID(entityID).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	ancestors := []EntityRef{}
+
+	if sources.memberships {
+		ancestors, err = store.EntityAncestors(ctx, entityType, entityID)
+
+		if err != nil {
+			return Explanation{}, err
+		}
+	}
+
+	inherited := map[string]bool{}
+
+	for _, ancestor := range ancestors {
+		list, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery().
+			SetEntityType(ancestor.EntityType).
+			SetEntityID(ancestor.EntityID).
+			SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			return Explanation{}, err
+		}
+
+		for _, entityPermission := range list {
+			inherited[entityPermission.ID()] = true
+		}
+
+		entityPermissions = append(entityPermissions, list...)
+	}
+
+	entityPermissions = lo.UniqBy(entityPermissions, func(entityPermission EntityPermissionInterface) string {
+		return entityPermission.ID()
+	})
+
+	entityRoles := []EntityRoleInterface{}
+	roles := map[string]RoleInterface{}
+	rolePermissions := map[string][]RolePermissionInterface{}
+
+	if sources.roles {
+		entityRoles, roles, rolePermissions, err = explainRoles(ctx, store, entityType, entityID)
+
+		if err != nil {
+			return Explanation{}, err
+		}
+	}
+
+	permissionIDs := lo.Map(entityPermissions, func(entityPermission EntityPermissionInterface, _ int) string {
+		return entityPermission.PermissionID()
+	})
+
+	for _, list := range rolePermissions {
+		permissionIDs = append(permissionIDs, lo.Map(list, func(rolePermission RolePermissionInterface, _ int) string {
+			return rolePermission.PermissionID()
+		})...)
+	}
+
+	permissions := map[string]PermissionInterface{}
+
+	if len(permissionIDs) > 0 {
+		list, err := store.PermissionList(ctx, NewPermissionQuery().
+			SetIDIn(lo.Uniq(permissionIDs)).
+			SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			return Explanation{}, err
+		}
+
+		permissions = lo.KeyBy(list, func(permission PermissionInterface) string {
+			return permission.ID()
+		})
+	}
+
+	explanation := Explanation{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Handle:     handle,
+		Steps:      []ExplainStep{},
+	}
+
+	for _, entityPermission := range entityPermissions {
+		step := ExplainStep{
+			Source:             lo.Ternary(inherited[entityPermission.ID()], EXPLAIN_SOURCE_MEMBERSHIP, EXPLAIN_SOURCE_DIRECT),
+			EntityType:         entityPermission.EntityType(),
+			EntityID:           entityPermission.EntityID(),
+			EntityPermissionID: entityPermission.ID(),
+			PermissionID:       entityPermission.PermissionID(),
+			Effect:             lo.Ternary(entityPermission.IsDeny(), ENTITY_PERMISSION_EFFECT_DENY, ENTITY_PERMISSION_EFFECT_ALLOW),
+			ResourceType:       entityPermission.ResourceType(),
+			ResourceID:         entityPermission.ResourceID(),
+		}
+
+		permission := permissions[entityPermission.PermissionID()]
+
+		if permission != nil {
+			step.PermissionHandle = permission.Handle()
+		}
+
+		permissionReason := explainPermissionReason(permission, handle, entityPermission.IsSoftDeleted())
+
+		switch {
+		case step.Source == EXPLAIN_SOURCE_DIRECT && entityPermission.EntityType() != entityType:
+			step.Reason = EXPLAIN_REASON_WRONG_ENTITY
+		case permissionReason != "":
+			step.Reason = permissionReason
+		case entityPermission.IsResourceScoped():
+			step.Reason = EXPLAIN_REASON_RESOURCE_SCOPED
+		case entityPermission.IsPending():
+			step.Reason = EXPLAIN_REASON_PENDING
+		case entityPermission.IsExpired():
+			step.Reason = EXPLAIN_REASON_EXPIRED
+		}
+
+		explanation.Steps = append(explanation.Steps, explainStepOutcome(step))
+	}
+
+	for _, entityRole := range entityRoles {
+		step := ExplainStep{
+			Source:       EXPLAIN_SOURCE_ROLE,
+			EntityType:   entityRole.EntityType(),
+			EntityID:     entityRole.EntityID(),
+			EntityRoleID: entityRole.ID(),
+			RoleID:       entityRole.RoleID(),
+		}
+
+		role := roles[entityRole.RoleID()]
+
+		if role != nil {
+			step.RoleHandle = role.Handle()
+		}
+
+		switch {
+		case entityRole.IsSoftDeleted():
+			step.Reason = EXPLAIN_REASON_SOFT_DELETED
+		case role == nil:
+			step.Reason = EXPLAIN_REASON_ROLE_NOT_FOUND
+		case role.IsSoftDeleted():
+			step.Reason = EXPLAIN_REASON_ROLE_SOFT_DELETED
+		case !role.IsActive():
+			step.Reason = EXPLAIN_REASON_ROLE_INACTIVE
+		}
+
+		if step.Reason != "" {
+			explanation.Steps = append(explanation.Steps, explainStepOutcome(step))
+			continue
+		}
+
+		for _, rolePermission := range rolePermissions[role.ID()] {
+			rolePermissionStep := step
+			rolePermissionStep.RolePermissionID = rolePermission.ID()
+			rolePermissionStep.PermissionID = rolePermission.PermissionID()
+			rolePermissionStep.Effect = ENTITY_PERMISSION_EFFECT_ALLOW
+
+			permission := permissions[rolePermission.PermissionID()]
+
+			if permission != nil {
+				rolePermissionStep.PermissionHandle = permission.Handle()
+			}
+
+			rolePermissionStep.Reason = explainPermissionReason(permission, handle, rolePermission.IsSoftDeleted())
+
+			explanation.Steps = append(explanation.Steps, explainStepOutcome(rolePermissionStep))
+		}
+	}
+
+	explainVerdict(&explanation)
+
+	return explanation, nil
+}
+
+// explainRoles returns the entity roles of the entity, including the soft deleted ones,
+// their roles keyed by ID and the role permissions of the roles keyed by role ID
+func explainRoles(ctx context.Context, store StoreInterface, entityType string, entityID string) ([]EntityRoleInterface, map[string]RoleInterface, map[string][]RolePermissionInterface, error) {
+	entityRoles, err := store.EntityRoleList(ctx, NewEntityRoleQuery().
+		SetEntityType(entityType).
+		SetEntityID(entityID).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	roles := map[string]RoleInterface{}
+	rolePermissions := map[string][]RolePermissionInterface{}
+
+	if len(entityRoles) < 1 {
+		return entityRoles, roles, rolePermissions, nil
+	}
+
+	roleIDs := lo.Uniq(lo.Map(entityRoles, func(entityRole EntityRoleInterface, _ int) string {
+		return entityRole.RoleID()
+	}))
+
+	list, err := store.RoleList(ctx, NewRoleQuery().
+		SetIDIn(roleIDs).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, role := range list {
+		roles[role.ID()] = role
+
+		rolePermissions[role.ID()], err = store.RolePermissionList(ctx, NewRolePermissionQuery().
+			SetRoleID(role.ID()).
+			SetSoftDeletedIncluded(true))
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return entityRoles, roles, rolePermissions, nil
+}
+
+// explainPermissionReason returns the reason to skip a grant of the permission
+// for the handle, empty if the grant is not skipped because of the permission
+// or because the grant itself is soft deleted
+func explainPermissionReason(permission PermissionInterface, handle string, softDeleted bool) string {
+	switch {
+	case permission == nil:
+		return EXPLAIN_REASON_PERMISSION_NOT_FOUND
+	case !PermissionHandleMatch(permission.Handle(), handle):
+		return EXPLAIN_REASON_HANDLE_MISMATCH
+	case softDeleted:
+		return EXPLAIN_REASON_SOFT_DELETED
+	case permission.IsSoftDeleted():
+		return EXPLAIN_REASON_PERMISSION_SOFT_DELETED
+	case !permission.IsActive():
+		return EXPLAIN_REASON_PERMISSION_INACTIVE
+	}
+
+	return ""
+}
+
+// explainStepOutcome sets the outcome of the step based on its skip reason
+func explainStepOutcome(step ExplainStep) ExplainStep {
+	step.Outcome = lo.Ternary(step.Reason == "", EXPLAIN_OUTCOME_MATCHED, EXPLAIN_OUTCOME_SKIPPED)
+
+	return step
+}
+
+// explainVerdict evaluates the matched steps of the explanation with grantsAllow
+// and marks the most specific step of the winning effect as the deciding one
+func explainVerdict(explanation *Explanation) {
+	matched := lo.Filter(explanation.Steps, func(step ExplainStep, _ int) bool {
+		return step.IsMatched()
+	})
+
+	explanation.Allowed = grantsAllow(lo.Map(matched, func(step ExplainStep, _ int) grant {
+		return step.grant()
+	}), explanation.Handle, "", "")
+
+	effect := lo.Ternary(explanation.Allowed, ENTITY_PERMISSION_EFFECT_ALLOW, ENTITY_PERMISSION_EFFECT_DENY)
+	deciding := -1
+
+	for i, step := range explanation.Steps {
+		if !step.IsMatched() || step.Effect != effect {
+			continue
+		}
+
+		if deciding < 0 || step.grant().specificity() > explanation.Steps[deciding].grant().specificity() {
+			deciding = i
+		}
+	}
+
+	if deciding < 0 {
+		explanation.Reason = "no matching grant"
+		return
+	}
+
+	explanation.Steps[deciding].Deciding = true
+	explanation.Reason = lo.Ternary(explanation.Allowed, "allowed by ", "denied by ") + explanation.Steps[deciding].describe()
+}
@@ -0,0 +1,271 @@
+package permissionstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// explainTestStep returns the step of the explanation for the entity permission
+func explainTestStep(t *testing.T, explanation Explanation, entityPermissionID string) ExplainStep {
+	t.Helper()
+
+	for _, step := range explanation.Steps {
+		if step.EntityPermissionID == entityPermissionID {
+			return step
+		}
+	}
+
+	t.Fatal("no step for entity permission", entityPermissionID)
+
+	return ExplainStep{}
+}
+
+func TestStoreEntityPermissionExplain(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	_, wildcard := checkTestGrant(t, store, "USER", "USER_01", "reports.*", PERMISSION_STATUS_ACTIVE)
+	_, mismatch := checkTestGrant(t, store, "USER", "USER_01", "billing.read", PERMISSION_STATUS_ACTIVE)
+	_, inactive := checkTestGrant(t, store, "USER", "USER_01", "reports.payroll.*", PERMISSION_STATUS_INACTIVE)
+	_, softDeleted := checkTestGrantWithEffect(t, store, "USER", "USER_01", "reports.payroll.view", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+	_, wrongEntity := checkTestGrant(t, store, "TEAM", "USER_01", "reports.payroll.view", PERMISSION_STATUS_ACTIVE)
+
+	err = store.EntityPermissionSoftDelete(ctx, softDeleted)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	resourceScoped := NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID(softDeleted.PermissionID()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_DENY).
+		SetResourceType("REPORT").
+		SetResourceID("REPORT_01")
+
+	err = store.EntityPermissionCreate(ctx, resourceScoped)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_INACTIVE).
+		SetHandle("auditor").
+		SetTitle("Auditor")
+
+	err = store.RoleCreate(ctx, role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(ctx, NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	explanation, err := store.EntityPermissionExplain(ctx, "USER", "USER_01", "reports.payroll.view")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !explanation.Allowed {
+		t.Fatal("reports.payroll.view MUST be allowed by reports.*")
+	}
+
+	if len(explanation.Steps) != 7 {
+		t.Fatal("expected 7 steps but got", len(explanation.Steps), explanation.Steps)
+	}
+
+	expected := map[string]string{
+		wildcard.ID():       "",
+		mismatch.ID():       EXPLAIN_REASON_HANDLE_MISMATCH,
+		inactive.ID():       EXPLAIN_REASON_PERMISSION_INACTIVE,
+		softDeleted.ID():    EXPLAIN_REASON_SOFT_DELETED,
+		wrongEntity.ID():    EXPLAIN_REASON_WRONG_ENTITY,
+		resourceScoped.ID(): EXPLAIN_REASON_RESOURCE_SCOPED,
+	}
+
+	for id, reason := range expected {
+		step := explainTestStep(t, explanation, id)
+
+		if step.Reason != reason {
+			t.Fatal("entity permission", step.PermissionHandle, "expected reason", reason, "but got", step.Reason)
+		}
+
+		if step.IsMatched() != (reason == "") {
+			t.Fatal("entity permission", step.PermissionHandle, "unexpected outcome", step.Outcome)
+		}
+	}
+
+	if !explainTestStep(t, explanation, wildcard.ID()).Deciding {
+		t.Fatal("reports.* MUST be the deciding step")
+	}
+
+	roleStep := explanation.Steps[len(explanation.Steps)-1]
+
+	if roleStep.Source != EXPLAIN_SOURCE_ROLE || roleStep.RoleHandle != "auditor" || roleStep.Reason != EXPLAIN_REASON_ROLE_INACTIVE {
+		t.Fatal("unexpected role step", roleStep)
+	}
+
+	if !strings.HasPrefix(explanation.Reason, "allowed by direct grant "+wildcard.ID()) {
+		t.Fatal("unexpected reason", explanation.Reason)
+	}
+
+	// an active deny on the handle overrides the wildcard allow
+	_, deny := checkTestGrantWithEffect(t, store, "USER", "USER_01", "reports.payroll.view", PERMISSION_STATUS_ACTIVE, ENTITY_PERMISSION_EFFECT_DENY)
+
+	explanation, err = store.EntityPermissionExplain(ctx, "USER", "USER_01", "reports.payroll.view")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	has, err := store.EntityHasPermission(ctx, "USER", "USER_01", "reports.payroll.view")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if explanation.Allowed || has {
+		t.Fatal("reports.payroll.view MUST be denied")
+	}
+
+	if !explainTestStep(t, explanation, deny.ID()).Deciding || explainTestStep(t, explanation, wildcard.ID()).Deciding {
+		t.Fatal("the deny MUST be the deciding step")
+	}
+
+	if !strings.HasPrefix(explanation.Reason, "denied by direct grant "+deny.ID()) {
+		t.Fatal("unexpected reason", explanation.Reason)
+	}
+
+	explanation, err = store.EntityPermissionExplain(ctx, "USER", "USER_02", "reports.payroll.view")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if explanation.Allowed || len(explanation.Steps) != 0 || explanation.Reason != "no matching grant" {
+		t.Fatal("unexpected explanation", explanation)
+	}
+}
+
+func TestStoreEntityPermissionExplain_Membership(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	membershipTestCreate(t, store, "USER", "USER_01", "TEAM", "TEAM_01")
+
+	_, inherited := checkTestGrant(t, store, "TEAM", "TEAM_01", "articles.read", PERMISSION_STATUS_ACTIVE)
+
+	explanation, err := store.EntityPermissionExplain(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !explanation.Allowed {
+		t.Fatal("articles.read MUST be allowed via the membership")
+	}
+
+	step := explainTestStep(t, explanation, inherited.ID())
+
+	if step.Source != EXPLAIN_SOURCE_MEMBERSHIP || step.EntityType != "TEAM" || step.EntityID != "TEAM_01" || !step.Deciding {
+		t.Fatal("unexpected step", step)
+	}
+}
+
+func TestStoreEntityPermissionExplain_Render(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, allow := checkTestGrant(t, store, "USER", "USER_01", "articles.*", PERMISSION_STATUS_ACTIVE)
+	checkTestGrant(t, store, "USER", "USER_01", "billing.read", PERMISSION_STATUS_ACTIVE)
+
+	explanation, err := store.EntityPermissionExplain(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	text := explanation.String()
+
+	expectedLines := []string{
+		`USER:USER_01 "articles.read" allowed: allowed by direct grant ` + allow.ID() + ` of USER:USER_01 on "articles.*" [allow]`,
+		`* matched direct grant ` + allow.ID() + ` of USER:USER_01 on "articles.*" [allow]`,
+		`skipped direct grant`,
+		`(handle_mismatch)`,
+	}
+
+	for _, line := range expectedLines {
+		if !strings.Contains(text, line) {
+			t.Fatal("expected text to contain", line, "but got", text)
+		}
+	}
+
+	data, err := json.Marshal(explanation)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	decoded := map[string]any{}
+
+	err = json.Unmarshal(data, &decoded)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if decoded["allowed"] != true || decoded["handle"] != "articles.read" || decoded["entity_type"] != "USER" {
+		t.Fatal("unexpected json", string(data))
+	}
+
+	steps, ok := decoded["steps"].([]any)
+
+	if !ok || len(steps) != 2 {
+		t.Fatal("unexpected json steps", string(data))
+	}
+
+	if !strings.Contains(string(data), `"reason":"handle_mismatch"`) || !strings.Contains(string(data), `"deciding":true`) {
+		t.Fatal("unexpected json", string(data))
+	}
+}
@@ -0,0 +1,179 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// MigrationStatus returns the state of all schema migrations ordered by version
+func (store *store) MigrationStatus(ctx context.Context) ([]Migration, error) {
+	if store.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	if err := store.migrationTableCreate(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := store.migrationsApplied(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	status := lo.Map(migrations(), func(m migration, _ int) Migration {
+		appliedAt, isApplied := applied[m.version]
+
+		return Migration{
+			Version:     m.version,
+			Description: m.description,
+			Enabled:     m.enabled == nil || m.enabled(store),
+			Applied:     isApplied,
+			AppliedAt:   appliedAt,
+		}
+	})
+
+	return status, nil
+}
+
+// MigrateTo applies the pending migrations up to and including the given version.
+//
+// Each migration is applied and recorded in its own transaction. The migrations
+// of optional features, which are not configured, are skipped and stay pending.
+// Migrating down is not supported.
+func (store *store) MigrateTo(ctx context.Context, version int) error {
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	all := migrations()
+
+	if version < 1 || version > all[len(all)-1].version {
+		return errors.New("permissionstore > MigrateTo. unknown version " + strconv.Itoa(version))
+	}
+
+	dialect := store.dbDriverName
+
+	if !lo.Contains(migrationsSupportedDialects, dialect) {
+		return errors.New("permissionstore > MigrateTo. migrations are not supported for dialect " + dialect)
+	}
+
+	if err := store.migrationTableCreate(ctx); err != nil {
+		return err
+	}
+
+	applied, err := store.migrationsApplied(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for appliedVersion := range applied {
+		if appliedVersion > version {
+			return errors.New("permissionstore > MigrateTo. version " + strconv.Itoa(appliedVersion) + " is already applied, migrating down is not supported")
+		}
+	}
+
+	for _, m := range all {
+		if m.version > version {
+			break
+		}
+
+		if _, isApplied := applied[m.version]; isApplied {
+			continue
+		}
+
+		if m.enabled != nil && !m.enabled(store) {
+			continue
+		}
+
+		err := store.transaction(ctx, func(txCtx database.QueryableContext) error {
+			if err := m.up(txCtx, store); err != nil {
+				return err
+			}
+
+			return store.migrationRecord(txCtx, m)
+		})
+
+		if err != nil {
+			return fmt.Errorf("permissionstore > MigrateTo. migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// migrationTableCreate creates the schema version table, if it does not exist
+func (store *store) migrationTableCreate(ctx context.Context) error {
+	sqlStr := store.sqlMigrationTableCreate()
+
+	if sqlStr == "" {
+		return errors.New("permissionstore: migration table create sql is empty")
+	}
+
+	store.logSql("migrate", sqlStr)
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr)
+
+	return err
+}
+
+// migrationsApplied returns the times of the applied migrations keyed by version
+func (store *store) migrationsApplied(ctx context.Context) (map[int]string, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.migrationTableName).
+		Prepared(true).
+		Select(COLUMN_VERSION, COLUMN_APPLIED_AT).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	rows, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int]string{}
+
+	for _, row := range rows {
+		applied[cast.ToInt(row[COLUMN_VERSION])] = row[COLUMN_APPLIED_AT]
+	}
+
+	return applied, nil
+}
+
+// migrationRecord records the migration as applied
+func (store *store) migrationRecord(ctx database.QueryableContext, m migration) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.migrationTableName).
+		Prepared(true).
+		Rows(goqu.Record{
+			COLUMN_VERSION:     m.version,
+			COLUMN_DESCRIPTION: m.description,
+			COLUMN_APPLIED_AT:  carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		}).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	_, err := database.Execute(ctx, sqlStr, params...)
+
+	return err
+}
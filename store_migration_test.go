@@ -0,0 +1,363 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreMigrationStatus(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	status, err := store.MigrationStatus(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(status) != len(migrations()) {
+		t.Fatal("expected", len(migrations()), "migrations, found:", len(status))
+	}
+
+	for index, migration := range status {
+		if migration.Version != index+1 {
+			t.Fatal("migrations MUST be ordered by version, found:", migration.Version, "at", index)
+		}
+
+		if !migration.Enabled || !migration.Applied || migration.AppliedAt == "" {
+			t.Fatalf("migration MUST be applied: %+v", migration)
+		}
+	}
+}
+
+func TestStoreMigrateTo(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		RoleTableName:             "permissions_role_table",
+		RolePermissionTableName:   "permissions_role_permission_table",
+		EntityRoleTableName:       "permissions_entity_role_table",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.MigrateTo(context.Background(), 2)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	status, err := store.MigrationStatus(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, migration := range status {
+		if migration.Applied != (migration.Version <= 2) {
+			t.Fatalf("unexpected migration state: %+v", migration)
+		}
+	}
+
+	err = store.MigrateTo(context.Background(), 1)
+
+	if err == nil {
+		t.Fatal("must return error as migrating down is not supported")
+	}
+
+	err = store.MigrateTo(context.Background(), 999)
+
+	if err == nil {
+		t.Fatal("must return error as version is unknown")
+	}
+
+	err = store.AutoMigrate()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	status, err = store.MigrationStatus(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, migration := range status {
+		if migration.Enabled != migration.Applied {
+			t.Fatalf("enabled migrations MUST be applied, disabled skipped: %+v", migration)
+		}
+	}
+
+	if status[4].Enabled {
+		t.Fatal("audit migration MUST be disabled, as audit is not configured")
+	}
+
+	// the role tables exist
+	err = store.RoleCreate(context.Background(), NewRole().SetHandle("editor").SetTitle("Editor"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreMigrate_ExistingDatabase(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	db.SetMaxOpenConns(1) // a single in-memory database for all statements
+
+	// the tables as created before the versioned migrations
+	sqlStrings := []string{
+		`CREATE TABLE IF NOT EXISTS "permissions_permission_table" ("id" TEXT(40) PRIMARY KEY NOT NULL, "status" TEXT(40) NOT NULL, "handle" TEXT(50) NOT NULL, "title" TEXT(100) NOT NULL, "metas" TEXT NOT NULL, "memo" TEXT NOT NULL, "created_at" DATETIME NOT NULL, "updated_at" DATETIME NOT NULL, "soft_deleted_at" DATETIME NOT NULL);`,
+		`CREATE TABLE IF NOT EXISTS "permissions_entity_permission_table" ("id" TEXT(40) PRIMARY KEY NOT NULL, "entity_type" TEXT(80) NOT NULL, "entity_id" TEXT(40) NOT NULL, "permission_id" TEXT(40) NOT NULL, "metas" TEXT NOT NULL, "memo" TEXT NOT NULL, "created_at" DATETIME NOT NULL, "updated_at" DATETIME NOT NULL, "soft_deleted_at" DATETIME NOT NULL);`,
+		`INSERT INTO "permissions_permission_table" VALUES ('PERMISSION_01', 'active', 'articles.read', 'Read articles', '{}', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '` + sb.MAX_DATETIME + `');`,
+		`INSERT INTO "permissions_entity_permission_table" VALUES ('ENTITY_PERMISSION_01', 'USER', 'USER_01', 'PERMISSION_01', '{}', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '` + sb.MAX_DATETIME + `');`,
+	}
+
+	for _, sqlStr := range sqlStrings {
+		if _, err := db.Exec(sqlStr); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityPermission, err := store.EntityPermissionFindByID(context.Background(), "ENTITY_PERMISSION_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityPermission == nil {
+		t.Fatal("entity permission MUST be found")
+	}
+
+	if entityPermission.Effect() != ENTITY_PERMISSION_EFFECT_ALLOW {
+		t.Fatal("effect MUST be backfilled, found:", entityPermission.Effect())
+	}
+
+	validFrom := entityPermission.ValidFromCarbon().ToDateTimeString(carbon.UTC)
+	expiresAt := entityPermission.ExpiresAtCarbon().ToDateTimeString(carbon.UTC)
+
+	if validFrom != sb.NULL_DATETIME || expiresAt != sb.MAX_DATETIME {
+		t.Fatal("validity window MUST be backfilled, found:", entityPermission.ValidFrom(), entityPermission.ExpiresAt())
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !has {
+		t.Fatal("USER_01 MUST keep the permission after the migration")
+	}
+
+	// migrating again is a no-op
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreMigrateTo_ConfiguredDialect(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		DbDriverName:              "unknown",
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the dialect MUST be the configured one, not the one of the sqlite driver
+	err = store.MigrateTo(context.Background(), 1)
+
+	if err == nil || !strings.Contains(err.Error(), "dialect unknown") {
+		t.Fatal("expected the configured dialect to be rejected but got", err)
+	}
+}
+
+func TestStoreMigrateTo_Duplicates(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	db.SetMaxOpenConns(1) // a single in-memory database for all statements
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+
+	if err := store.MigrateTo(ctx, 5); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the duplicates are possible before the unique indexes of version 6
+	permissions := []PermissionInterface{}
+
+	for range 2 {
+		permission := NewPermission().
+			SetStatus(PERMISSION_STATUS_ACTIVE).
+			SetHandle("articles.read").
+			SetTitle("Read articles")
+
+		if err := store.PermissionCreate(ctx, permission); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	grants := []EntityPermissionInterface{}
+
+	for range 2 {
+		grant := NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(permissions[0].ID())
+
+		if err := store.EntityPermissionCreate(ctx, grant); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		grants = append(grants, grant)
+	}
+
+	err = store.MigrateTo(ctx, 6)
+
+	var conflict *MigrationConflictError
+
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrDuplicateHandle) {
+		t.Fatal("expected a MigrationConflictError for the handles but got", err)
+	}
+
+	if conflict.Table != "permissions_permission_table" || len(conflict.Keys) != 1 || conflict.Keys[0][0] != "articles.read" {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+
+	if err := store.PermissionSoftDelete(ctx, permissions[1]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.MigrateTo(ctx, 6)
+
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrDuplicateGrant) {
+		t.Fatal("expected a MigrationConflictError for the grants but got", err)
+	}
+
+	expected := []string{"USER", "USER_01", permissions[0].ID(), "", ""}
+
+	if len(conflict.Keys) != 1 || !slices.Equal(conflict.Keys[0], expected) {
+		t.Fatal("expected the key", expected, "but got", conflict.Keys)
+	}
+
+	if !strings.Contains(err.Error(), permissions[0].ID()) {
+		t.Fatal("the error MUST list the conflicting key, found:", err)
+	}
+
+	status, err := store.MigrationStatus(ctx)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if status[5].Applied {
+		t.Fatal("the failed migration MUST NOT be applied")
+	}
+
+	if err := store.EntityPermissionSoftDelete(ctx, grants[1]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.MigrateTo(ctx, 6); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityPermissionCreate(ctx, NewEntityPermission().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetPermissionID(permissions[0].ID()))
+
+	if !errors.Is(err, ErrDuplicateGrant) {
+		t.Fatal("the unique index MUST be created, found:", err)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // NewStoreOptions define the options for creating a new block store
@@ -16,6 +18,40 @@ type NewStoreOptions struct {
 	// EntityPermissionTableName is the name of the entity to permission relation table
 	EntityPermissionTableName string
 
+	// RoleTableName is the name of the role table, optional.
+	// Roles are enabled when all the role table names are set
+	RoleTableName string
+
+	// RolePermissionTableName is the name of the role to permission relation table, optional
+	RolePermissionTableName string
+
+	// EntityRoleTableName is the name of the entity to role relation table, optional
+	EntityRoleTableName string
+
+	// EntityMembershipTableName is the name of the member entity to parent entity relation table, optional.
+	// When set, entities inherit the permissions granted to the entities they are members of
+	EntityMembershipTableName string
+
+	// MembershipMaxDepth is the maximum number of memberships followed from an entity
+	// to its ancestors, optional. Defaults to MEMBERSHIP_MAX_DEPTH_DEFAULT
+	MembershipMaxDepth int
+
+	// AuditTableName is the name of the audit log table, optional.
+	// When set, every mutation is recorded in the audit log
+	AuditTableName string
+
+	// MigrationTableName is the name of the schema version table, optional.
+	// Defaults to the permission table name with suffix "_migration"
+	MigrationTableName string
+
+	// CacheSize is the maximum number of entities, whose effective permissions are cached, optional.
+	// The cache is enabled when CacheSize is greater than zero
+	CacheSize int
+
+	// CacheTTL is the time an entry stays in the cache, zero for no expiry.
+	// Entries are invalidated on changes regardless of the TTL
+	CacheTTL time.Duration
+
 	// DB is the underlying database connection
 	DB *sql.DB
 
@@ -42,8 +78,34 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		return nil, errors.New("permission store: EntityPermissionTableName is required")
 	}
 
+	roleTableNames := []string{opts.RoleTableName, opts.RolePermissionTableName, opts.EntityRoleTableName}
+
+	if emptyCount := lo.Count(roleTableNames, ""); emptyCount > 0 && emptyCount < len(roleTableNames) {
+		return nil, errors.New("permission store: RoleTableName, RolePermissionTableName and EntityRoleTableName must be set together")
+	}
+
+	if opts.MigrationTableName == "" {
+		opts.MigrationTableName = opts.PermissionTableName + "_migration"
+	}
+
+	if opts.MembershipMaxDepth < 0 {
+		return nil, errors.New("permission store: MembershipMaxDepth " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	if opts.MembershipMaxDepth == 0 {
+		opts.MembershipMaxDepth = MEMBERSHIP_MAX_DEPTH_DEFAULT
+	}
+
+	if opts.CacheSize < 0 {
+		return nil, errors.New("permission store: CacheSize " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	if opts.CacheTTL < 0 {
+		return nil, errors.New("permission store: CacheTTL " + ERROR_NEGATIVE_NUMBER)
+	}
+
 	if opts.DB == nil {
-		return nil, errors.New("shop store: DB is required")
+		return nil, ErrNilDatabase
 	}
 
 	if opts.DbDriverName == "" {
@@ -57,6 +119,13 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 	store := &store{
 		permissionTableName:       opts.PermissionTableName,
 		entityPermissionTableName: opts.EntityPermissionTableName,
+		roleTableName:             opts.RoleTableName,
+		rolePermissionTableName:   opts.RolePermissionTableName,
+		entityRoleTableName:       opts.EntityRoleTableName,
+		entityMembershipTableName: opts.EntityMembershipTableName,
+		membershipMaxDepth:        opts.MembershipMaxDepth,
+		auditTableName:            opts.AuditTableName,
+		migrationTableName:        opts.MigrationTableName,
 		automigrateEnabled:        opts.AutomigrateEnabled,
 		db:                        opts.DB,
 		dbDriverName:              opts.DbDriverName,
@@ -64,6 +133,10 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		sqlLogger:                 opts.SqlLogger,
 	}
 
+	if opts.CacheSize > 0 {
+		store.cache = newGrantCache(opts.CacheSize, opts.CacheTTL)
+	}
+
 	if store.automigrateEnabled {
 		err := store.AutoMigrate()
 
@@ -19,6 +19,10 @@ func (store *store) PermissionCount(ctx context.Context, options PermissionQuery
 
 	q, _, err := store.permissionSelectQuery(options)
 
+	if err != nil {
+		return -1, err
+	}
+
 	sqlStr, params, errSql := q.Prepared(true).
 		Limit(1).
 		Select(goqu.COUNT(goqu.Star()).As("count")).
@@ -56,6 +60,10 @@ func (store *store) PermissionCreate(ctx context.Context, permission PermissionI
 		return errors.New("permission is nil")
 	}
 
+	if err := PermissionHandleValidate(permission.Handle()); err != nil {
+		return errors.New("permissionstore > PermissionCreate. " + err.Error())
+	}
+
 	permission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	permission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
@@ -74,10 +82,14 @@ func (store *store) PermissionCreate(ctx context.Context, permission PermissionI
 	store.logSql("insert", sqlStr, params...)
 
 	if store.db == nil {
-		return errors.New("permissionstore: database is nil")
+		return ErrNilDatabase
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	err := store.executeAudited(ctx, AUDIT_OPERATION_CREATE, store.permissionTableName, permission.ID(), data, sqlStr, params...)
+
+	if isUniqueViolation(err) {
+		return newError("PermissionCreate", ErrDuplicateHandle, "handle", permission.Handle())
+	}
 
 	if err != nil {
 		return err
@@ -85,6 +97,8 @@ func (store *store) PermissionCreate(ctx context.Context, permission PermissionI
 
 	permission.MarkAsNotDirty()
 
+	store.cacheFlush(ctx)
+
 	return nil
 }
 
@@ -113,9 +127,15 @@ func (store *store) PermissionDeleteByID(ctx context.Context, id string) error {
 
 	store.logSql("delete", sqlStr, params...)
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	err := store.executeAudited(ctx, AUDIT_OPERATION_DELETE, store.permissionTableName, id, nil, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
 
-	return err
+	return nil
 }
 
 func (store *store) PermissionFindByHandle(ctx context.Context, handle string) (permission PermissionInterface, err error) {
@@ -158,6 +178,47 @@ func (store *store) PermissionFindByID(ctx context.Context, id string) (permissi
 	return nil, nil
 }
 
+// PermissionGetByHandle returns a permission by its handle, ErrNotFound if it does not exist
+func (store *store) PermissionGetByHandle(ctx context.Context, handle string) (PermissionInterface, error) {
+	permission, err := store.PermissionFindByHandle(ctx, handle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if permission == nil {
+		return nil, newError("PermissionGetByHandle", ErrNotFound, "handle", handle)
+	}
+
+	return permission, nil
+}
+
+// PermissionGetByID returns a permission by its ID, ErrNotFound if it does not exist
+func (store *store) PermissionGetByID(ctx context.Context, id string) (PermissionInterface, error) {
+	permission, err := store.PermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if permission == nil {
+		return nil, newError("PermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return permission, nil
+}
+
+// PermissionHandleTree returns the handle tree of the permissions matching the given query options
+func (store *store) PermissionHandleTree(ctx context.Context, query PermissionQueryInterface) ([]*PermissionHandleNode, error) {
+	permissions, err := store.PermissionList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionHandleTreeBuild(permissions), nil
+}
+
 func (store *store) PermissionList(ctx context.Context, query PermissionQueryInterface) ([]PermissionInterface, error) {
 	if query == nil {
 		return []PermissionInterface{}, errors.New("at permission list > permission query is nil")
@@ -165,6 +226,10 @@ func (store *store) PermissionList(ctx context.Context, query PermissionQueryInt
 
 	q, columns, err := store.permissionSelectQuery(query)
 
+	if err != nil {
+		return []PermissionInterface{}, err
+	}
+
 	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
 
 	if errSql != nil {
@@ -174,7 +239,7 @@ func (store *store) PermissionList(ctx context.Context, query PermissionQueryInt
 	store.logSql("select", sqlStr, sqlParams...)
 
 	if store.db == nil {
-		return []PermissionInterface{}, errors.New("permissionstore: database is nil")
+		return []PermissionInterface{}, ErrNilDatabase
 	}
 
 	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
@@ -204,7 +269,7 @@ func (store *store) PermissionSoftDelete(ctx context.Context, permission Permiss
 }
 
 func (store *store) PermissionSoftDeleteByID(ctx context.Context, id string) error {
-	permission, err := store.PermissionFindByID(ctx, id)
+	permission, err := store.PermissionGetByID(ctx, id)
 
 	if err != nil {
 		return err
@@ -228,6 +293,12 @@ func (store *store) PermissionUpdate(ctx context.Context, permission PermissionI
 		return nil
 	}
 
+	if handle, changed := dataChanged[COLUMN_HANDLE]; changed {
+		if err := PermissionHandleValidate(handle); err != nil {
+			return errors.New("permissionstore > PermissionUpdate. " + err.Error())
+		}
+	}
+
 	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
 		Update(store.permissionTableName).
 		Prepared(true).
@@ -242,14 +313,24 @@ func (store *store) PermissionUpdate(ctx context.Context, permission PermissionI
 	store.logSql("update", sqlStr, params...)
 
 	if store.db == nil {
-		return errors.New("permissionstore: database is nil")
+		return ErrNilDatabase
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	err := store.executeAudited(ctx, auditUpdateOperation(dataChanged), store.permissionTableName, permission.ID(), dataChanged, sqlStr, params...)
 
 	permission.MarkAsNotDirty()
 
-	return err
+	if isUniqueViolation(err) {
+		return newError("PermissionUpdate", ErrDuplicateHandle, "id", permission.ID(), "handle", permission.Handle())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
 }
 
 func (store *store) permissionSelectQuery(options PermissionQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
@@ -258,7 +339,7 @@ func (store *store) permissionSelectQuery(options PermissionQueryInterface) (sel
 	}
 
 	if err := options.Validate(); err != nil {
-		return nil, nil, err
+		return nil, nil, newInvalidQueryError("PermissionQuery", err)
 	}
 
 	q := goqu.Dialect(store.dbDriverName).From(store.permissionTableName)
@@ -283,8 +364,12 @@ func (store *store) permissionSelectQuery(options PermissionQueryInterface) (sel
 		q = q.Where(goqu.C(COLUMN_HANDLE).Eq(options.Handle()))
 	}
 
+	if options.HasHandleIn() {
+		q = q.Where(goqu.C(COLUMN_HANDLE).In(options.HandleIn()))
+	}
+
 	if options.HasTitleLike() {
-		q = q.Where(goqu.C(COLUMN_TITLE).ILike(`%` + options.TitleLike() + `%`))
+		q = q.Where(store.titleLike(`%` + options.TitleLike() + `%`))
 	}
 
 	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
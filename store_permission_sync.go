@@ -0,0 +1,237 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+// PermissionDefinition is a permission as defined in code, i.e. by a service
+// declaring the permissions it checks
+type PermissionDefinition struct {
+	// Handle identifies the permission, i.e. "articles.read"
+	Handle string
+
+	// Title is the human readable name of the permission
+	Title string
+
+	// Memo is an optional note
+	Memo string
+
+	// Metas are optional key-value data
+	Metas map[string]string
+}
+
+// SyncOptions are the options of SyncPermissions
+type SyncOptions struct {
+	// Missing is what happens to the permissions, which are not in the catalog:
+	// PERMISSION_SYNC_MISSING_KEEP (default), PERMISSION_SYNC_MISSING_DEACTIVATE
+	// or PERMISSION_SYNC_MISSING_SOFT_DELETE
+	Missing string
+}
+
+// SyncReport lists the handles of the permissions changed by SyncPermissions
+type SyncReport struct {
+	// Created are the permissions created from the catalog
+	Created []string
+
+	// Updated are the permissions, which title, memo, metas or status changed
+	Updated []string
+
+	// Unchanged are the permissions, which already matched the catalog
+	Unchanged []string
+
+	// Deactivated are the permissions missing from the catalog, which were set inactive
+	Deactivated []string
+
+	// SoftDeleted are the permissions missing from the catalog, which were soft deleted
+	SoftDeleted []string
+}
+
+// SyncPermissions makes the permissions match the catalog in one transaction.
+//
+// Permissions are matched by handle. Missing permissions are created, the title,
+// memo and metas of the existing ones are updated and inactive ones are activated.
+// The permissions, which are not in the catalog, are kept, deactivated or soft
+// deleted, as set by the options.
+func (store *store) SyncPermissions(ctx context.Context, definitions []PermissionDefinition, options SyncOptions) (SyncReport, error) {
+	return syncPermissions(ctx, store, definitions, options)
+}
+
+// syncPermissions implements SyncPermissions on top of the methods of the store
+func syncPermissions(ctx context.Context, store StoreInterface, definitions []PermissionDefinition, options SyncOptions) (SyncReport, error) {
+	report := SyncReport{
+		Created:     []string{},
+		Updated:     []string{},
+		Unchanged:   []string{},
+		Deactivated: []string{},
+		SoftDeleted: []string{},
+	}
+
+	if options.Missing == "" {
+		options.Missing = PERMISSION_SYNC_MISSING_KEEP
+	}
+
+	if !lo.Contains([]string{PERMISSION_SYNC_MISSING_KEEP, PERMISSION_SYNC_MISSING_DEACTIVATE, PERMISSION_SYNC_MISSING_SOFT_DELETE}, options.Missing) {
+		return report, errors.New("permissionstore > SyncPermissions. missing must be keep, deactivate or soft_delete")
+	}
+
+	catalog := map[string]PermissionDefinition{}
+
+	for _, definition := range definitions {
+		if err := PermissionHandleValidate(definition.Handle); err != nil {
+			return report, errors.New("permissionstore > SyncPermissions. " + err.Error())
+		}
+
+		if _, exists := catalog[definition.Handle]; exists {
+			return report, errors.New("permissionstore > SyncPermissions. permission handle " + definition.Handle + " is defined more than once")
+		}
+
+		catalog[definition.Handle] = definition
+	}
+
+	err := store.WithTransaction(ctx, func(txCtx context.Context) error {
+		existing, err := store.PermissionList(txCtx, NewPermissionQuery())
+
+		if err != nil {
+			return err
+		}
+
+		existingByHandle := map[string]PermissionInterface{}
+
+		for _, permission := range existing {
+			existingByHandle[permission.Handle()] = permission
+		}
+
+		for _, definition := range definitions {
+			permission, exists := existingByHandle[definition.Handle]
+
+			if !exists {
+				permission = NewPermission().
+					SetStatus(PERMISSION_STATUS_ACTIVE).
+					SetHandle(definition.Handle).
+					SetTitle(definition.Title).
+					SetMemo(definition.Memo)
+
+				if err := permission.SetMetas(permissionSyncMetas(definition)); err != nil {
+					return err
+				}
+
+				if err := store.PermissionCreate(txCtx, permission); err != nil {
+					return err
+				}
+
+				report.Created = append(report.Created, definition.Handle)
+				continue
+			}
+
+			changed, err := permissionSyncApply(permission, definition)
+
+			if err != nil {
+				return err
+			}
+
+			if !changed {
+				report.Unchanged = append(report.Unchanged, definition.Handle)
+				continue
+			}
+
+			if err := store.PermissionUpdate(txCtx, permission); err != nil {
+				return err
+			}
+
+			report.Updated = append(report.Updated, definition.Handle)
+		}
+
+		for _, permission := range existing {
+			if _, inCatalog := catalog[permission.Handle()]; inCatalog {
+				continue
+			}
+
+			switch options.Missing {
+			case PERMISSION_SYNC_MISSING_DEACTIVATE:
+				if permission.IsInactive() {
+					continue
+				}
+
+				if err := store.PermissionUpdate(txCtx, permission.SetStatus(PERMISSION_STATUS_INACTIVE)); err != nil {
+					return err
+				}
+
+				report.Deactivated = append(report.Deactivated, permission.Handle())
+			case PERMISSION_SYNC_MISSING_SOFT_DELETE:
+				if err := store.PermissionSoftDelete(txCtx, permission); err != nil {
+					return err
+				}
+
+				report.SoftDeleted = append(report.SoftDeleted, permission.Handle())
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return SyncReport{
+			Created:     []string{},
+			Updated:     []string{},
+			Unchanged:   []string{},
+			Deactivated: []string{},
+			SoftDeleted: []string{},
+		}, err
+	}
+
+	sort.Strings(report.Deactivated)
+	sort.Strings(report.SoftDeleted)
+
+	return report, nil
+}
+
+// permissionSyncApply applies the definition to the permission,
+// returns true if the permission changed
+func permissionSyncApply(permission PermissionInterface, definition PermissionDefinition) (bool, error) {
+	changed := false
+
+	if !permission.IsActive() {
+		permission.SetStatus(PERMISSION_STATUS_ACTIVE)
+		changed = true
+	}
+
+	if permission.Title() != definition.Title {
+		permission.SetTitle(definition.Title)
+		changed = true
+	}
+
+	if permission.Memo() != definition.Memo {
+		permission.SetMemo(definition.Memo)
+		changed = true
+	}
+
+	metas, err := permission.Metas()
+
+	if err != nil {
+		return false, err
+	}
+
+	if !maps.Equal(metas, permissionSyncMetas(definition)) {
+		if err := permission.SetMetas(permissionSyncMetas(definition)); err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// permissionSyncMetas returns the metas of the definition, never nil
+func permissionSyncMetas(definition PermissionDefinition) map[string]string {
+	if definition.Metas == nil {
+		return map[string]string{}
+	}
+
+	return definition.Metas
+}
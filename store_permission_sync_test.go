@@ -0,0 +1,197 @@
+package permissionstore
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestStoreSyncPermissions(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	catalog := []PermissionDefinition{
+		{Handle: "articles.read", Title: "Read articles"},
+		{Handle: "articles.write", Title: "Write articles", Metas: map[string]string{"group": "articles"}},
+	}
+
+	report, err := store.SyncPermissions(context.Background(), catalog, SyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(report.Created, []string{"articles.read", "articles.write"}) {
+		t.Fatal("unexpected created:", report.Created)
+	}
+
+	// syncing again changes nothing
+	report, err = store.SyncPermissions(context.Background(), catalog, SyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(report.Created) != 0 || len(report.Updated) != 0 || len(report.Unchanged) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	catalog = []PermissionDefinition{
+		{Handle: "articles.write", Title: "Write and publish articles", Metas: map[string]string{"group": "articles"}},
+		{Handle: "comments.read", Title: "Read comments"},
+	}
+
+	report, err = store.SyncPermissions(context.Background(), catalog, SyncOptions{
+		Missing: PERMISSION_SYNC_MISSING_DEACTIVATE,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(report.Created, []string{"comments.read"}) {
+		t.Fatal("unexpected created:", report.Created)
+	}
+
+	if !slices.Equal(report.Updated, []string{"articles.write"}) {
+		t.Fatal("unexpected updated:", report.Updated)
+	}
+
+	if !slices.Equal(report.Deactivated, []string{"articles.read"}) {
+		t.Fatal("unexpected deactivated:", report.Deactivated)
+	}
+
+	write, err := store.PermissionGetByHandle(context.Background(), "articles.write")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if write.Title() != "Write and publish articles" {
+		t.Fatal("title MUST be updated, found:", write.Title())
+	}
+
+	read, err := store.PermissionGetByHandle(context.Background(), "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !read.IsInactive() {
+		t.Fatal("permission missing from the catalog MUST be inactive, found:", read.Status())
+	}
+
+	// an inactive permission back in the catalog is activated
+	catalog = append(catalog, PermissionDefinition{Handle: "articles.read", Title: "Read articles"})
+
+	report, err = store.SyncPermissions(context.Background(), catalog, SyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(report.Updated, []string{"articles.read"}) {
+		t.Fatal("unexpected updated:", report.Updated)
+	}
+}
+
+func TestStoreSyncPermissions_SoftDeleteMissing(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = store.SyncPermissions(context.Background(), []PermissionDefinition{
+		{Handle: "articles.read", Title: "Read articles"},
+		{Handle: "articles.write", Title: "Write articles"},
+	}, SyncOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	report, err := store.SyncPermissions(context.Background(), []PermissionDefinition{
+		{Handle: "articles.read", Title: "Read articles"},
+	}, SyncOptions{Missing: PERMISSION_SYNC_MISSING_SOFT_DELETE})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(report.SoftDeleted, []string{"articles.write"}) {
+		t.Fatal("unexpected soft deleted:", report.SoftDeleted)
+	}
+
+	count, err := store.PermissionCount(context.Background(), NewPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("expected 1 permission, found:", count)
+	}
+}
+
+func TestStoreSyncPermissions_InvalidCatalog(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = store.SyncPermissions(context.Background(), []PermissionDefinition{
+		{Handle: "articles.read", Title: "Read articles"},
+		{Handle: "articles.read", Title: "Read articles again"},
+	}, SyncOptions{})
+
+	if err == nil {
+		t.Fatal("must return error as the handle is defined twice")
+	}
+
+	_, err = store.SyncPermissions(context.Background(), []PermissionDefinition{
+		{Handle: "articles..read", Title: "Read articles"},
+	}, SyncOptions{})
+
+	if err == nil {
+		t.Fatal("must return error as the handle is invalid")
+	}
+
+	_, err = store.SyncPermissions(context.Background(), []PermissionDefinition{}, SyncOptions{Missing: "purge"})
+
+	if err == nil {
+		t.Fatal("must return error as the missing option is unknown")
+	}
+
+	count, err := store.PermissionCount(context.Background(), NewPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("invalid catalogs MUST NOT change the permissions, found:", count)
+	}
+}
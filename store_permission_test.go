@@ -2,6 +2,7 @@ package permissionstore
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -54,7 +55,7 @@ func TestStorePermissionCount(t *testing.T) {
 
 	err = store.PermissionCreate(context.Background(), NewPermission().
 		SetStatus(PERMISSION_STATUS_ACTIVE).
-		SetHandle("PERMISSION_HANDLE").
+		SetHandle("PERMISSION_HANDLE_2").
 		SetTitle("PERMISSION_TITLE"))
 
 	if err != nil {
@@ -536,3 +537,264 @@ func TestStorePermissionSoftDeleteByID(t *testing.T) {
 		t.Fatal("Permission MUST be soft deleted")
 	}
 }
+
+func TestStorePermissionCreate_InvalidHandle(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("billing..read").
+		SetTitle("PERMISSION_TITLE"))
+
+	if err == nil {
+		t.Fatal("must return error as handle is invalid")
+	}
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetTitle("PERMISSION_TITLE"))
+
+	if err == nil {
+		t.Fatal("must return error as handle is empty")
+	}
+}
+
+func TestStorePermissionUpdate_InvalidHandle(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("billing.invoice.read").
+		SetTitle("PERMISSION_TITLE")
+
+	err = store.PermissionCreate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permission.SetHandle("billing.*.read")
+
+	err = store.PermissionUpdate(context.Background(), permission)
+
+	if err == nil {
+		t.Fatal("must return error as handle is invalid")
+	}
+}
+
+func TestStorePermissionHandleTree(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, handle := range []string{"billing.invoice.read", "billing.invoice.write", "billing.*"} {
+		err = store.PermissionCreate(context.Background(), NewPermission().
+			SetStatus(PERMISSION_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tree, err := store.PermissionHandleTree(context.Background(), NewPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(tree) != 1 || tree[0].Handle != "billing" {
+		t.Fatal("unexpected tree:", tree)
+	}
+
+	if len(tree[0].Children) != 2 {
+		t.Fatal("unexpected billing children:", len(tree[0].Children))
+	}
+
+	invoice := tree[0].Children[1]
+
+	if invoice.Handle != "billing.invoice" || len(invoice.Children) != 2 {
+		t.Fatal("unexpected invoice node:", invoice.Handle)
+	}
+
+	if invoice.Children[0].Permission == nil || invoice.Children[0].Permission.Handle() != "billing.invoice.read" {
+		t.Fatal("unexpected invoice child:", invoice.Children[0].Handle)
+	}
+}
+
+func TestStorePermissionCreate_DuplicateHandle(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles")
+
+	err = store.PermissionCreate(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles"))
+
+	if !errors.Is(err, ErrDuplicateHandle) {
+		t.Fatal("must return ErrDuplicateHandle, found:", err)
+	}
+
+	other := NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.write").
+		SetTitle("Write articles")
+
+	err = store.PermissionCreate(context.Background(), other)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionUpdate(context.Background(), other.SetHandle("articles.read"))
+
+	if !errors.Is(err, ErrDuplicateHandle) {
+		t.Fatal("must return ErrDuplicateHandle, found:", err)
+	}
+
+	// the handle of a soft deleted permission can be reused
+	err = store.PermissionSoftDelete(context.Background(), permission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStorePermissionGetByHandle(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission, err := store.PermissionGetByHandle(context.Background(), "articles.read")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+
+	if permission != nil {
+		t.Fatal("permission MUST be nil")
+	}
+
+	err = store.PermissionCreate(context.Background(), NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle("articles.read").
+		SetTitle("Read articles"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permission, err = store.PermissionGetByHandle(context.Background(), "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if permission == nil || permission.Handle() != "articles.read" {
+		t.Fatal("permission MUST be found")
+	}
+
+	_, err = store.PermissionGetByID(context.Background(), "UNKNOWN_ID")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+
+	err = store.PermissionSoftDeleteByID(context.Background(), "UNKNOWN_ID")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+}
+
+func TestStorePermissionList_InvalidQuery(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = store.PermissionList(context.Background(), NewPermissionQuery().SetLimit(-1))
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("must return ErrInvalidQuery, found:", err)
+	}
+
+	_, err = store.PermissionCount(context.Background(), NewPermissionQuery().SetLimit(-1))
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatal("must return ErrInvalidQuery, found:", err)
+	}
+}
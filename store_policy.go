@@ -0,0 +1,597 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sort"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// ExportOptions are the options of Export
+type ExportOptions struct {
+	// Format is POLICY_FORMAT_JSON (default) or POLICY_FORMAT_YAML
+	Format string
+}
+
+// ImportOptions are the options of Import
+type ImportOptions struct {
+	// Format is POLICY_FORMAT_JSON (default) or POLICY_FORMAT_YAML
+	Format string
+
+	// Mode is POLICY_IMPORT_MODE_MERGE (default), which creates and updates
+	// the permissions and grants of the document, or POLICY_IMPORT_MODE_REPLACE,
+	// which also soft deletes the permissions and grants not in the document
+	Mode string
+
+	// DryRun returns the planned changes without applying them
+	DryRun bool
+}
+
+// ImportReport lists the changes of an import, planned ones for a dry run
+type ImportReport struct {
+	DryRun bool
+
+	// PermissionsCreated are the handles of the created permissions
+	PermissionsCreated []string
+
+	// PermissionsUpdated are the handles of the updated permissions
+	PermissionsUpdated []string
+
+	// PermissionsDeleted are the handles of the soft deleted permissions
+	PermissionsDeleted []string
+
+	GrantsCreated []PolicyGrant
+	GrantsUpdated []PolicyGrant
+	GrantsDeleted []PolicyGrant
+}
+
+// Export writes the permissions and the entity permission grants, which are not
+// soft deleted, as a versioned policy document. Permissions are keyed by handle
+// and the grants refer to them by handle, so the document can be imported into
+// a store with other table names and IDs
+func (store *store) Export(ctx context.Context, w io.Writer, options ExportOptions) error {
+	return policyExport(ctx, store, w, options)
+}
+
+// policyExport implements Export on top of the methods of the store
+func policyExport(ctx context.Context, store StoreInterface, w io.Writer, options ExportOptions) error {
+	if w == nil {
+		return errors.New("permissionstore > Export. writer is nil")
+	}
+
+	if options.Format == "" {
+		options.Format = POLICY_FORMAT_JSON
+	}
+
+	if !lo.Contains([]string{POLICY_FORMAT_JSON, POLICY_FORMAT_YAML}, options.Format) {
+		return errors.New("permissionstore > Export. format must be json or yaml")
+	}
+
+	permissions, err := store.PermissionList(ctx, NewPermissionQuery())
+
+	if err != nil {
+		return err
+	}
+
+	entityPermissions, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery())
+
+	if err != nil {
+		return err
+	}
+
+	document, err := policyDocumentBuild(permissions, entityPermissions)
+
+	if err != nil {
+		return err
+	}
+
+	if err := policyDocumentEncode(w, document, options.Format); err != nil {
+		return errors.New("permissionstore > Export. " + err.Error())
+	}
+
+	return nil
+}
+
+// Import reads a policy document written by Export and applies it in one
+// transaction. Permissions are matched by handle and grants by entity,
+// permission handle and resource, so the IDs of the source store are not used
+func (store *store) Import(ctx context.Context, r io.Reader, options ImportOptions) (ImportReport, error) {
+	return policyImport(ctx, store, r, options)
+}
+
+// policyImport implements Import on top of the methods of the store
+func policyImport(ctx context.Context, store StoreInterface, r io.Reader, options ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: options.DryRun}
+
+	if r == nil {
+		return report, errors.New("permissionstore > Import. reader is nil")
+	}
+
+	if options.Format == "" {
+		options.Format = POLICY_FORMAT_JSON
+	}
+
+	if options.Mode == "" {
+		options.Mode = POLICY_IMPORT_MODE_MERGE
+	}
+
+	if !lo.Contains([]string{POLICY_IMPORT_MODE_MERGE, POLICY_IMPORT_MODE_REPLACE}, options.Mode) {
+		return report, errors.New("permissionstore > Import. mode must be merge or replace")
+	}
+
+	document, err := policyDocumentDecode(r, options.Format)
+
+	if err != nil {
+		return report, errors.New("permissionstore > Import. " + err.Error())
+	}
+
+	if err := policyDocumentValidate(document); err != nil {
+		return report, errors.New("permissionstore > Import. " + err.Error())
+	}
+
+	err = store.WithTransaction(ctx, func(txCtx context.Context) error {
+		plan, err := policyImportPlanBuild(txCtx, store, document, options.Mode)
+
+		if err != nil {
+			return err
+		}
+
+		report = plan.report(options.DryRun)
+
+		if options.DryRun {
+			return nil
+		}
+
+		return policyImportApply(txCtx, store, plan)
+	})
+
+	if err != nil {
+		return ImportReport{DryRun: options.DryRun}, err
+	}
+
+	return report, nil
+}
+
+// policyImportPlan is the changes needed to apply a policy document
+type policyImportPlan struct {
+	// permissionIDs are the IDs of the existing permissions keyed by handle
+	permissionIDs map[string]string
+
+	permissionsCreate map[string]PolicyPermission
+	permissionsUpdate map[string]PermissionInterface
+	permissionsDelete []PermissionInterface
+
+	grantsCreate []PolicyGrant
+	grantsUpdate []policyImportGrantUpdate
+	grantsDelete []policyImportGrantUpdate
+}
+
+// policyImportGrantUpdate is an existing grant with its changed or deleted document form
+type policyImportGrantUpdate struct {
+	entityPermission EntityPermissionInterface
+	grant            PolicyGrant
+}
+
+// report returns the import report of the plan
+func (plan policyImportPlan) report(dryRun bool) ImportReport {
+	report := ImportReport{
+		DryRun:             dryRun,
+		PermissionsCreated: lo.Keys(plan.permissionsCreate),
+		PermissionsUpdated: lo.Keys(plan.permissionsUpdate),
+		PermissionsDeleted: lo.Map(plan.permissionsDelete, func(permission PermissionInterface, _ int) string {
+			return permission.Handle()
+		}),
+		GrantsCreated: plan.grantsCreate,
+		GrantsUpdated: lo.Map(plan.grantsUpdate, func(update policyImportGrantUpdate, _ int) PolicyGrant {
+			return update.grant
+		}),
+		GrantsDeleted: lo.Map(plan.grantsDelete, func(update policyImportGrantUpdate, _ int) PolicyGrant {
+			return update.grant
+		}),
+	}
+
+	sort.Strings(report.PermissionsCreated)
+	sort.Strings(report.PermissionsUpdated)
+	sort.Strings(report.PermissionsDeleted)
+	policyGrantsSort(report.GrantsCreated)
+	policyGrantsSort(report.GrantsUpdated)
+	policyGrantsSort(report.GrantsDeleted)
+
+	return report
+}
+
+// policyImportPlanBuild compares the document with the store and returns the changes
+func policyImportPlanBuild(ctx context.Context, store StoreInterface, document PolicyDocument, mode string) (policyImportPlan, error) {
+	plan := policyImportPlan{
+		permissionIDs:     map[string]string{},
+		permissionsCreate: map[string]PolicyPermission{},
+		permissionsUpdate: map[string]PermissionInterface{},
+		permissionsDelete: []PermissionInterface{},
+		grantsCreate:      []PolicyGrant{},
+		grantsUpdate:      []policyImportGrantUpdate{},
+		grantsDelete:      []policyImportGrantUpdate{},
+	}
+
+	permissions, err := store.PermissionList(ctx, NewPermissionQuery())
+
+	if err != nil {
+		return plan, err
+	}
+
+	handles := map[string]string{} // existing permission handles keyed by ID
+
+	for _, permission := range permissions {
+		handles[permission.ID()] = permission.Handle()
+		plan.permissionIDs[permission.Handle()] = permission.ID()
+
+		definition, inDocument := document.Permissions[permission.Handle()]
+
+		if !inDocument {
+			if mode == POLICY_IMPORT_MODE_REPLACE {
+				plan.permissionsDelete = append(plan.permissionsDelete, permission)
+			}
+
+			continue
+		}
+
+		changed, err := policyPermissionApply(permission, definition)
+
+		if err != nil {
+			return plan, err
+		}
+
+		if changed {
+			plan.permissionsUpdate[permission.Handle()] = permission
+		}
+	}
+
+	for handle, definition := range document.Permissions {
+		if _, exists := plan.permissionIDs[handle]; !exists {
+			plan.permissionsCreate[handle] = definition
+		}
+	}
+
+	for _, grant := range document.Grants {
+		_, inDocument := document.Permissions[grant.Permission]
+		_, exists := plan.permissionIDs[grant.Permission]
+
+		if !inDocument && (!exists || mode == POLICY_IMPORT_MODE_REPLACE) {
+			return plan, newError("Import", ErrNotFound, "permission", grant.Permission)
+		}
+	}
+
+	entityPermissions, err := store.EntityPermissionList(ctx, NewEntityPermissionQuery())
+
+	if err != nil {
+		return plan, err
+	}
+
+	documentGrants := map[string]PolicyGrant{}
+
+	for _, grant := range document.Grants {
+		documentGrants[grant.key()] = grant
+	}
+
+	existingGrants := map[string]bool{}
+
+	for _, entityPermission := range entityPermissions {
+		handle, exists := handles[entityPermission.PermissionID()]
+
+		if !exists {
+			continue // the permission is soft deleted
+		}
+
+		existing, err := policyGrantFromEntityPermission(entityPermission, handle)
+
+		if err != nil {
+			return plan, err
+		}
+
+		existingGrants[existing.key()] = true
+
+		grant, inDocument := documentGrants[existing.key()]
+
+		if !inDocument {
+			if mode == POLICY_IMPORT_MODE_REPLACE {
+				plan.grantsDelete = append(plan.grantsDelete, policyImportGrantUpdate{entityPermission, existing})
+			}
+
+			continue
+		}
+
+		if !policyGrantEqual(existing, grant) {
+			plan.grantsUpdate = append(plan.grantsUpdate, policyImportGrantUpdate{entityPermission, grant})
+		}
+	}
+
+	for _, grant := range document.Grants {
+		if !existingGrants[grant.key()] {
+			plan.grantsCreate = append(plan.grantsCreate, grant)
+		}
+	}
+
+	return plan, nil
+}
+
+// policyImportApply applies the changes of the plan
+func policyImportApply(ctx context.Context, store StoreInterface, plan policyImportPlan) error {
+	for _, handle := range lo.Keys(plan.permissionsCreate) {
+		definition := plan.permissionsCreate[handle]
+
+		permission := NewPermission().SetHandle(handle)
+
+		if _, err := policyPermissionApply(permission, definition); err != nil {
+			return err
+		}
+
+		if err := store.PermissionCreate(ctx, permission); err != nil {
+			return err
+		}
+
+		plan.permissionIDs[handle] = permission.ID()
+	}
+
+	for _, permission := range plan.permissionsUpdate {
+		if err := store.PermissionUpdate(ctx, permission); err != nil {
+			return err
+		}
+	}
+
+	for _, update := range plan.grantsDelete {
+		if err := store.EntityPermissionSoftDelete(ctx, update.entityPermission); err != nil {
+			return err
+		}
+	}
+
+	for _, update := range plan.grantsUpdate {
+		if err := policyGrantApply(update.entityPermission, update.grant, plan.permissionIDs); err != nil {
+			return err
+		}
+
+		if err := store.EntityPermissionUpdate(ctx, update.entityPermission); err != nil {
+			return err
+		}
+	}
+
+	for _, grant := range plan.grantsCreate {
+		entityPermission := NewEntityPermission()
+
+		if err := policyGrantApply(entityPermission, grant, plan.permissionIDs); err != nil {
+			return err
+		}
+
+		if err := store.EntityPermissionCreate(ctx, entityPermission); err != nil {
+			return err
+		}
+	}
+
+	for _, permission := range plan.permissionsDelete {
+		if err := store.PermissionSoftDelete(ctx, permission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// policyDocumentBuild returns the policy document of the permissions and the
+// entity permissions. Entity permissions of unknown permissions are left out
+func policyDocumentBuild(permissions []PermissionInterface, entityPermissions []EntityPermissionInterface) (PolicyDocument, error) {
+	document := PolicyDocument{
+		Version:     POLICY_DOCUMENT_VERSION,
+		Permissions: map[string]PolicyPermission{},
+		Grants:      []PolicyGrant{},
+	}
+
+	handles := map[string]string{}
+
+	for _, permission := range permissions {
+		metas, err := permission.Metas()
+
+		if err != nil {
+			return document, err
+		}
+
+		handles[permission.ID()] = permission.Handle()
+
+		document.Permissions[permission.Handle()] = PolicyPermission{
+			Status: permission.Status(),
+			Title:  permission.Title(),
+			Memo:   permission.Memo(),
+			Metas:  policyMetas(metas),
+		}
+	}
+
+	for _, entityPermission := range entityPermissions {
+		handle, exists := handles[entityPermission.PermissionID()]
+
+		if !exists {
+			continue
+		}
+
+		grant, err := policyGrantFromEntityPermission(entityPermission, handle)
+
+		if err != nil {
+			return document, err
+		}
+
+		document.Grants = append(document.Grants, grant)
+	}
+
+	policyGrantsSort(document.Grants)
+
+	return document, nil
+}
+
+// policyDocumentValidate checks the handles of the permissions and the grants
+// of the document, and that no grant is repeated
+func policyDocumentValidate(document PolicyDocument) error {
+	for handle, permission := range document.Permissions {
+		if err := PermissionHandleValidate(handle); err != nil {
+			return err
+		}
+
+		if !lo.Contains([]string{"", PERMISSION_STATUS_ACTIVE, PERMISSION_STATUS_INACTIVE}, permission.Status) {
+			return errors.New("permission " + handle + " status must be active or inactive")
+		}
+	}
+
+	keys := map[string]bool{}
+
+	for _, grant := range document.Grants {
+		if grant.Permission == "" {
+			return errors.New("grant permission is empty")
+		}
+
+		entityPermission := NewEntityPermission()
+
+		if err := policyGrantApply(entityPermission, grant, map[string]string{grant.Permission: grant.Permission}); err != nil {
+			return err
+		}
+
+		if err := entityPermissionPrepareCreate(entityPermission); err != nil {
+			return errors.New("grant of " + grant.Permission + " to " + grant.EntityType + " " + grant.EntityID + ": " + err.Error())
+		}
+
+		if keys[grant.key()] {
+			return errors.New("grant of " + grant.Permission + " to " + grant.EntityType + " " + grant.EntityID + " is repeated")
+		}
+
+		keys[grant.key()] = true
+	}
+
+	return nil
+}
+
+// policyPermissionApply sets the fields of the document permission on the permission,
+// returns true if the permission changed
+func policyPermissionApply(permission PermissionInterface, definition PolicyPermission) (bool, error) {
+	changed := false
+
+	status := lo.Ternary(definition.Status == "", PERMISSION_STATUS_ACTIVE, definition.Status)
+
+	if permission.Status() != status {
+		permission.SetStatus(status)
+		changed = true
+	}
+
+	if permission.Title() != definition.Title {
+		permission.SetTitle(definition.Title)
+		changed = true
+	}
+
+	if permission.Memo() != definition.Memo {
+		permission.SetMemo(definition.Memo)
+		changed = true
+	}
+
+	metas, err := permission.Metas()
+
+	if err != nil {
+		return false, err
+	}
+
+	if !maps.Equal(policyMetas(metas), policyMetas(definition.Metas)) {
+		if err := permission.SetMetas(lo.Ternary(definition.Metas == nil, map[string]string{}, definition.Metas)); err != nil {
+			return false, err
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// policyGrantFromEntityPermission returns the document form of the entity permission
+func policyGrantFromEntityPermission(entityPermission EntityPermissionInterface, handle string) (PolicyGrant, error) {
+	metas, err := entityPermission.Metas()
+
+	if err != nil {
+		return PolicyGrant{}, err
+	}
+
+	grant := PolicyGrant{
+		EntityType:   entityPermission.EntityType(),
+		EntityID:     entityPermission.EntityID(),
+		Permission:   handle,
+		Effect:       entityPermission.Effect(),
+		ResourceType: entityPermission.ResourceType(),
+		ResourceID:   entityPermission.ResourceID(),
+		ValidFrom:    entityPermission.ValidFromCarbon().ToDateTimeString(carbon.UTC),
+		ExpiresAt:    entityPermission.ExpiresAtCarbon().ToDateTimeString(carbon.UTC),
+		Memo:         entityPermission.Memo(),
+		Metas:        policyMetas(metas),
+	}
+
+	if grant.Effect == "" {
+		grant.Effect = ENTITY_PERMISSION_EFFECT_ALLOW
+	}
+
+	if grant.ValidFrom == sb.NULL_DATETIME {
+		grant.ValidFrom = ""
+	}
+
+	if grant.ExpiresAt == sb.MAX_DATETIME {
+		grant.ExpiresAt = ""
+	}
+
+	return grant, nil
+}
+
+// policyGrantApply sets the fields of the document grant on the entity permission,
+// the permission handle is mapped to the ID of the permission
+func policyGrantApply(entityPermission EntityPermissionInterface, grant PolicyGrant, permissionIDs map[string]string) error {
+	permissionID, exists := permissionIDs[grant.Permission]
+
+	if !exists {
+		return newError("Import", ErrNotFound, "permission", grant.Permission)
+	}
+
+	entityPermission.
+		SetEntityType(grant.EntityType).
+		SetEntityID(grant.EntityID).
+		SetPermissionID(permissionID).
+		SetEffect(lo.Ternary(grant.Effect == "", ENTITY_PERMISSION_EFFECT_ALLOW, grant.Effect)).
+		SetResourceType(grant.ResourceType).
+		SetResourceID(grant.ResourceID).
+		SetValidFrom(lo.Ternary(grant.ValidFrom == "", sb.NULL_DATETIME, grant.ValidFrom)).
+		SetExpiresAt(lo.Ternary(grant.ExpiresAt == "", sb.MAX_DATETIME, grant.ExpiresAt)).
+		SetMemo(grant.Memo)
+
+	return entityPermission.SetMetas(lo.Ternary(grant.Metas == nil, map[string]string{}, grant.Metas))
+}
+
+// policyGrantEqual returns true if the grants have the same effect, window, memo and metas
+func policyGrantEqual(a PolicyGrant, b PolicyGrant) bool {
+	effect := func(g PolicyGrant) string {
+		return lo.Ternary(g.Effect == "", ENTITY_PERMISSION_EFFECT_ALLOW, g.Effect)
+	}
+
+	datetime := func(value string) string {
+		if value == "" {
+			return ""
+		}
+
+		return carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+	}
+
+	return effect(a) == effect(b) &&
+		datetime(a.ValidFrom) == datetime(b.ValidFrom) &&
+		datetime(a.ExpiresAt) == datetime(b.ExpiresAt) &&
+		a.Memo == b.Memo &&
+		maps.Equal(policyMetas(a.Metas), policyMetas(b.Metas))
+}
+
+// policyMetas returns nil for empty metas, so they are omitted from the document
+func policyMetas(metas map[string]string) map[string]string {
+	if len(metas) < 1 {
+		return nil
+	}
+
+	return metas
+}
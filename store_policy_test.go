@@ -0,0 +1,263 @@
+package permissionstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func policyTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	read := NewPermission().SetStatus(PERMISSION_STATUS_ACTIVE).SetHandle("articles.read").SetTitle("Read articles")
+	write := NewPermission().SetStatus(PERMISSION_STATUS_ACTIVE).SetHandle("articles.write").SetTitle("Write articles")
+
+	for _, permission := range []PermissionInterface{read, write} {
+		if err := store.PermissionCreate(context.Background(), permission); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	grants := []EntityPermissionInterface{
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_01").SetPermissionID(read.ID()),
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_01").SetPermissionID(write.ID()).
+			SetResourceType("article").SetResourceID("ARTICLE_01").SetExpiresAt("2999-01-01 00:00:00"),
+		NewEntityPermission().SetEntityType("USER").SetEntityID("USER_02").SetPermissionID(write.ID()).
+			SetEffect(ENTITY_PERMISSION_EFFECT_DENY),
+	}
+
+	for _, grant := range grants {
+		if err := store.EntityPermissionCreate(context.Background(), grant); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	return store
+}
+
+func TestStoreExportImport(t *testing.T) {
+	for _, format := range []string{POLICY_FORMAT_JSON, POLICY_FORMAT_YAML} {
+		t.Run(format, func(t *testing.T) {
+			source := policyTestStore(t)
+
+			defer func() {
+				if err := source.DB().Close(); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			var exported bytes.Buffer
+
+			if err := source.Export(context.Background(), &exported, ExportOptions{Format: format}); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if !strings.Contains(exported.String(), "articles.read") {
+				t.Fatal("permissions MUST be keyed by handle:", exported.String())
+			}
+
+			// the target store has other table names and IDs
+			db, err := initDB(":memory:")
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			defer func() {
+				if err := db.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			target, err := NewStore(NewStoreOptions{
+				DB:                        db,
+				PermissionTableName:       "target_permission",
+				EntityPermissionTableName: "target_entity_permission",
+				AutomigrateEnabled:        true,
+			})
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			report, err := target.Import(context.Background(), bytes.NewReader(exported.Bytes()), ImportOptions{Format: format})
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if !slices.Equal(report.PermissionsCreated, []string{"articles.read", "articles.write"}) || len(report.GrantsCreated) != 3 {
+				t.Fatalf("unexpected report: %+v", report)
+			}
+
+			checks := []struct {
+				entityID string
+				handle   string
+				resource string
+				expected bool
+			}{
+				{"USER_01", "articles.read", "", true},
+				{"USER_01", "articles.write", "", false},
+				{"USER_01", "articles.write", "ARTICLE_01", true},
+				{"USER_02", "articles.write", "ARTICLE_01", false},
+			}
+
+			for _, check := range checks {
+				var has bool
+
+				if check.resource == "" {
+					has, err = target.EntityHasPermission(context.Background(), "USER", check.entityID, check.handle)
+				} else {
+					has, err = target.EntityHasPermissionOnResource(context.Background(), "USER", check.entityID, check.handle, "article", check.resource)
+				}
+
+				if err != nil {
+					t.Fatal("unexpected error:", err)
+				}
+
+				if has != check.expected {
+					t.Fatal(check.entityID, check.handle, check.resource, "expected", check.expected, "got", has)
+				}
+			}
+
+			// the export of the target matches the export of the source
+			var reexported bytes.Buffer
+
+			if err := target.Export(context.Background(), &reexported, ExportOptions{Format: format}); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if reexported.String() != exported.String() {
+				t.Fatal("export MUST be stable, expected:\n", exported.String(), "found:\n", reexported.String())
+			}
+
+			// importing again changes nothing
+			report, err = target.Import(context.Background(), bytes.NewReader(exported.Bytes()), ImportOptions{Format: format})
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if len(report.PermissionsCreated)+len(report.PermissionsUpdated)+len(report.GrantsCreated)+len(report.GrantsUpdated) != 0 {
+				t.Fatalf("unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestStoreImport_ReplaceAndDryRun(t *testing.T) {
+	store := policyTestStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	document := `{
+		"version": 1,
+		"permissions": {
+			"articles.read": {"status": "active", "title": "Read all articles"},
+			"comments.read": {"status": "active", "title": "Read comments"}
+		},
+		"grants": [
+			{"entity_type": "USER", "entity_id": "USER_01", "permission": "articles.read", "effect": "allow"},
+			{"entity_type": "USER", "entity_id": "USER_03", "permission": "comments.read", "effect": "allow"}
+		]
+	}`
+
+	report, err := store.Import(context.Background(), strings.NewReader(document), ImportOptions{
+		Mode:   POLICY_IMPORT_MODE_REPLACE,
+		DryRun: true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !report.DryRun ||
+		!slices.Equal(report.PermissionsCreated, []string{"comments.read"}) ||
+		!slices.Equal(report.PermissionsUpdated, []string{"articles.read"}) ||
+		!slices.Equal(report.PermissionsDeleted, []string{"articles.write"}) ||
+		len(report.GrantsCreated) != 1 ||
+		len(report.GrantsDeleted) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	count, err := store.PermissionCount(context.Background(), NewPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("dry run MUST NOT change the store, found permissions:", count)
+	}
+
+	_, err = store.Import(context.Background(), strings.NewReader(document), ImportOptions{Mode: POLICY_IMPORT_MODE_REPLACE})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var exported bytes.Buffer
+
+	if err := store.Export(context.Background(), &exported, ExportOptions{}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if strings.Contains(exported.String(), "articles.write") || !strings.Contains(exported.String(), "Read all articles") || !strings.Contains(exported.String(), "USER_03") {
+		t.Fatal("the store MUST match the document, found:", exported.String())
+	}
+}
+
+func TestStoreImport_Invalid(t *testing.T) {
+	store := policyTestStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err := store.Import(context.Background(), strings.NewReader(`{"version": 2}`), ImportOptions{})
+
+	if err == nil {
+		t.Fatal("must return error as the version is not supported")
+	}
+
+	_, err = store.Import(context.Background(), strings.NewReader(`{
+		"version": 1,
+		"permissions": {},
+		"grants": [{"entity_type": "USER", "entity_id": "USER_01", "permission": "unknown.permission"}]
+	}`), ImportOptions{})
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("must return ErrNotFound, found:", err)
+	}
+
+	_, err = store.Import(context.Background(), strings.NewReader(`{
+		"version": 1,
+		"permissions": {},
+		"grants": [{"entity_type": "USER", "entity_id": "USER_01", "permission": "articles.read", "effect": "maybe"}]
+	}`), ImportOptions{})
+
+	if err == nil {
+		t.Fatal("must return error as the effect is invalid")
+	}
+
+	_, err = store.Import(context.Background(), strings.NewReader(`{"version": 1}`), ImportOptions{Mode: "overwrite"})
+
+	if err == nil {
+		t.Fatal("must return error as the mode is invalid")
+	}
+}
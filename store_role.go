@@ -0,0 +1,402 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+func (store *store) RoleCount(ctx context.Context, options RoleQueryInterface) (int64, error) {
+	options.SetCountOnly(true)
+
+	q, _, err := store.roleSelectQuery(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		Limit(1).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, nil
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return -1, nil
+	}
+
+	countStr := mapped[0]["count"]
+
+	i, err := strconv.ParseInt(countStr, 10, 64)
+
+	if err != nil {
+		return -1, err
+
+	}
+
+	return i, nil
+}
+
+func (store *store) RoleCreate(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("role is nil")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	role.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	role.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	data := role.Data()
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.roleTableName).
+		Prepared(true).
+		Rows(data).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err := store.executeAudited(ctx, AUDIT_OPERATION_CREATE, store.roleTableName, role.ID(), data, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	role.MarkAsNotDirty()
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) RoleDelete(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("role is nil")
+	}
+
+	return store.RoleDeleteByID(ctx, role.ID())
+}
+
+func (store *store) RoleDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("role id is empty")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.roleTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	err := store.executeAudited(ctx, AUDIT_OPERATION_DELETE, store.roleTableName, id, nil, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) RoleFindByHandle(ctx context.Context, handle string) (role RoleInterface, err error) {
+	if handle == "" {
+		return nil, errors.New("role handle is empty")
+	}
+
+	query := NewRoleQuery().SetHandle(handle).SetLimit(1)
+
+	list, err := store.RoleList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *store) RoleFindByID(ctx context.Context, id string) (role RoleInterface, err error) {
+	if id == "" {
+		return nil, errors.New("role id is empty")
+	}
+
+	query := NewRoleQuery().SetID(id).SetLimit(1)
+
+	list, err := store.RoleList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// RoleGetByHandle returns a role by its handle, ErrNotFound if it does not exist
+func (store *store) RoleGetByHandle(ctx context.Context, handle string) (RoleInterface, error) {
+	role, err := store.RoleFindByHandle(ctx, handle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, newError("RoleGetByHandle", ErrNotFound, "handle", handle)
+	}
+
+	return role, nil
+}
+
+// RoleGetByID returns a role by its ID, ErrNotFound if it does not exist
+func (store *store) RoleGetByID(ctx context.Context, id string) (RoleInterface, error) {
+	role, err := store.RoleFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, newError("RoleGetByID", ErrNotFound, "id", id)
+	}
+
+	return role, nil
+}
+
+func (store *store) RoleList(ctx context.Context, query RoleQueryInterface) ([]RoleInterface, error) {
+	if query == nil {
+		return []RoleInterface{}, errors.New("at role list > role query is nil")
+	}
+
+	q, columns, err := store.roleSelectQuery(query)
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+	if errSql != nil {
+		return []RoleInterface{}, nil
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return []RoleInterface{}, ErrNilDatabase
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	list := []RoleInterface{}
+
+	lo.ForEach(modelMaps, func(modelMap map[string]string, index int) {
+		model := NewRoleFromExistingData(modelMap)
+		list = append(list, model)
+	})
+
+	return list, nil
+}
+
+func (store *store) RoleSoftDelete(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("at role soft delete > role is nil")
+	}
+
+	role.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.RoleUpdate(ctx, role)
+}
+
+func (store *store) RoleSoftDeleteByID(ctx context.Context, id string) error {
+	role, err := store.RoleGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.RoleSoftDelete(ctx, role)
+}
+
+func (store *store) RoleUpdate(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("at role update > role is nil")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	role.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := role.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.roleTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(role.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err := store.executeAudited(ctx, auditUpdateOperation(dataChanged), store.roleTableName, role.ID(), dataChanged, sqlStr, params...)
+
+	role.MarkAsNotDirty()
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
+	if options == nil {
+		return nil, nil, errors.New("role options is nil")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, nil, newInvalidQueryError("RoleQuery", err)
+	}
+
+	if !store.rolesEnabled() {
+		return nil, nil, errors.New("permissionstore: roles are not enabled")
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.roleTableName)
+
+	if options.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(options.ID()))
+	}
+
+	if options.HasIDIn() {
+		q = q.Where(goqu.C(COLUMN_ID).In(options.IDIn()))
+	}
+
+	if options.HasStatus() {
+		q = q.Where(goqu.C(COLUMN_STATUS).Eq(options.Status()))
+	}
+
+	if options.HasStatusIn() {
+		q = q.Where(goqu.C(COLUMN_STATUS).In(options.StatusIn()))
+	}
+
+	if options.HasHandle() {
+		q = q.Where(goqu.C(COLUMN_HANDLE).Eq(options.Handle()))
+	}
+
+	if options.HasTitleLike() {
+		q = q.Where(store.titleLike(`%` + options.TitleLike() + `%`))
+	}
+
+	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
+		q = q.Where(
+			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
+			goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
+		)
+	} else if options.HasCreatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
+	} else if options.HasCreatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+	}
+
+	if !options.IsCountOnly() {
+		if options.HasLimit() {
+			q = q.Limit(cast.ToUint(options.Limit()))
+		}
+
+		if options.HasOffset() {
+			q = q.Offset(cast.ToUint(options.Offset()))
+		}
+	}
+
+	if options.HasOrderBy() {
+		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
+		if strings.EqualFold(sort, sb.ASC) {
+			q = q.Order(goqu.I(options.OrderBy()).Asc())
+		} else {
+			q = q.Order(goqu.I(options.OrderBy()).Desc())
+		}
+	}
+
+	columns = []any{}
+
+	for _, column := range options.Columns() {
+		columns = append(columns, column)
+	}
+
+	if options.SoftDeletedIncluded() {
+		return q, columns, nil // soft deleted roles requested specifically
+	}
+
+	softDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).
+		Gt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	return q.Where(softDeleted), columns, nil
+}
@@ -0,0 +1,412 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+func (store *store) RolePermissionCount(ctx context.Context, options RolePermissionQueryInterface) (int64, error) {
+	options.SetCountOnly(true)
+
+	q, _, err := store.rolePermissionSelectQuery(options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).
+		Limit(1).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, nil
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	mapped, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(mapped) < 1 {
+		return -1, nil
+	}
+
+	countStr := mapped[0]["count"]
+
+	i, err := strconv.ParseInt(countStr, 10, 64)
+
+	if err != nil {
+		return -1, err
+
+	}
+
+	return i, nil
+}
+
+func (store *store) RolePermissionCreate(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission is nil")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	if rolePermission.PermissionID() == "" {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission permissionID is empty")
+	}
+
+	if rolePermission.RoleID() == "" {
+		return errors.New("permissionstore > RolePermissionCreate. rolePermission roleID is empty")
+	}
+
+	rolePermissionExists, err := store.RolePermissionFindByRoleAndPermission(
+		ctx,
+		rolePermission.RoleID(),
+		rolePermission.PermissionID(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if rolePermissionExists != nil {
+		return newError("RolePermissionCreate", ErrDuplicateGrant, "role_id", rolePermission.RoleID(), "permission_id", rolePermission.PermissionID())
+	}
+
+	rolePermission.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	rolePermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	data := rolePermission.Data()
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.rolePermissionTableName).
+		Prepared(true).
+		Rows(data).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err = store.executeAudited(ctx, AUDIT_OPERATION_CREATE, store.rolePermissionTableName, rolePermission.ID(), data, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	rolePermission.MarkAsNotDirty()
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) RolePermissionDelete(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("rolePermission is nil")
+	}
+
+	return store.RolePermissionDeleteByID(ctx, rolePermission.ID())
+}
+
+func (store *store) RolePermissionDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("rolePermission id is empty")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.rolePermissionTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	err := store.executeAudited(ctx, AUDIT_OPERATION_DELETE, store.rolePermissionTableName, id, nil, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) RolePermissionFindByRoleAndPermission(
+	ctx context.Context,
+	roleID string,
+	permissionID string,
+) (rolePermission RolePermissionInterface, err error) {
+	if roleID == "" {
+		return nil, errors.New("RolePermissionFindByRoleAndPermission roleID is empty")
+	}
+
+	if permissionID == "" {
+		return nil, errors.New("RolePermissionFindByRoleAndPermission permissionID is empty")
+	}
+
+	query := NewRolePermissionQuery().
+		SetRoleID(roleID).
+		SetPermissionID(permissionID).
+		SetLimit(1)
+
+	list, err := store.RolePermissionList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (store *store) RolePermissionFindByID(ctx context.Context, id string) (rolePermission RolePermissionInterface, err error) {
+	if id == "" {
+		return nil, errors.New("rolePermission id is empty")
+	}
+
+	query := NewRolePermissionQuery().SetID(id).SetLimit(1)
+
+	list, err := store.RolePermissionList(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// RolePermissionGetByID returns a role permission mapping by its ID, ErrNotFound if it does not exist
+func (store *store) RolePermissionGetByID(ctx context.Context, id string) (RolePermissionInterface, error) {
+	rolePermission, err := store.RolePermissionFindByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rolePermission == nil {
+		return nil, newError("RolePermissionGetByID", ErrNotFound, "id", id)
+	}
+
+	return rolePermission, nil
+}
+
+func (store *store) RolePermissionList(ctx context.Context, query RolePermissionQueryInterface) ([]RolePermissionInterface, error) {
+	if query == nil {
+		return []RolePermissionInterface{}, errors.New("at rolePermission list > rolePermission query is nil")
+	}
+
+	q, columns, err := store.rolePermissionSelectQuery(query)
+
+	if err != nil {
+		return []RolePermissionInterface{}, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+	if errSql != nil {
+		return []RolePermissionInterface{}, nil
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return []RolePermissionInterface{}, ErrNilDatabase
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []RolePermissionInterface{}, err
+	}
+
+	list := []RolePermissionInterface{}
+
+	lo.ForEach(modelMaps, func(modelMap map[string]string, index int) {
+		model := NewRolePermissionFromExistingData(modelMap)
+		list = append(list, model)
+	})
+
+	return list, nil
+}
+
+func (store *store) RolePermissionSoftDelete(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("at rolePermission soft delete > rolePermission is nil")
+	}
+
+	rolePermission.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return store.RolePermissionUpdate(ctx, rolePermission)
+}
+
+func (store *store) RolePermissionSoftDeleteByID(ctx context.Context, id string) error {
+	rolePermission, err := store.RolePermissionGetByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return store.RolePermissionSoftDelete(ctx, rolePermission)
+}
+
+func (store *store) RolePermissionUpdate(ctx context.Context, rolePermission RolePermissionInterface) error {
+	if rolePermission == nil {
+		return errors.New("at rolePermission update > rolePermission is nil")
+	}
+
+	if !store.rolesEnabled() {
+		return errors.New("permissionstore: roles are not enabled")
+	}
+
+	rolePermission.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	dataChanged := rolePermission.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	if len(dataChanged) < 1 {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.rolePermissionTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(rolePermission.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	err := store.executeAudited(ctx, auditUpdateOperation(dataChanged), store.rolePermissionTableName, rolePermission.ID(), dataChanged, sqlStr, params...)
+
+	rolePermission.MarkAsNotDirty()
+
+	if err != nil {
+		return err
+	}
+
+	store.cacheFlush(ctx)
+
+	return nil
+}
+
+func (store *store) rolePermissionSelectQuery(options RolePermissionQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
+	if options == nil {
+		return nil, nil, errors.New("rolePermission options is nil")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, nil, newInvalidQueryError("RolePermissionQuery", err)
+	}
+
+	if !store.rolesEnabled() {
+		return nil, nil, errors.New("permissionstore: roles are not enabled")
+	}
+
+	q := goqu.Dialect(store.dbDriverName).From(store.rolePermissionTableName)
+
+	if options.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(options.ID()))
+	}
+
+	if options.HasIDIn() {
+		q = q.Where(goqu.C(COLUMN_ID).In(options.IDIn()))
+	}
+
+	if options.HasPermissionID() {
+		q = q.Where(goqu.C(COLUMN_PERMISSION_ID).Eq(options.PermissionID()))
+	}
+
+	if options.HasRoleID() {
+		q = q.Where(goqu.C(COLUMN_ROLE_ID).Eq(options.RoleID()))
+	}
+
+	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
+		q = q.Where(
+			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
+			goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
+		)
+	} else if options.HasCreatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
+	} else if options.HasCreatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+	}
+
+	if !options.IsCountOnly() {
+		if options.HasLimit() {
+			q = q.Limit(cast.ToUint(options.Limit()))
+		}
+
+		if options.HasOffset() {
+			q = q.Offset(cast.ToUint(options.Offset()))
+		}
+	}
+
+	if options.HasOrderBy() {
+		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
+		if strings.EqualFold(sort, sb.ASC) {
+			q = q.Order(goqu.I(options.OrderBy()).Asc())
+		} else {
+			q = q.Order(goqu.I(options.OrderBy()).Desc())
+		}
+	}
+
+	columns = []any{}
+
+	for _, column := range options.Columns() {
+		columns = append(columns, column)
+	}
+
+	if options.SoftDeletedIncluded() {
+		return q, columns, nil // soft deleted rolePermissions requested specifically
+	}
+
+	softDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).
+		Gt(carbon.Now(carbon.UTC).ToDateTimeString())
+
+	return q.Where(softDeleted), columns, nil
+}
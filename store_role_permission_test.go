@@ -0,0 +1,552 @@
+package permissionstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreRolePermissionCount(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	count, err := store.RolePermissionCount(context.Background(), NewRolePermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err = store.RolePermissionCount(context.Background(), NewRolePermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	rolePermission2 := NewRolePermission().
+		SetRoleID("ROLE_02").
+		SetPermissionID("PERMISSION_02")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission2)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	count, err = store.RolePermissionCount(context.Background(), NewRolePermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected count:", count)
+	}
+}
+
+func TestStoreRolePermissionCreate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreRolePermissionCreate_Duplicate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err == nil {
+		t.Fatal("must return error as duplicated entity to permission relationship")
+	}
+}
+
+func TestStoreRolePermissionDelete(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionDelete(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rolePermissionFound, err := store.RolePermissionFindByID(context.Background(), rolePermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if rolePermissionFound != nil {
+		t.Fatal("RolePermission MUST be nil")
+	}
+
+	rolePermissionFindWithDeleted, err := store.RolePermissionList(context.Background(), NewRolePermissionQuery().
+		SetID(rolePermission.ID()).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(rolePermissionFindWithDeleted) != 0 {
+		t.Fatal("RolePermission MUST be nil")
+	}
+}
+
+func TestStoreRolePermissionDeleteByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionDeleteByID(context.Background(), rolePermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rolePermissionFound, err := store.RolePermissionFindByID(context.Background(), rolePermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if rolePermissionFound != nil {
+		t.Fatal("RolePermission MUST be nil")
+	}
+
+	rolePermissionFindWithDeleted, err := store.RolePermissionList(context.Background(), NewRolePermissionQuery().
+		SetID(rolePermission.ID()).
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(rolePermissionFindWithDeleted) != 0 {
+		t.Fatal("RolePermission MUST NOT be found")
+	}
+}
+
+func TestStoreRolePermissionFindByRoleAndPermission(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = rolePermission.SetMetas(map[string]string{
+		"education_1": "Education 1",
+		"education_2": "Education 2",
+		"education_3": "Education 3",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionCreate(database.Context(context.Background(), store.DB()), rolePermission)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	rolePermissionFound, errFind := store.RolePermissionFindByRoleAndPermission(database.Context(context.Background(), store.DB()), rolePermission.RoleID(), rolePermission.PermissionID())
+
+	if errFind != nil {
+		t.Fatal("unexpected error:", errFind)
+	}
+
+	if rolePermissionFound == nil {
+		t.Fatal("RolePermission MUST NOT be nil")
+	}
+
+	if rolePermissionFound.ID() != rolePermission.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	if rolePermissionFound.RoleID() != rolePermission.RoleID() {
+		t.Fatal("RoleIDs do not match")
+	}
+
+	if rolePermissionFound.PermissionID() != rolePermission.PermissionID() {
+		t.Fatal("PermissionIDs do not match")
+	}
+
+	if rolePermissionFound.Meta("education_1") != rolePermission.Meta("education_1") {
+		t.Fatal("Metas do not match")
+	}
+
+	if rolePermissionFound.Meta("education_2") != rolePermission.Meta("education_2") {
+		t.Fatal("Metas do not match")
+	}
+
+	if rolePermissionFound.Meta("education_3") != rolePermission.Meta("education_3") {
+		t.Fatal("Metas do not match")
+	}
+}
+
+func TestStoreRolePermissionFindByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = rolePermission.SetMetas(map[string]string{
+		"education_1": "Education 1",
+		"education_2": "Education 2",
+		"education_3": "Education 3",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := database.Context(context.Background(), store.DB())
+	err = store.RolePermissionCreate(ctx, rolePermission)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	rolePermissionFound, errFind := store.RolePermissionFindByID(ctx, rolePermission.ID())
+
+	if errFind != nil {
+		t.Fatal("unexpected error:", errFind)
+	}
+
+	if rolePermissionFound == nil {
+		t.Fatal("RolePermission MUST NOT be nil")
+	}
+
+	if rolePermissionFound.ID() != rolePermission.ID() {
+		t.Fatal("IDs do not match")
+	}
+
+	if rolePermissionFound.RoleID() != rolePermission.RoleID() {
+		t.Fatal("RoleIDs do not match")
+	}
+
+	if rolePermissionFound.PermissionID() != rolePermission.PermissionID() {
+		t.Fatal("PermissionIDs do not match")
+	}
+
+	if rolePermissionFound.Meta("education_1") != rolePermission.Meta("education_1") {
+		t.Fatal("Metas do not match")
+	}
+
+	if rolePermissionFound.Meta("education_2") != rolePermission.Meta("education_2") {
+		t.Fatal("Metas do not match")
+	}
+
+	if rolePermissionFound.Meta("education_3") != rolePermission.Meta("education_3") {
+		t.Fatal("Metas do not match")
+	}
+}
+
+func TestStoreRolePermissionList(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission1 := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	rolePermission2 := NewRolePermission().
+		SetRoleID("ROLE_02").
+		SetPermissionID("PERMISSION_02")
+
+	rolePermissions := []RolePermissionInterface{
+		rolePermission1,
+		rolePermission2,
+	}
+
+	for _, rolePermission := range rolePermissions {
+		err = store.RolePermissionCreate(context.Background(), rolePermission)
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	list1, err := store.RolePermissionList(context.Background(), NewRolePermissionQuery().SetPermissionID("PERMISSION_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list1) != 1 {
+		t.Fatal("unexpected list length:", len(list1))
+	}
+
+	list2, err := store.RolePermissionList(context.Background(), NewRolePermissionQuery().SetRoleID("ROLE_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list2) != 1 {
+		t.Fatal("unexpected list length:", len(list2))
+	}
+}
+
+func TestStoreRolePermissionSoftDelete(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionSoftDelete(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if rolePermission.SoftDeletedAt() == sb.MAX_DATETIME {
+		t.Fatal("RolePermission MUST be soft deleted")
+	}
+
+	rolePermissionFound, errFind := store.RolePermissionFindByID(context.Background(), rolePermission.ID())
+
+	if errFind != nil {
+		t.Fatal("unexpected error:", errFind)
+	}
+
+	if rolePermissionFound != nil {
+		t.Fatal("RolePermission MUST be soft deleted, so MUST be nil")
+	}
+
+	rolePermissionFindWithDeleted, err := store.RolePermissionList(context.Background(), NewRolePermissionQuery().
+		SetSoftDeletedIncluded(true).
+		SetID(rolePermission.ID()).
+		SetLimit(1))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(rolePermissionFindWithDeleted) == 0 {
+		t.Fatal("RolePermission MUST be soft deleted")
+	}
+
+	if strings.Contains(rolePermissionFindWithDeleted[0].SoftDeletedAt(), sb.MAX_DATETIME) {
+		t.Fatal("RolePermission MUST be soft deleted", rolePermission.SoftDeletedAt())
+	}
+
+	if !rolePermissionFindWithDeleted[0].IsSoftDeleted() {
+		t.Fatal("RolePermission MUST be soft deleted")
+	}
+}
+
+func TestStoreRolePermissionSoftDeleteByID(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	rolePermission := NewRolePermission().
+		SetRoleID("ROLE_01").
+		SetPermissionID("PERMISSION_01")
+
+	err = store.RolePermissionCreate(context.Background(), rolePermission)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RolePermissionSoftDeleteByID(context.Background(), rolePermission.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if rolePermission.SoftDeletedAt() != sb.MAX_DATETIME {
+		t.Fatal("RolePermission MUST NOT be soft deleted, as it was soft deleted by ID")
+	}
+
+	rolePermissionFound, errFind := store.RolePermissionFindByID(context.Background(), rolePermission.ID())
+
+	if errFind != nil {
+		t.Fatal("unexpected error:", errFind)
+	}
+
+	if rolePermissionFound != nil {
+		t.Fatal("RolePermission MUST be nil")
+	}
+	query := NewRolePermissionQuery().
+		SetSoftDeletedIncluded(true).
+		SetID(rolePermission.ID()).
+		SetLimit(1)
+
+	rolePermissionFindWithDeleted, err := store.RolePermissionList(context.Background(), query)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(rolePermissionFindWithDeleted) == 0 {
+		t.Fatal("RolePermission MUST be soft deleted")
+	}
+
+	if strings.Contains(rolePermissionFindWithDeleted[0].SoftDeletedAt(), sb.MAX_DATETIME) {
+		t.Fatal("RolePermission MUST be soft deleted", rolePermission.SoftDeletedAt())
+	}
+
+	if !rolePermissionFindWithDeleted[0].IsSoftDeleted() {
+		t.Fatal("RolePermission MUST be soft deleted")
+	}
+}
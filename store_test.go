@@ -43,6 +43,11 @@ func initStore(filepath string) (StoreInterface, error) {
 		DB:                        db,
 		PermissionTableName:       "permissions_permission_table",
 		EntityPermissionTableName: "permissions_entity_permission_table",
+		RoleTableName:             "permissions_role_table",
+		RolePermissionTableName:   "permissions_role_permission_table",
+		EntityRoleTableName:       "permissions_entity_role_table",
+		EntityMembershipTableName: "permissions_entity_membership_table",
+		AuditTableName:            "permissions_audit_table",
 		AutomigrateEnabled:        true,
 		DebugEnabled:              true,
 		SqlLogger:                 slog.New(slog.NewTextHandler(os.Stdout, nil)),
@@ -144,3 +149,55 @@ func TestStoreWithTx(t *testing.T) {
 		t.Fatal("Permission MUST be PERMISSION_TITLE_2, as transaction committed")
 	}
 }
+
+func TestStoreRolesDisabled(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		RoleTableName:             "permissions_role_table",
+	})
+
+	if err == nil {
+		t.Fatal("must return error as not all role table names are set")
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                        db,
+		PermissionTableName:       "permissions_permission_table",
+		EntityPermissionTableName: "permissions_entity_permission_table",
+		AutomigrateEnabled:        true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RoleCreate(context.Background(), NewRole().SetHandle("editor"))
+
+	if err == nil {
+		t.Fatal("must return error as roles are not enabled")
+	}
+
+	has, err := store.EntityHasPermission(context.Background(), "USER", "USER_01", "articles.read")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if has {
+		t.Fatal("USER_01 MUST NOT have permission articles.read")
+	}
+}
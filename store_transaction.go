@@ -0,0 +1,191 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// transactionSavepointDialects are the dialects supporting savepoints
+var transactionSavepointDialects = []string{
+	sb.DIALECT_MYSQL,
+	sb.DIALECT_POSTGRES,
+	sb.DIALECT_SQLITE,
+}
+
+// transactionContextKey is the context key of the transaction scope
+type transactionContextKey struct{}
+
+// transactionScope is the state of a transaction started by WithTransaction,
+// carried by the context passed to the transaction function
+type transactionScope struct {
+	// depth is the nesting level, zero for the outermost transaction
+	depth int
+
+	// pending are the cache invalidations to repeat after the commit
+	pending *transactionPending
+}
+
+// transactionPending collects the cache invalidations made in a transaction
+type transactionPending struct {
+	mu       sync.Mutex
+	flushAll bool
+	entities [][2]string
+}
+
+// invalidate records the invalidation of the effective permissions of the entity
+func (p *transactionPending) invalidate(entityType string, entityID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entities = append(p.entities, [2]string{entityType, entityID})
+}
+
+// flush records the invalidation of the effective permissions of all entities
+func (p *transactionPending) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.flushAll = true
+}
+
+// transactionScopeFromContext returns the transaction scope carried by the context, nil if none
+func transactionScopeFromContext(ctx context.Context) *transactionScope {
+	if ctx == nil {
+		return nil
+	}
+
+	scope, _ := ctx.Value(transactionContextKey{}).(*transactionScope)
+
+	return scope
+}
+
+// WithTransaction runs fn in a database transaction. All store methods called
+// with the context passed to fn run in the transaction. The transaction is
+// committed when fn succeeds and rolled back when fn returns an error or panics.
+//
+// When the context already carries a transaction, i.e. for nested calls, fn runs
+// in a savepoint, so only its own changes are rolled back on failure. For dialects
+// without savepoints fn joins the outer transaction.
+func (store *store) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if fn == nil {
+		return errors.New("permissionstore > WithTransaction. fn is nil")
+	}
+
+	if store.inTransaction(ctx) {
+		return store.withSavepoint(ctx.(database.QueryableContext), fn)
+	}
+
+	if store.db == nil {
+		return ErrNilDatabase
+	}
+
+	tx, err := store.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	scope := &transactionScope{depth: 0, pending: &transactionPending{}}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(database.Context(context.WithValue(ctx, transactionContextKey{}, scope), tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	store.cacheCommit(scope.pending)
+
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the transaction carried by the context
+func (store *store) withSavepoint(ctx database.QueryableContext, fn func(ctx context.Context) error) (err error) {
+	if !lo.Contains(transactionSavepointDialects, store.dbDriverName) {
+		return fn(ctx)
+	}
+
+	scope := &transactionScope{depth: 1, pending: &transactionPending{}}
+
+	if outer := transactionScopeFromContext(ctx); outer != nil {
+		scope = &transactionScope{depth: outer.depth + 1, pending: outer.pending}
+	}
+
+	savepoint := "permissionstore_savepoint_" + strconv.Itoa(scope.depth)
+
+	if err := store.savepointExec(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = store.savepointExec(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(r)
+		}
+	}()
+
+	savepointCtx := database.Context(context.WithValue(ctx.Context, transactionContextKey{}, scope), ctx.Queryable())
+
+	if err := fn(savepointCtx); err != nil {
+		_ = store.savepointExec(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+		return err
+	}
+
+	return store.savepointExec(ctx, "RELEASE SAVEPOINT "+savepoint)
+}
+
+// savepointExec executes a savepoint statement
+func (store *store) savepointExec(ctx database.QueryableContext, sqlStr string) error {
+	store.logSql("savepoint", sqlStr)
+
+	_, err := database.Execute(ctx, sqlStr)
+
+	return err
+}
+
+// cacheCommit repeats the cache invalidations of a committed transaction
+func (store *store) cacheCommit(pending *transactionPending) {
+	if store.cache == nil {
+		return
+	}
+
+	pending.mu.Lock()
+	defer pending.mu.Unlock()
+
+	if pending.flushAll {
+		store.cache.flush()
+		return
+	}
+
+	for _, entity := range pending.entities {
+		store.cache.invalidate(entity[0], entity[1])
+	}
+}
+
+// transaction runs fn in a database transaction. When the context already
+// carries a transaction fn joins it, otherwise a new transaction is started
+// with WithTransaction
+func (store *store) transaction(ctx context.Context, fn func(txCtx database.QueryableContext) error) error {
+	if store.inTransaction(ctx) {
+		return fn(ctx.(database.QueryableContext))
+	}
+
+	return store.WithTransaction(ctx, func(txCtx context.Context) error {
+		return fn(txCtx.(database.QueryableContext))
+	})
+}
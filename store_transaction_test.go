@@ -0,0 +1,228 @@
+package permissionstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gouniverse/sb"
+)
+
+func transactionTestPermissionCount(t *testing.T, store StoreInterface) int64 {
+	count, err := store.PermissionCount(context.Background(), NewPermissionQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return count
+}
+
+func transactionTestPermission(handle string) PermissionInterface {
+	return NewPermission().
+		SetStatus(PERMISSION_STATUS_ACTIVE).
+		SetHandle(handle).
+		SetTitle(handle)
+}
+
+func TestStoreWithTransaction_Commit(t *testing.T) {
+	store, err := initStore(filepath.Join(t.TempDir(), "test_store_with_transaction_commit.db"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := store.PermissionCreate(ctx, transactionTestPermission("articles.read")); err != nil {
+			return err
+		}
+
+		return store.PermissionCreate(ctx, transactionTestPermission("articles.write"))
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count := transactionTestPermissionCount(t, store); count != 2 {
+		t.Fatal("expected 2 permissions, found:", count)
+	}
+}
+
+func TestStoreWithTransaction_Rollback(t *testing.T) {
+	store, err := initStore(filepath.Join(t.TempDir(), "test_store_with_transaction_rollback.db"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	errExpected := errors.New("expected error")
+
+	err = store.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := store.PermissionCreate(ctx, transactionTestPermission("articles.read")); err != nil {
+			return err
+		}
+
+		return errExpected
+	})
+
+	if !errors.Is(err, errExpected) {
+		t.Fatal("must return the error of fn, found:", err)
+	}
+
+	if count := transactionTestPermissionCount(t, store); count != 0 {
+		t.Fatal("the transaction MUST be rolled back, found permissions:", count)
+	}
+
+	// the audit records are rolled back with the changes
+	auditCount, err := store.AuditCount(context.Background(), NewAuditQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if auditCount != 0 {
+		t.Fatal("the audit records MUST be rolled back, found:", auditCount)
+	}
+}
+
+func TestStoreWithTransaction_Panic(t *testing.T) {
+	store, err := initStore(filepath.Join(t.TempDir(), "test_store_with_transaction_panic.db"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("the panic MUST be propagated")
+			}
+		}()
+
+		_ = store.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if err := store.PermissionCreate(ctx, transactionTestPermission("articles.read")); err != nil {
+				return err
+			}
+
+			panic("expected panic")
+		})
+	}()
+
+	if count := transactionTestPermissionCount(t, store); count != 0 {
+		t.Fatal("the transaction MUST be rolled back, found permissions:", count)
+	}
+}
+
+func TestStoreWithTransaction_Nested(t *testing.T) {
+	store, err := initStore(filepath.Join(t.TempDir(), "test_store_with_transaction_nested.db"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := store.PermissionCreate(ctx, transactionTestPermission("articles.read")); err != nil {
+			return err
+		}
+
+		// the failed nested transaction only rolls back its own changes
+		errNested := store.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := store.PermissionCreate(ctx, transactionTestPermission("articles.write")); err != nil {
+				return err
+			}
+
+			return errors.New("nested error")
+		})
+
+		if errNested == nil {
+			t.Fatal("must return the error of the nested fn")
+		}
+
+		return store.WithTransaction(ctx, func(ctx context.Context) error {
+			return store.PermissionCreate(ctx, transactionTestPermission("articles.delete"))
+		})
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	permissions, err := store.PermissionList(context.Background(), NewPermissionQuery().SetOrderBy(COLUMN_HANDLE).SetSortDirection(sb.ASC))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(permissions) != 2 {
+		t.Fatal("expected 2 permissions, found:", len(permissions))
+	}
+
+	if permissions[0].Handle() != "articles.delete" || permissions[1].Handle() != "articles.read" {
+		t.Fatal("unexpected permissions:", permissions[0].Handle(), permissions[1].Handle())
+	}
+}
+
+func TestStoreWithTransaction_CacheInvalidatedAfterCommit(t *testing.T) {
+	store := initStoreWithCache(t, filepath.Join(t.TempDir(), "test_store_with_transaction_cache.db"))
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	permission := transactionTestPermission("articles.read")
+
+	if err := store.PermissionCreate(context.Background(), permission); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.WithTransaction(context.Background(), func(ctx context.Context) error {
+		err := store.EntityPermissionCreate(ctx, NewEntityPermission().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetPermissionID(permission.ID()))
+
+		if err != nil {
+			return err
+		}
+
+		// a concurrent reader caches the state before the commit
+		cacheTestHas(t, store, "USER_01", "articles.read", false)
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the state cached before the commit is invalidated
+	cacheTestHas(t, store, "USER_01", "articles.read", true)
+}
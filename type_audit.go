@@ -0,0 +1,149 @@
+package permissionstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/maputils"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+)
+
+// == CLASS ===================================================================
+
+// auditRecord is a single entry of the audit log. The audit log is append only,
+// the records are created by the store on every mutation and never updated
+type auditRecord struct {
+	dataobject.DataObject
+}
+
+var _ AuditRecordInterface = (*auditRecord)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewAuditRecord() AuditRecordInterface {
+	o := (&auditRecord{}).
+		SetID(uid.HumanUid()).
+		SetActor("").
+		SetDataBefore("").
+		SetDataAfter("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewAuditRecordFromExistingData(data map[string]string) AuditRecordInterface {
+	o := &auditRecord{}
+	o.Hydrate(data)
+	return o
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+// Actor returns the actor who made the change, empty if unknown
+func (o *auditRecord) Actor() string {
+	return o.Get(COLUMN_ACTOR)
+}
+
+func (o *auditRecord) SetActor(actor string) AuditRecordInterface {
+	o.Set(COLUMN_ACTOR, actor)
+	return o
+}
+
+func (o *auditRecord) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *auditRecord) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *auditRecord) SetCreatedAt(createdAt string) AuditRecordInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// DataAfter returns the row data after the change as json string,
+// empty for deletes
+func (o *auditRecord) DataAfter() string {
+	return o.Get(COLUMN_DATA_AFTER)
+}
+
+// DataAfterMap returns the row data after the change
+func (o *auditRecord) DataAfterMap() (map[string]string, error) {
+	return auditDataFromJSON(o.DataAfter())
+}
+
+func (o *auditRecord) SetDataAfter(dataAfter string) AuditRecordInterface {
+	o.Set(COLUMN_DATA_AFTER, dataAfter)
+	return o
+}
+
+// DataBefore returns the row data before the change as json string,
+// empty for creates
+func (o *auditRecord) DataBefore() string {
+	return o.Get(COLUMN_DATA_BEFORE)
+}
+
+// DataBeforeMap returns the row data before the change
+func (o *auditRecord) DataBeforeMap() (map[string]string, error) {
+	return auditDataFromJSON(o.DataBefore())
+}
+
+func (o *auditRecord) SetDataBefore(dataBefore string) AuditRecordInterface {
+	o.Set(COLUMN_DATA_BEFORE, dataBefore)
+	return o
+}
+
+func (o *auditRecord) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *auditRecord) SetID(id string) AuditRecordInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+// Operation returns the operation, one of the AUDIT_OPERATION_* constants
+func (o *auditRecord) Operation() string {
+	return o.Get(COLUMN_OPERATION)
+}
+
+func (o *auditRecord) SetOperation(operation string) AuditRecordInterface {
+	o.Set(COLUMN_OPERATION, operation)
+	return o
+}
+
+// RowID returns the ID of the changed row
+func (o *auditRecord) RowID() string {
+	return o.Get(COLUMN_ROW_ID)
+}
+
+func (o *auditRecord) SetRowID(rowID string) AuditRecordInterface {
+	o.Set(COLUMN_ROW_ID, rowID)
+	return o
+}
+
+// TableName returns the name of the table of the changed row
+func (o *auditRecord) TableName() string {
+	return o.Get(COLUMN_TABLE_NAME)
+}
+
+func (o *auditRecord) SetTableName(tableName string) AuditRecordInterface {
+	o.Set(COLUMN_TABLE_NAME, tableName)
+	return o
+}
+
+// auditDataFromJSON decodes the json encoded row data, empty string decodes to empty map
+func auditDataFromJSON(dataStr string) (map[string]string, error) {
+	if dataStr == "" {
+		return map[string]string{}, nil
+	}
+
+	dataJson, errJson := utils.FromJSON(dataStr, map[string]string{})
+
+	if errJson != nil {
+		return map[string]string{}, errJson
+	}
+
+	return maputils.MapStringAnyToMapStringString(dataJson.(map[string]any)), nil
+}
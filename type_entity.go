@@ -22,7 +22,12 @@ var _ EntityPermissionInterface = (*entityPermission)(nil)
 func NewEntityPermission() EntityPermissionInterface {
 	o := (&entityPermission{}).
 		SetID(uid.HumanUid()).
+		SetEffect(ENTITY_PERMISSION_EFFECT_ALLOW).
 		SetMemo("").
+		SetResourceType("").
+		SetResourceID("").
+		SetValidFrom(sb.NULL_DATETIME).
+		SetExpiresAt(sb.MAX_DATETIME).
 		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetSoftDeletedAt(sb.MAX_DATETIME)
@@ -44,6 +49,39 @@ func NewEntityPermissionFromExistingData(data map[string]string) EntityPermissio
 
 // == METHODS =================================================================
 
+// IsAllow returns true if the mapping allows the permission.
+// Mappings without effect allow the permission
+func (o *entityPermission) IsAllow() bool {
+	return !o.IsDeny()
+}
+
+// IsDeny returns true if the mapping explicitly denies the permission
+func (o *entityPermission) IsDeny() bool {
+	return o.Effect() == ENTITY_PERMISSION_EFFECT_DENY
+}
+
+// IsExpired returns true if the validity window of the mapping has ended
+func (o *entityPermission) IsExpired() bool {
+	return o.ExpiresAtCarbon().Compare("<=", carbon.Now(carbon.UTC))
+}
+
+// IsPending returns true if the validity window of the mapping has not started yet
+func (o *entityPermission) IsPending() bool {
+	return o.ValidFromCarbon().Compare(">", carbon.Now(carbon.UTC))
+}
+
+// IsValid returns true if the mapping is within its validity window,
+// i.e. it is neither pending nor expired
+func (o *entityPermission) IsValid() bool {
+	return !o.IsPending() && !o.IsExpired()
+}
+
+// IsResourceScoped returns true if the grant applies to a specific resource only,
+// false if it is a global grant applying to every resource
+func (o *entityPermission) IsResourceScoped() bool {
+	return o.ResourceType() != "" || o.ResourceID() != ""
+}
+
 func (o *entityPermission) IsSoftDeleted() bool {
 	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
 }
@@ -63,6 +101,15 @@ func (o *entityPermission) SetCreatedAt(createdAt string) EntityPermissionInterf
 	return o
 }
 
+func (o *entityPermission) Effect() string {
+	return o.Get(COLUMN_EFFECT)
+}
+
+func (o *entityPermission) SetEffect(effect string) EntityPermissionInterface {
+	o.Set(COLUMN_EFFECT, effect)
+	return o
+}
+
 func (o *entityPermission) EntityType() string {
 	return o.Get(COLUMN_ENTITY_TYPE)
 }
@@ -81,6 +128,21 @@ func (o *entityPermission) SetEntityID(entityID string) EntityPermissionInterfac
 	return o
 }
 
+// ExpiresAt returns the time the mapping stops being effective,
+// sb.MAX_DATETIME for mappings which never expire
+func (o *entityPermission) ExpiresAt() string {
+	return o.Get(COLUMN_EXPIRES_AT)
+}
+
+func (o *entityPermission) ExpiresAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.ExpiresAt(), carbon.UTC)
+}
+
+func (o *entityPermission) SetExpiresAt(expiresAt string) EntityPermissionInterface {
+	o.Set(COLUMN_EXPIRES_AT, expiresAt)
+	return o
+}
+
 func (o *entityPermission) ID() string {
 	return o.Get(COLUMN_ID)
 }
@@ -179,6 +241,24 @@ func (o *entityPermission) SetPermissionID(roleID string) EntityPermissionInterf
 	return o
 }
 
+func (o *entityPermission) ResourceID() string {
+	return o.Get(COLUMN_RESOURCE_ID)
+}
+
+func (o *entityPermission) SetResourceID(resourceID string) EntityPermissionInterface {
+	o.Set(COLUMN_RESOURCE_ID, resourceID)
+	return o
+}
+
+func (o *entityPermission) ResourceType() string {
+	return o.Get(COLUMN_RESOURCE_TYPE)
+}
+
+func (o *entityPermission) SetResourceType(resourceType string) EntityPermissionInterface {
+	o.Set(COLUMN_RESOURCE_TYPE, resourceType)
+	return o
+}
+
 func (o *entityPermission) UpdatedAt() string {
 	return o.Get(COLUMN_UPDATED_AT)
 }
@@ -191,3 +271,18 @@ func (o *entityPermission) SetUpdatedAt(updatedAt string) EntityPermissionInterf
 	o.Set(COLUMN_UPDATED_AT, updatedAt)
 	return o
 }
+
+// ValidFrom returns the time the mapping starts being effective,
+// sb.NULL_DATETIME for mappings effective since their creation
+func (o *entityPermission) ValidFrom() string {
+	return o.Get(COLUMN_VALID_FROM)
+}
+
+func (o *entityPermission) ValidFromCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.ValidFrom(), carbon.UTC)
+}
+
+func (o *entityPermission) SetValidFrom(validFrom string) EntityPermissionInterface {
+	o.Set(COLUMN_VALID_FROM, validFrom)
+	return o
+}
@@ -0,0 +1,202 @@
+package permissionstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/maputils"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+)
+
+// == CLASS ===================================================================
+
+type entityMembership struct {
+	dataobject.DataObject
+}
+
+var _ EntityMembershipInterface = (*entityMembership)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewEntityMembership() EntityMembershipInterface {
+	o := (&entityMembership{}).
+		SetID(uid.HumanUid()).
+		SetMemo("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	err := o.SetMetas(map[string]string{})
+
+	if err != nil {
+		return o
+	}
+
+	return o
+}
+
+func NewEntityMembershipFromExistingData(data map[string]string) EntityMembershipInterface {
+	o := &entityMembership{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *entityMembership) IsSoftDeleted() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *entityMembership) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *entityMembership) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *entityMembership) SetCreatedAt(createdAt string) EntityMembershipInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *entityMembership) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *entityMembership) SetID(id string) EntityMembershipInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *entityMembership) MemberType() string {
+	return o.Get(COLUMN_MEMBER_TYPE)
+}
+
+func (o *entityMembership) SetMemberType(memberType string) EntityMembershipInterface {
+	o.Set(COLUMN_MEMBER_TYPE, memberType)
+	return o
+}
+
+func (o *entityMembership) MemberID() string {
+	return o.Get(COLUMN_MEMBER_ID)
+}
+
+func (o *entityMembership) SetMemberID(memberID string) EntityMembershipInterface {
+	o.Set(COLUMN_MEMBER_ID, memberID)
+	return o
+}
+
+func (o *entityMembership) Memo() string {
+	return o.Get(COLUMN_MEMO)
+}
+
+func (o *entityMembership) SetMemo(memo string) EntityMembershipInterface {
+	o.Set(COLUMN_MEMO, memo)
+	return o
+}
+
+func (o *entityMembership) Metas() (map[string]string, error) {
+	metasStr := o.Get(COLUMN_METAS)
+
+	if metasStr == "" {
+		metasStr = "{}"
+	}
+
+	metasJson, errJson := utils.FromJSON(metasStr, map[string]string{})
+	if errJson != nil {
+		return map[string]string{}, errJson
+	}
+
+	return maputils.MapStringAnyToMapStringString(metasJson.(map[string]any)), nil
+}
+
+func (o *entityMembership) Meta(name string) string {
+	metas, err := o.Metas()
+
+	if err != nil {
+		return ""
+	}
+
+	if value, exists := metas[name]; exists {
+		return value
+	}
+
+	return ""
+}
+
+func (o *entityMembership) SetMeta(name, value string) error {
+	return o.UpsertMetas(map[string]string{name: value})
+}
+
+// SetMetas stores metas as json string
+// Warning: it overwrites any existing metas
+func (o *entityMembership) SetMetas(metas map[string]string) error {
+	mapString, err := utils.ToJSON(metas)
+	if err != nil {
+		return err
+	}
+	o.Set(COLUMN_METAS, mapString)
+	return nil
+}
+
+func (o *entityMembership) UpsertMetas(metas map[string]string) error {
+	currentMetas, err := o.Metas()
+
+	if err != nil {
+		return err
+	}
+
+	for k, v := range metas {
+		currentMetas[k] = v
+	}
+
+	return o.SetMetas(currentMetas)
+}
+
+func (o *entityMembership) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *entityMembership) SoftDeletedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *entityMembership) SetSoftDeletedAt(deletedAt string) EntityMembershipInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, deletedAt)
+	return o
+}
+
+func (o *entityMembership) ParentType() string {
+	return o.Get(COLUMN_PARENT_TYPE)
+}
+
+func (o *entityMembership) SetParentType(parentType string) EntityMembershipInterface {
+	o.Set(COLUMN_PARENT_TYPE, parentType)
+	return o
+}
+
+func (o *entityMembership) ParentID() string {
+	return o.Get(COLUMN_PARENT_ID)
+}
+
+func (o *entityMembership) SetParentID(parentID string) EntityMembershipInterface {
+	o.Set(COLUMN_PARENT_ID, parentID)
+	return o
+}
+
+func (o *entityMembership) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *entityMembership) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.Get(COLUMN_UPDATED_AT), carbon.UTC)
+}
+
+func (o *entityMembership) SetUpdatedAt(updatedAt string) EntityMembershipInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
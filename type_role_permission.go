@@ -0,0 +1,184 @@
+package permissionstore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/maputils"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+)
+
+// == CLASS ===================================================================
+
+type rolePermission struct {
+	dataobject.DataObject
+}
+
+var _ RolePermissionInterface = (*rolePermission)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewRolePermission() RolePermissionInterface {
+	o := (&rolePermission{}).
+		SetID(uid.HumanUid()).
+		SetMemo("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	err := o.SetMetas(map[string]string{})
+
+	if err != nil {
+		return o
+	}
+
+	return o
+}
+
+func NewRolePermissionFromExistingData(data map[string]string) RolePermissionInterface {
+	o := &rolePermission{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *rolePermission) IsSoftDeleted() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *rolePermission) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *rolePermission) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *rolePermission) SetCreatedAt(createdAt string) RolePermissionInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *rolePermission) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *rolePermission) SetID(id string) RolePermissionInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *rolePermission) Memo() string {
+	return o.Get(COLUMN_MEMO)
+}
+
+func (o *rolePermission) SetMemo(memo string) RolePermissionInterface {
+	o.Set(COLUMN_MEMO, memo)
+	return o
+}
+
+func (o *rolePermission) Metas() (map[string]string, error) {
+	metasStr := o.Get(COLUMN_METAS)
+
+	if metasStr == "" {
+		metasStr = "{}"
+	}
+
+	metasJson, errJson := utils.FromJSON(metasStr, map[string]string{})
+	if errJson != nil {
+		return map[string]string{}, errJson
+	}
+
+	return maputils.MapStringAnyToMapStringString(metasJson.(map[string]any)), nil
+}
+
+func (o *rolePermission) Meta(name string) string {
+	metas, err := o.Metas()
+
+	if err != nil {
+		return ""
+	}
+
+	if value, exists := metas[name]; exists {
+		return value
+	}
+
+	return ""
+}
+
+func (o *rolePermission) SetMeta(name, value string) error {
+	return o.UpsertMetas(map[string]string{name: value})
+}
+
+// SetMetas stores metas as json string
+// Warning: it overwrites any existing metas
+func (o *rolePermission) SetMetas(metas map[string]string) error {
+	mapString, err := utils.ToJSON(metas)
+	if err != nil {
+		return err
+	}
+	o.Set(COLUMN_METAS, mapString)
+	return nil
+}
+
+func (o *rolePermission) UpsertMetas(metas map[string]string) error {
+	currentMetas, err := o.Metas()
+
+	if err != nil {
+		return err
+	}
+
+	for k, v := range metas {
+		currentMetas[k] = v
+	}
+
+	return o.SetMetas(currentMetas)
+}
+
+func (o *rolePermission) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *rolePermission) SoftDeletedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *rolePermission) SetSoftDeletedAt(deletedAt string) RolePermissionInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, deletedAt)
+	return o
+}
+
+func (o *rolePermission) PermissionID() string {
+	return o.Get(COLUMN_PERMISSION_ID)
+}
+
+func (o *rolePermission) SetPermissionID(permissionID string) RolePermissionInterface {
+	o.Set(COLUMN_PERMISSION_ID, permissionID)
+	return o
+}
+
+func (o *rolePermission) RoleID() string {
+	return o.Get(COLUMN_ROLE_ID)
+}
+
+func (o *rolePermission) SetRoleID(roleID string) RolePermissionInterface {
+	o.Set(COLUMN_ROLE_ID, roleID)
+	return o
+}
+
+func (o *rolePermission) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *rolePermission) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.Get(COLUMN_UPDATED_AT), carbon.UTC)
+}
+
+func (o *rolePermission) SetUpdatedAt(updatedAt string) RolePermissionInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}